@@ -0,0 +1,221 @@
+package main
+
+import (
+	authpb "grpc/pb/auth"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type impersonateRequest struct {
+	Reason     string `json:"reason" binding:"required"`
+	TTLMinutes int32  `json:"ttl_minutes"`
+}
+
+type setUserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// handler untuk admin routes, the auth service checks the admin role
+func (g *Gateway) ListUsers(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+
+	resp, err := g.authClient.ListUsers(requestContext(c), &authpb.ListUsersRequest{
+		Token:   token,
+		Page:    int32(page),
+		PerPage: int32(perPage),
+		Search:  c.Query("search"),
+		Role:    c.Query("role"),
+		Status:  c.Query("status"),
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) GetUser(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	resp, err := g.authClient.GetUser(requestContext(c), &authpb.GetUserRequest{
+		Token: token,
+		Id:    id,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) DisableUser(c *gin.Context) {
+	g.setUserDisabled(c, true)
+}
+
+func (g *Gateway) EnableUser(c *gin.Context) {
+	g.setUserDisabled(c, false)
+}
+
+func (g *Gateway) setUserDisabled(c *gin.Context, disabled bool) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	resp, err := g.authClient.SetUserDisabled(requestContext(c), &authpb.SetUserDisabledRequest{
+		Token:    token,
+		Id:       id,
+		Disabled: disabled,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) SetUserRole(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req setUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.authClient.SetUserRole(requestContext(c), &authpb.SetUserRoleRequest{
+		Token: token,
+		Id:    id,
+		Role:  req.Role,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) ForceLogout(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	resp, err := g.authClient.ForceLogout(requestContext(c), &authpb.ForceLogoutRequest{
+		Token: token,
+		Id:    id,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) RestoreUser(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	resp, err := g.authClient.RestoreUser(requestContext(c), &authpb.RestoreUserRequest{
+		Token: token,
+		Id:    id,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Impersonate returns a short-lived token for the user, requests made with
+// it are logged with the admin as actor
+func (g *Gateway) Impersonate(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req impersonateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.authClient.Impersonate(requestContext(c), &authpb.ImpersonateRequest{
+		Token:      token,
+		Id:         id,
+		Reason:     req.Reason,
+		TtlMinutes: req.TTLMinutes,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	authpb "grpc/pb/auth"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// scopes checked by RequireScope, granted when an API key is created
+const (
+	scopeProductsRead  = "products:read"
+	scopeProductsWrite = "products:write"
+)
+
+type createAPIKeyRequest struct {
+	Name          string   `json:"name" binding:"required"`
+	Scopes        []string `json:"scopes" binding:"required"`
+	ExpiresInDays int32    `json:"expires_in_days"`
+}
+
+// handler untuk api key routes (/auth/me/api-keys), managing keys needs a
+// user session, an API key can't create other keys
+func (g *Gateway) CreateAPIKey(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	var req createAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.authClient.CreateAPIKey(requestContext(c), &authpb.CreateAPIKeyRequest{
+		Token:         token,
+		Name:          req.Name,
+		Scopes:        req.Scopes,
+		ExpiresInDays: req.ExpiresInDays,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (g *Gateway) ListAPIKeys(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	resp, err := g.authClient.ListAPIKeys(requestContext(c), &authpb.ListAPIKeysRequest{
+		Token: token,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) RevokeAPIKey(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	resp, err := g.authClient.RevokeAPIKey(requestContext(c), &authpb.RevokeAPIKeyRequest{
+		Token: token,
+		Id:    id,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
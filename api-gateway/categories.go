@@ -0,0 +1,168 @@
+package main
+
+import (
+	productpb "grpc/pb/product"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type createCategoryRequest struct {
+	Name     string `json:"name" binding:"required"`
+	ParentID uint64 `json:"parent_id"`
+}
+
+type updateCategoryRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type moveCategoryRequest struct {
+	ParentID uint64 `json:"parent_id"` // 0 makes it a root category
+}
+
+type setProductCategoryRequest struct {
+	CategoryID uint64 `json:"category_id"` // 0 removes the category
+}
+
+// handler untuk category routes, categories belong to the organization
+// active in the token like products
+func (g *Gateway) CreateCategory(c *gin.Context) {
+	var req createCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.productClient.CreateCategory(requestContext(c), &productpb.CreateCategoryRequest{
+		Name:     req.Name,
+		ParentId: req.ParentID,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (g *Gateway) ListCategories(c *gin.Context) {
+	resp, err := g.productClient.ListCategories(requestContext(c), &productpb.ListCategoriesRequest{})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) GetCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	resp, err := g.productClient.GetCategory(requestContext(c), &productpb.GetCategoryRequest{
+		Id: id,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) UpdateCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req updateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.productClient.UpdateCategory(requestContext(c), &productpb.UpdateCategoryRequest{
+		Id:   id,
+		Name: req.Name,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) MoveCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req moveCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.productClient.MoveCategory(requestContext(c), &productpb.MoveCategoryRequest{
+		Id:       id,
+		ParentId: req.ParentID,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) DeleteCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	resp, err := g.productClient.DeleteCategory(requestContext(c), &productpb.DeleteCategoryRequest{
+		Id: id,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) SetProductCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req setProductCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.productClient.SetProductCategory(requestContext(c), &productpb.SetProductCategoryRequest{
+		Id:         id,
+		CategoryId: req.CategoryID,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	productpb "grpc/pb/product"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRatesFileSize limits exchange rate imports, product-service takes at
+// most 1000 rates at once
+const maxRatesFileSize = 1 << 20
+
+type setRoundingRuleRequest struct {
+	IncrementMinor int64  `json:"increment_minor"`
+	Mode           string `json:"mode"` // half_up (default), up or down
+}
+
+// handler untuk price list routes, prices in the product currency are set
+// with unit_price
+func (g *Gateway) SetProductPrice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var price productpb.Money
+	if err := c.ShouldBindJSON(&price); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.productClient.SetProductPrice(requestContext(c), &productpb.SetProductPriceRequest{
+		ProductId: id,
+		Price:     &price,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) DeleteProductPrice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	resp, err := g.productClient.DeleteProductPrice(requestContext(c), &productpb.DeleteProductPriceRequest{
+		ProductId:    id,
+		CurrencyCode: c.Param("currency"),
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// handler untuk exchange rate routes, the product service checks the admin role
+func (g *Gateway) ListExchangeRates(c *gin.Context) {
+	resp, err := g.productClient.ListExchangeRates(requestContext(c), &productpb.ListExchangeRatesRequest{})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) SetExchangeRate(c *gin.Context) {
+	var req productpb.SetExchangeRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.productClient.SetExchangeRate(requestContext(c), &req)
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) DeleteExchangeRate(c *gin.Context) {
+	resp, err := g.productClient.DeleteExchangeRate(requestContext(c), &productpb.DeleteExchangeRateRequest{
+		FromCurrency: c.Param("from"),
+		ToCurrency:   c.Param("to"),
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ImportExchangeRates takes a CSV file as the multipart field "file"
+func (g *Gateway) ImportExchangeRates(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxRatesFileSize)
+
+	file, _, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing file"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read file"})
+		return
+	}
+
+	resp, err := g.productClient.ImportExchangeRates(requestContext(c), &productpb.ImportExchangeRatesRequest{
+		Csv: data,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) ListRoundingRules(c *gin.Context) {
+	resp, err := g.productClient.ListRoundingRules(requestContext(c), &productpb.ListRoundingRulesRequest{})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) SetRoundingRule(c *gin.Context) {
+	var req setRoundingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.productClient.SetRoundingRule(requestContext(c), &productpb.SetRoundingRuleRequest{
+		CurrencyCode:   c.Param("currency"),
+		IncrementMinor: req.IncrementMinor,
+		Mode:           req.Mode,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
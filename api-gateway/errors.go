@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type violation struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// writeGRPCError maps an error returned by a backend service to an HTTP
+// response, keeping structured violations when the service sent them
+func writeGRPCError(c *gin.Context, err error) {
+	st := status.Convert(err)
+
+	body := gin.H{"error": st.Message()}
+
+	var violations []violation
+	for _, detail := range st.Details() {
+		if failure, ok := detail.(*errdetails.PreconditionFailure); ok {
+			for _, v := range failure.Violations {
+				violations = append(violations, violation{
+					Field:   v.Subject,
+					Code:    v.Type,
+					Message: v.Description,
+				})
+			}
+		}
+	}
+	if len(violations) > 0 {
+		body["violations"] = violations
+	}
+
+	c.JSON(httpStatusFromCode(st.Code()), body)
+}
+
+func httpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
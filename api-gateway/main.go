@@ -1,11 +1,15 @@
 package main
 
 import (
-	"context"
+	"expvar"
+	"grpc/mtls"
 	authpb "grpc/pb/auth"
 	productpb "grpc/pb/product"
+	"grpc/serviceauth"
+	"grpc/tokencache"
 	"log"
 	"net/http"
+	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -17,26 +21,74 @@ import (
 )
 
 type Gateway struct {
-	authClient    authpb.AuthServiceClient
-	productClient productpb.ProductServiceClient
+	authClient      authpb.AuthServiceClient
+	productClient   productpb.ProductServiceClient
+	validationCache *tokencache.Cache[*authpb.ValidateResponse]
 }
 
 func NewGateway() (*Gateway, error) {
+	var opts []grpc.DialOption
+
+	// identify the gateway to the backend services with a service token
+	clientID := os.Getenv("SERVICE_CLIENT_ID")
+	if clientID != "" {
+		tokenURL := os.Getenv("SERVICE_TOKEN_URL")
+		if tokenURL == "" {
+			tokenURL = "http://localhost:8080/oauth/token"
+		}
+
+		tokens := serviceauth.NewTokenSource(tokenURL, clientID, os.Getenv("SERVICE_CLIENT_SECRET"))
+		opts = append(opts,
+			grpc.WithChainUnaryInterceptor(serviceauth.UnaryClientInterceptor(tokens)),
+			grpc.WithChainStreamInterceptor(serviceauth.StreamClientInterceptor(tokens)),
+		)
+	} else {
+		log.Println("Warning: SERVICE_CLIENT_ID not set, calls to backend services are not authenticated")
+	}
+
+	// optional mTLS, the server certificates must be issued for the service names
+	authCreds := insecure.NewCredentials()
+	productCreds := insecure.NewCredentials()
+	tlsConfig := mtls.Config{
+		CertFile: os.Getenv("GRPC_TLS_CERT_FILE"),
+		KeyFile:  os.Getenv("GRPC_TLS_KEY_FILE"),
+		CAFile:   os.Getenv("GRPC_TLS_CA_FILE"),
+	}
+	if tlsConfig.Enabled() {
+		reloader, err := mtls.NewReloader(tlsConfig)
+		if err != nil {
+			return nil, err
+		}
+		authCreds = mtls.ClientCredentials(reloader, "auth-service")
+		productCreds = mtls.ClientCredentials(reloader, "product-service")
+	}
+
 	// Connect to Auth Service
-	authConn, err := grpc.Dial("localhost:50051", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	authConn, err := grpc.Dial("localhost:50051", append(opts, grpc.WithTransportCredentials(authCreds))...)
 	if err != nil {
 		return nil, err
 	}
 
 	// Connect to Product Service
-	productConn, err := grpc.Dial("localhost:50052", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	productConn, err := grpc.Dial("localhost:50052", append(opts, grpc.WithTransportCredentials(productCreds))...)
 	if err != nil {
 		return nil, err
 	}
 
+	// cache Validate results, 0 disables the cache
+	cacheTTL := defaultValidationCacheTTL
+	if ttl, err := strconv.Atoi(os.Getenv("VALIDATION_CACHE_TTL_SECONDS")); err == nil && ttl >= 0 {
+		cacheTTL = time.Duration(ttl) * time.Second
+	}
+
+	authClient := authpb.NewAuthServiceClient(authConn)
+	validationCache := NewValidationCache(cacheTTL)
+	go validationCache.Watch(authClient)
+
 	return &Gateway{
-		authClient:    authpb.NewAuthServiceClient(authConn),
-		productClient: productpb.NewProductServiceClient(productConn),
+		authClient:      authClient,
+		productClient:   productpb.NewProductServiceClient(productConn),
+		validationCache: validationCache,
 	}, nil
 }
 
@@ -48,9 +100,9 @@ func (g *Gateway) Register(c *gin.Context) {
 		return
 	}
 
-	resp, err := g.authClient.Register(context.Background(), &req)
+	resp, err := g.authClient.Register(requestContext(c), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeGRPCError(c, err)
 		return
 	}
 
@@ -64,9 +116,9 @@ func (g *Gateway) Login(c *gin.Context) {
 		return
 	}
 
-	resp, err := g.authClient.Login(context.Background(), &req)
+	resp, err := g.authClient.Login(requestContext(c), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeGRPCError(c, err)
 		return
 	}
 
@@ -80,11 +132,11 @@ func (g *Gateway) Logout(c *gin.Context) {
 		return
 	}
 
-	resp, err := g.authClient.Logout(context.Background(), &authpb.LogoutRequest{
+	resp, err := g.authClient.Logout(requestContext(c), &authpb.LogoutRequest{
 		Token: token,
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeGRPCError(c, err)
 		return
 	}
 
@@ -99,9 +151,9 @@ func (g *Gateway) CreateProduct(c *gin.Context) {
 		return
 	}
 
-	resp, err := g.productClient.CreateProduct(context.Background(), &req)
+	resp, err := g.productClient.CreateProduct(requestContext(c), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeGRPCError(c, err)
 		return
 	}
 
@@ -112,14 +164,22 @@ func (g *Gateway) ListProducts(c *gin.Context) {
 	page := 1
 	perPage := 10
 	search := c.DefaultQuery("search", "")
+	// includes products of the subcategories
+	categoryID, err := strconv.ParseUint(c.DefaultQuery("category_id", "0"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category_id"})
+		return
+	}
 
-	resp, err := g.productClient.ListProducts(context.Background(), &productpb.ListProductsRequest{
-		Page:    int32(page),
-		PerPage: int32(perPage),
-		Search:  search,
+	resp, err := g.productClient.ListProducts(requestContext(c), &productpb.ListProductsRequest{
+		Page:         int32(page),
+		PerPage:      int32(perPage),
+		Search:       search,
+		CategoryId:   categoryID,
+		CurrencyCode: c.Query("currency"),
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeGRPCError(c, err)
 		return
 	}
 
@@ -133,11 +193,12 @@ func (g *Gateway) GetProduct(c *gin.Context) {
 		return
 	}
 
-	resp, err := g.productClient.GetProduct(context.Background(), &productpb.GetProductRequest{
-		Id: id,
+	resp, err := g.productClient.GetProduct(requestContext(c), &productpb.GetProductRequest{
+		Id:           id,
+		CurrencyCode: c.Query("currency"),
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeGRPCError(c, err)
 		return
 	}
 
@@ -158,9 +219,9 @@ func (g *Gateway) UpdateProduct(c *gin.Context) {
 	}
 	req.Id = id
 
-	resp, err := g.productClient.UpdateProduct(context.Background(), &req)
+	resp, err := g.productClient.UpdateProduct(requestContext(c), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeGRPCError(c, err)
 		return
 	}
 
@@ -174,42 +235,81 @@ func (g *Gateway) DeleteProduct(c *gin.Context) {
 		return
 	}
 
-	resp, err := g.productClient.DeleteProduct(context.Background(), &productpb.DeleteProductRequest{
+	resp, err := g.productClient.DeleteProduct(requestContext(c), &productpb.DeleteProductRequest{
 		Id: id,
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeGRPCError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, resp)
 }
 
+// AuthMiddleware accepts a JWT or an API key, either as a bearer token or in
+// the X-API-Key header
 func (g *Gateway) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token := extractToken(c)
+		if token == "" {
+			token = c.GetHeader("X-API-Key")
+		}
 		if token == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			c.Abort()
 			return
 		}
 
-		// validate token using Auth service
-		resp, err := g.authClient.Validate(context.Background(), &authpb.ValidateRequest{
-			Token: token,
-		})
-		if err != nil || !resp.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
-			c.Abort()
-			return
+		// validate token using Auth service, unless the result is cached
+		resp, generation, ok := g.validationCache.Get(token)
+		if !ok {
+			var err error
+			resp, err = g.authClient.Validate(requestContext(c), &authpb.ValidateRequest{
+				Token: token,
+			})
+			if err != nil || !resp.Valid || resp.User == nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+				c.Abort()
+				return
+			}
+			g.validationCache.Set(token, resp, generation, time.Time{})
 		}
 
-		// store user info in context
+		// store user info in context, the token is forwarded to backend services
+		c.Set("token", token)
 		c.Set("user_id", resp.User.Id)
+		c.Set("auth_type", resp.AuthType)
+		c.Set("scopes", resp.Scopes)
+		if resp.ActorId != 0 {
+			c.Set("actor_id", resp.ActorId)
+			log.Printf("Impersonation: admin %d as user %d %s %s (request %s)",
+				resp.ActorId, resp.User.Id, c.Request.Method, c.Request.URL.Path, c.GetString("request_id"))
+		}
 		c.Next()
 	}
 }
 
+// RequireScope limits API keys to the scopes they were created with, user
+// sessions have full access
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_type") != "api_key" {
+			c.Next()
+			return
+		}
+
+		for _, s := range c.GetStringSlice("scopes") {
+			if s == scope {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "api key is missing the " + scope + " scope"})
+		c.Abort()
+	}
+}
+
 func extractToken(c *gin.Context) string {
 	token := c.GetHeader("Authorization")
 	if token == "" {
@@ -231,13 +331,14 @@ func main() {
 	}
 
 	router := gin.Default()
+	router.Use(RequestIDMiddleware())
 
 	// Add CORS middleware
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"}, // Izinkan semua origin untuk development
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization", "X-API-Key", "X-Request-ID"},
+		ExposeHeaders:    []string{"Content-Length", "X-Request-ID"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -248,18 +349,119 @@ func main() {
 		auth.POST("/register", gateway.Register)
 		auth.POST("/login", gateway.Login)
 		auth.POST("/logout", gateway.Logout)
+		auth.GET("/email/confirm", gateway.ConfirmEmailChange)
+		auth.POST("/email/confirm", gateway.ConfirmEmailChange)
+		auth.POST("/magic-link", gateway.RequestMagicLink)
+		auth.GET("/magic-link/consume", gateway.ConfirmMagicLink)
+		auth.POST("/magic-link/consume", gateway.ConsumeMagicLink)
+
+		// account of the token owner
+		auth.GET("/me", gateway.GetProfile)
+		auth.PATCH("/me", gateway.UpdateProfile)
+		auth.DELETE("/me", gateway.DeleteAccount)
+		auth.GET("/me/export", gateway.ExportMyData)
+		auth.POST("/me/erase", gateway.EraseMyAccount)
+		auth.PUT("/me/password", gateway.ChangePassword)
+		auth.POST("/me/email", gateway.ChangeEmail)
+		auth.GET("/me/api-keys", gateway.ListAPIKeys)
+		auth.POST("/me/api-keys", gateway.CreateAPIKey)
+		auth.DELETE("/me/api-keys/:id", gateway.RevokeAPIKey)
+	}
+
+	// organizations of the token owner
+	orgs := router.Group("/orgs")
+	{
+		orgs.POST("", gateway.CreateOrganization)
+		orgs.GET("", gateway.ListOrganizations)
+		orgs.POST("/invitations/accept", gateway.AcceptInvitation)
+		orgs.POST("/:id/switch", gateway.SwitchOrganization)
+		orgs.GET("/:id/members", gateway.ListMembers)
+		orgs.POST("/:id/invitations", gateway.InviteMember)
+		orgs.DELETE("/:id/members/:user_id", gateway.RemoveMember)
 	}
 
 	// protected routes
 	products := router.Group("/products")
 	products.Use(gateway.AuthMiddleware())
 	{
-		products.POST("", gateway.CreateProduct)
-		products.GET("", gateway.ListProducts)
-		products.GET("/:id", gateway.GetProduct)
-		products.PUT("/:id", gateway.UpdateProduct)
-		products.DELETE("/:id", gateway.DeleteProduct)
+		products.POST("", RequireScope(scopeProductsWrite), gateway.CreateProduct)
+		products.GET("", RequireScope(scopeProductsRead), gateway.ListProducts)
+		products.GET("/:id", RequireScope(scopeProductsRead), gateway.GetProduct)
+		products.PUT("/:id", RequireScope(scopeProductsWrite), gateway.UpdateProduct)
+		products.DELETE("/:id", RequireScope(scopeProductsWrite), gateway.DeleteProduct)
+		products.PUT("/:id/category", RequireScope(scopeProductsWrite), gateway.SetProductCategory)
+		products.POST("/:id/variants", RequireScope(scopeProductsWrite), gateway.CreateVariant)
+		products.PUT("/:id/variants/:variant_id", RequireScope(scopeProductsWrite), gateway.UpdateVariant)
+		products.DELETE("/:id/variants/:variant_id", RequireScope(scopeProductsWrite), gateway.DeleteVariant)
+		products.POST("/:id/stock/reserve", RequireScope(scopeProductsWrite), gateway.ReserveStock)
+		products.POST("/:id/stock/release", RequireScope(scopeProductsWrite), gateway.ReleaseStock)
+		products.PUT("/:id/prices", RequireScope(scopeProductsWrite), gateway.SetProductPrice)
+		products.DELETE("/:id/prices/:currency", RequireScope(scopeProductsWrite), gateway.DeleteProductPrice)
+		products.GET("/:id/price-history", RequireScope(scopeProductsRead), gateway.GetPriceHistory)
+		products.POST("/:id/scheduled-prices", RequireScope(scopeProductsWrite), gateway.SchedulePriceChange)
+		products.GET("/:id/scheduled-prices", RequireScope(scopeProductsRead), gateway.ListScheduledPriceChanges)
+		products.POST("/:id/scheduled-prices/:change_id/cancel", RequireScope(scopeProductsWrite), gateway.CancelScheduledPriceChange)
+	}
+
+	// category tree of the active organization
+	categories := router.Group("/categories")
+	categories.Use(gateway.AuthMiddleware())
+	{
+		categories.POST("", RequireScope(scopeProductsWrite), gateway.CreateCategory)
+		categories.GET("", RequireScope(scopeProductsRead), gateway.ListCategories)
+		categories.GET("/:id", RequireScope(scopeProductsRead), gateway.GetCategory)
+		categories.PUT("/:id", RequireScope(scopeProductsWrite), gateway.UpdateCategory)
+		categories.PUT("/:id/parent", RequireScope(scopeProductsWrite), gateway.MoveCategory)
+		categories.DELETE("/:id", RequireScope(scopeProductsWrite), gateway.DeleteCategory)
 	}
 
+	// admin routes
+	admin := router.Group("/admin")
+	{
+		admin.GET("/users", gateway.ListUsers)
+		admin.GET("/users/:id", gateway.GetUser)
+		admin.POST("/users/:id/disable", gateway.DisableUser)
+		admin.POST("/users/:id/enable", gateway.EnableUser)
+		admin.PUT("/users/:id/role", gateway.SetUserRole)
+		admin.POST("/users/:id/logout", gateway.ForceLogout)
+		admin.POST("/users/:id/restore", gateway.RestoreUser)
+		admin.POST("/users/:id/impersonate", gateway.Impersonate)
+		admin.GET("/audit-events", gateway.ListAuditEvents)
+		admin.GET("/audit-events/export", gateway.ExportAuditEvents)
+		admin.GET("/invites", gateway.ListRegistrationInvites)
+		admin.POST("/invites", gateway.CreateRegistrationInvite)
+		admin.DELETE("/invites/:id", gateway.RevokeRegistrationInvite)
+	}
+
+	// exchange rates and rounding rules are shared by all organizations
+	currencies := router.Group("/admin")
+	currencies.Use(gateway.AuthMiddleware())
+	{
+		currencies.GET("/exchange-rates", gateway.ListExchangeRates)
+		currencies.PUT("/exchange-rates", gateway.SetExchangeRate)
+		currencies.POST("/exchange-rates/import", gateway.ImportExchangeRates)
+		currencies.DELETE("/exchange-rates/:from/:to", gateway.DeleteExchangeRate)
+		currencies.GET("/rounding-rules", gateway.ListRoundingRules)
+		currencies.PUT("/rounding-rules/:currency", gateway.SetRoundingRule)
+	}
+
+	// /debug/vars stays off the public port
+	metricsAddr := os.Getenv("METRICS_ADDR")
+	if metricsAddr == "" {
+		metricsAddr = "localhost:9090"
+	}
+	go serveMetrics(metricsAddr)
+
 	router.Run(":8000")
 }
+
+// serveMetrics serves expvar on an internal address
+func serveMetrics(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	log.Printf("Metrics are served on %s/debug/vars", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Printf("Warning: metrics server stopped: %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDMiddleware makes sure every request has an id that is returned
+// to the client and forwarded to backend services
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader("X-Request-ID")
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+
+		c.Set("request_id", requestID)
+		c.Header("X-Request-ID", requestID)
+		c.Next()
+	}
+}
+
+// requestContext builds the outgoing gRPC context, forwarding the original
+// client's address, user agent, request id and, behind AuthMiddleware, the
+// user token and the impersonating admin
+func requestContext(c *gin.Context) context.Context {
+	ctx := metadata.AppendToOutgoingContext(c.Request.Context(),
+		"x-request-id", c.GetString("request_id"),
+		"x-forwarded-for", c.ClientIP(),
+		"x-forwarded-user-agent", c.Request.UserAgent(),
+	)
+	if token := c.GetString("token"); token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	}
+	if actorID := c.GetUint64("actor_id"); actorID != 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-actor-id", strconv.FormatUint(actorID, 10))
+	}
+
+	return ctx
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	authpb "grpc/pb/auth"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type createOrganizationRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type inviteMemberRequest struct {
+	Email string `json:"email" binding:"required"`
+	Role  string `json:"role"`
+}
+
+// handler untuk organization routes (/orgs), products are scoped to the
+// organization active in the token
+func (g *Gateway) CreateOrganization(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	var req createOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.authClient.CreateOrganization(requestContext(c), &authpb.CreateOrganizationRequest{
+		Token: token,
+		Name:  req.Name,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (g *Gateway) ListOrganizations(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	resp, err := g.authClient.ListOrganizations(requestContext(c), &authpb.ListOrganizationsRequest{
+		Token: token,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) SwitchOrganization(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	resp, err := g.authClient.SwitchOrganization(requestContext(c), &authpb.SwitchOrganizationRequest{
+		Token:          token,
+		OrganizationId: id,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) ListMembers(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	resp, err := g.authClient.ListMembers(requestContext(c), &authpb.ListMembersRequest{
+		Token:          token,
+		OrganizationId: id,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) InviteMember(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req inviteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.authClient.InviteMember(requestContext(c), &authpb.InviteMemberRequest{
+		Token:          token,
+		OrganizationId: id,
+		Email:          req.Email,
+		Role:           req.Role,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// AcceptInvitation needs a session of the invited user, the invitation
+// token comes from the mailed link (?token=) or the body
+func (g *Gateway) AcceptInvitation(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	invitationToken := c.Query("token")
+	if invitationToken == "" {
+		var req struct {
+			Token string `json:"token"`
+		}
+		_ = c.ShouldBindJSON(&req)
+		invitationToken = req.Token
+	}
+	if invitationToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing invitation token"})
+		return
+	}
+
+	resp, err := g.authClient.AcceptInvitation(requestContext(c), &authpb.AcceptInvitationRequest{
+		Token:           token,
+		InvitationToken: invitationToken,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) RemoveMember(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	resp, err := g.authClient.RemoveMember(requestContext(c), &authpb.RemoveMemberRequest{
+		Token:          token,
+		OrganizationId: id,
+		UserId:         userID,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	productpb "grpc/pb/product"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type schedulePriceRequest struct {
+	UnitPrice   *productpb.Money `json:"unit_price" binding:"required"`   // currency_code defaults to the product currency
+	EffectiveAt string           `json:"effective_at" binding:"required"` // RFC 3339
+}
+
+// handler untuk price history routes
+func (g *Gateway) GetPriceHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+
+	resp, err := g.productClient.GetPriceHistory(requestContext(c), &productpb.GetPriceHistoryRequest{
+		ProductId: id,
+		Page:      int32(page),
+		PerPage:   int32(perPage),
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) SchedulePriceChange(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req schedulePriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.productClient.SchedulePriceChange(requestContext(c), &productpb.SchedulePriceChangeRequest{
+		ProductId:   id,
+		UnitPrice:   req.UnitPrice,
+		EffectiveAt: req.EffectiveAt,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (g *Gateway) ListScheduledPriceChanges(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	resp, err := g.productClient.ListScheduledPriceChanges(requestContext(c), &productpb.ListScheduledPriceChangesRequest{
+		ProductId: id,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) CancelScheduledPriceChange(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	changeID, err := strconv.ParseUint(c.Param("change_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid change_id"})
+		return
+	}
+
+	resp, err := g.productClient.CancelScheduledPriceChange(requestContext(c), &productpb.CancelScheduledPriceChangeRequest{
+		ProductId: id,
+		Id:        changeID,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
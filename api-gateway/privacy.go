@@ -0,0 +1,58 @@
+package main
+
+import (
+	authpb "grpc/pb/auth"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type eraseAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// handler untuk personal data routes (/auth/me/export, /auth/me/erase)
+func (g *Gateway) ExportMyData(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	resp, err := g.authClient.ExportMyData(requestContext(c), &authpb.ExportMyDataRequest{
+		Token: token,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	// the archive is already JSON, it is sent as a download
+	c.Header("Content-Disposition", `attachment; filename="my-data.json"`)
+	c.Data(http.StatusOK, "application/json", resp.Archive)
+}
+
+func (g *Gateway) EraseMyAccount(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	var req eraseAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.authClient.EraseMyAccount(requestContext(c), &authpb.EraseMyAccountRequest{
+		Token:    token,
+		Password: req.Password,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
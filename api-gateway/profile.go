@@ -0,0 +1,263 @@
+package main
+
+import (
+	authpb "grpc/pb/auth"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type updateProfileRequest struct {
+	DisplayName *string `json:"display_name"`
+	AvatarURL   *string `json:"avatar_url"`
+	Locale      *string `json:"locale"`
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type changeEmailRequest struct {
+	NewEmail string `json:"new_email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type deleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// handler untuk profile routes (/auth/me)
+func (g *Gateway) GetProfile(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	resp, err := g.authClient.GetProfile(requestContext(c), &authpb.GetProfileRequest{
+		Token: token,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) UpdateProfile(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	var req updateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.authClient.UpdateProfile(requestContext(c), &authpb.UpdateProfileRequest{
+		Token:       token,
+		DisplayName: req.DisplayName,
+		AvatarUrl:   req.AvatarURL,
+		Locale:      req.Locale,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) ChangePassword(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	var req changePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.authClient.ChangePassword(requestContext(c), &authpb.ChangePasswordRequest{
+		Token:           token,
+		CurrentPassword: req.CurrentPassword,
+		NewPassword:     req.NewPassword,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) ChangeEmail(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	var req changeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.authClient.ChangeEmail(requestContext(c), &authpb.ChangeEmailRequest{
+		Token:    token,
+		NewEmail: req.NewEmail,
+		Password: req.Password,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, resp)
+}
+
+// ConfirmEmailChange accepts the token as a query parameter so the link in
+// the verification email works, or as a JSON body
+func (g *Gateway) ConfirmEmailChange(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		var req struct {
+			Token string `json:"token"`
+		}
+		_ = c.ShouldBindJSON(&req)
+		token = req.Token
+	}
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing verification token"})
+		return
+	}
+
+	resp, err := g.authClient.ConfirmEmailChange(requestContext(c), &authpb.ConfirmEmailChangeRequest{
+		VerificationToken: token,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+type requestMagicLinkRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+// RequestMagicLink answers the same for unknown addresses
+func (g *Gateway) RequestMagicLink(c *gin.Context) {
+	var req requestMagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.authClient.RequestMagicLink(requestContext(c), &authpb.RequestMagicLinkRequest{
+		Email: req.Email,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, resp)
+}
+
+var confirmMagicLinkTemplate = template.Must(template.New("magic-link").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Sign in</title>
+</head>
+<body>
+<h1>Sign in</h1>
+<form method="post" action="/auth/magic-link/consume">
+<input type="hidden" name="token" value="{{.}}">
+<button type="submit">Continue</button>
+</form>
+</body>
+</html>
+`))
+
+// ConfirmMagicLink is the target of the emailed link. It only shows a button
+// that posts the token, mail scanners and link previews follow GET links and
+// would use up the link otherwise
+func (g *Gateway) ConfirmMagicLink(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing login token"})
+		return
+	}
+
+	c.Header("X-Frame-Options", "DENY")
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+
+	if err := confirmMagicLinkTemplate.Execute(c.Writer, token); err != nil {
+		c.Error(err)
+	}
+}
+
+// ConsumeMagicLink logs in with the token from the confirm page or posted by
+// a frontend as JSON
+func (g *Gateway) ConsumeMagicLink(c *gin.Context) {
+	var req struct {
+		Token string `json:"token" form:"token"`
+	}
+	_ = c.ShouldBind(&req)
+	token := req.Token
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing login token"})
+		return
+	}
+
+	resp, err := g.authClient.ConsumeMagicLink(requestContext(c), &authpb.ConsumeMagicLinkRequest{
+		Token: token,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) DeleteAccount(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	var req deleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.authClient.DeleteAccount(requestContext(c), &authpb.DeleteAccountRequest{
+		Token:    token,
+		Password: req.Password,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
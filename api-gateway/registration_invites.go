@@ -0,0 +1,87 @@
+package main
+
+import (
+	authpb "grpc/pb/auth"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type createRegistrationInviteRequest struct {
+	Role          string `json:"role"`
+	MaxUses       int32  `json:"max_uses"`
+	ExpiresInDays int32  `json:"expires_in_days"`
+}
+
+// handler untuk registration invite routes (/admin/invites), codes are
+// needed to register while REGISTRATION_MODE=invite_only
+func (g *Gateway) CreateRegistrationInvite(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	var req createRegistrationInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.authClient.CreateRegistrationInvite(requestContext(c), &authpb.CreateRegistrationInviteRequest{
+		Token:         token,
+		Role:          req.Role,
+		MaxUses:       req.MaxUses,
+		ExpiresInDays: req.ExpiresInDays,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (g *Gateway) ListRegistrationInvites(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	resp, err := g.authClient.ListRegistrationInvites(requestContext(c), &authpb.ListRegistrationInvitesRequest{
+		Token: token,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) RevokeRegistrationInvite(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	resp, err := g.authClient.RevokeRegistrationInvite(requestContext(c), &authpb.RevokeRegistrationInviteRequest{
+		Token: token,
+		Id:    id,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"expvar"
+	authpb "grpc/pb/auth"
+	"grpc/tokencache"
+	"time"
+)
+
+const (
+	defaultValidationCacheTTL = time.Minute
+	validationCacheMaxEntries = 10000
+)
+
+// cache metrics, exposed on /debug/vars
+var (
+	validationCacheHits   = expvar.NewInt("gateway_validation_cache_hits_total")
+	validationCacheMisses = expvar.NewInt("gateway_validation_cache_misses_total")
+)
+
+func init() {
+	expvar.Publish("gateway_validation_cache_hit_rate", expvar.Func(func() any {
+		hits, misses := validationCacheHits.Value(), validationCacheMisses.Value()
+		if hits+misses == 0 {
+			return 0.0
+		}
+		return float64(hits) / float64(hits+misses)
+	}))
+}
+
+// NewValidationCache keeps successful Validate results while the revocation
+// stream from auth-service is connected, a ttl of 0 disables it
+func NewValidationCache(ttl time.Duration) *tokencache.Cache[*authpb.ValidateResponse] {
+	return tokencache.New(ttl, validationCacheMaxEntries, func(resp *authpb.ValidateResponse) uint64 {
+		return resp.User.Id
+	}, tokencache.Metrics{
+		Hits:        validationCacheHits,
+		Misses:      validationCacheMisses,
+		Revocations: expvar.NewInt("gateway_validation_cache_revocations_total"),
+		Entries:     expvar.NewInt("gateway_validation_cache_entries"),
+		Watching:    expvar.NewInt("gateway_validation_cache_watching"),
+	})
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	productpb "grpc/pb/product"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type createVariantRequest struct {
+	SKU        string            `json:"sku" binding:"required"`
+	Attributes map[string]string `json:"attributes" binding:"required"`
+	Price      *float64          `json:"price"`      // deprecated, use unit_price
+	UnitPrice  *productpb.Money  `json:"unit_price"` // omitted uses the product price
+	Stock      int32             `json:"stock"`
+}
+
+type updateVariantRequest struct {
+	SKU        *string           `json:"sku"`
+	Attributes map[string]string `json:"attributes"` // replaces all attributes
+	Price      *float64          `json:"price"`      // deprecated, use unit_price
+	UnitPrice  *productpb.Money  `json:"unit_price"`
+	ClearPrice bool              `json:"clear_price"`
+	Stock      *int32            `json:"stock"`
+}
+
+type stockRequest struct {
+	VariantID     uint64 `json:"variant_id"`     // reserve, required when the product has variants
+	TTLSeconds    int32  `json:"ttl_seconds"`    // reserve, 0 is 15 minutes
+	ReservationID uint64 `json:"reservation_id"` // release, required
+	Quantity      int32  `json:"quantity"`       // release gives back all of it when 0
+}
+
+// handler untuk variant routes, variants are returned nested in GET /products/:id
+func (g *Gateway) CreateVariant(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req createVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.productClient.CreateVariant(requestContext(c), &productpb.CreateVariantRequest{
+		ProductId:  productID,
+		Sku:        req.SKU,
+		Attributes: req.Attributes,
+		Price:      req.Price,
+		UnitPrice:  req.UnitPrice,
+		Stock:      req.Stock,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (g *Gateway) UpdateVariant(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("variant_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid variant_id"})
+		return
+	}
+
+	var req updateVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := g.productClient.UpdateVariant(requestContext(c), &productpb.UpdateVariantRequest{
+		ProductId:  productID,
+		Id:         id,
+		Sku:        req.SKU,
+		Attributes: req.Attributes,
+		Price:      req.Price,
+		UnitPrice:  req.UnitPrice,
+		ClearPrice: req.ClearPrice,
+		Stock:      req.Stock,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) DeleteVariant(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("variant_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid variant_id"})
+		return
+	}
+
+	resp, err := g.productClient.DeleteVariant(requestContext(c), &productpb.DeleteVariantRequest{
+		ProductId: productID,
+		Id:        id,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) ReserveStock(c *gin.Context) {
+	req, ok := bindStockRequest(c)
+	if !ok {
+		return
+	}
+
+	resp, err := g.productClient.ReserveStock(requestContext(c), req)
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (g *Gateway) ReleaseStock(c *gin.Context) {
+	req, ok := bindStockRequest(c)
+	if !ok {
+		return
+	}
+
+	resp, err := g.productClient.ReleaseStock(requestContext(c), req)
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func bindStockRequest(c *gin.Context) (*productpb.StockRequest, bool) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return nil, false
+	}
+
+	var req stockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return &productpb.StockRequest{
+		ProductId:     productID,
+		VariantId:     req.VariantID,
+		Quantity:      req.Quantity,
+		ReservationId: req.ReservationID,
+		TtlSeconds:    req.TTLSeconds,
+	}, true
+}
@@ -8,20 +8,37 @@ import (
 	"auth-service/internal/service"
 	"auth-service/internal/usecase"
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"expvar"
 	"fmt"
+	"grpc/mtls"
+	productpb "grpc/pb/product"
+	"grpc/serviceauth"
 	"log"
+	nethttp "net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 func main() {
+	// AUTH_DEV_MODE allows running without the keys and secrets a deployment
+	// has to share between instances, never set it in production
+	devMode := getEnvBool("AUTH_DEV_MODE", false)
+
 	// init DB
 	dsn := os.Getenv("DATABASE_URL")
 	if dsn == "" {
@@ -33,12 +50,50 @@ func main() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	// personal data is encrypted with keys from PII_KEY_DIR, see make pii-keys
+	piiKeyDir := os.Getenv("PII_KEY_DIR")
+	if piiKeyDir == "" {
+		piiKeyDir = "keys"
+	}
+	keyring, err := service.LoadKeyring(piiKeyDir, os.Getenv("PII_ACTIVE_KEY"))
+	if err != nil {
+		log.Fatalf("Failed to load PII keys (run make pii-keys to create them): %v", err)
+	}
+	repository.RegisterPIISerializer(keyring)
+
 	if err = db.AutoMigrate(
 		&domain.User{},
+		&domain.AuditEvent{},
+		&domain.OAuthClient{},
+		&domain.AuthorizationCode{},
+		&domain.APIKey{},
+		&domain.Organization{},
+		&domain.Membership{},
+		&domain.Invitation{},
+		&domain.RegistrationInvite{},
+		&domain.MagicLink{},
+		&domain.UserEvent{},
 	); err != nil {
 		log.Fatalf("Auto migration failed: %v", err)
 	}
 
+	// lookups by email need the blind index of users stored in plaintext
+	if backfilled, err := repository.BackfillEmailIndex(db, keyring); err != nil {
+		log.Fatalf("Email index migration failed: %v", err)
+	} else if backfilled > 0 {
+		log.Printf("Added the email index of %d users", backfilled)
+	}
+
+	// usernames and emails are unique ignoring case, collisions from before
+	// have to be resolved by hand
+	collisions, err := repository.MigrateIdentityIndexes(db)
+	if err != nil {
+		log.Fatalf("Identity index migration failed: %v", err)
+	}
+	for _, c := range collisions {
+		log.Printf("Warning: users %v share the same %s ignoring case, the case-insensitive %s index is not created until they are renamed", c.UserIDs, c.Field, c.Field)
+	}
+
 	// init redis client
 	redisAddr := os.Getenv("REDIS_ADDR")
 	if redisAddr == "" {
@@ -55,34 +110,243 @@ func main() {
 	_, err = redisClient.Ping(ctx).Result()
 	if err != nil {
 		log.Printf("Warning: Redis connection failed: %v", err)
-		log.Println("Continuing without Redis - token blacklisting will not work")
+		log.Println("Continuing without Redis - token revocation is local to this instance")
 	}
 
 	// init repository
-	userRepo := repository.NewUserRepository(db)
+	userRepo := repository.NewUserRepository(db, keyring)
+	auditRepo := repository.NewAuditRepository(db)
+	oauthClientRepo := repository.NewOAuthClientRepository(db)
+	authCodeRepo := repository.NewAuthorizationCodeRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	orgRepo := repository.NewOrganizationRepository(db)
+	inviteRepo := repository.NewRegistrationInviteRepository(db)
+	magicLinkRepo := repository.NewMagicLinkRepository(db)
+	userEventRepo := repository.NewUserEventRepository(db)
 
 	// init services
+	// JWT_SECRET signed access tokens before they moved to the OIDC key, it
+	// only verifies those for one more day and stays in auth-service
 	jwtSecretKey := os.Getenv("JWT_SECRET")
 	if jwtSecretKey == "" {
 		jwtSecretKey = "rahasia"
 	}
-	tokenService := service.NewJwtTokenService(jwtSecretKey, redisClient)
+
+	// OpenID Connect issuer, must match the URL clients use to reach this service
+	oidcIssuer := os.Getenv("OIDC_ISSUER")
+	if oidcIssuer == "" {
+		oidcIssuer = "http://localhost:8080"
+	}
+	// signs access, ID and service tokens, other services verify them with the
+	// public key from /oauth/jwks. All instances need the same key
+	var signingKey *rsa.PrivateKey
+	if path := os.Getenv("OIDC_SIGNING_KEY_FILE"); path != "" {
+		signingKey, err = service.LoadRSAPrivateKey(path)
+		if err != nil {
+			log.Fatalf("Failed to load OIDC signing key: %v", err)
+		}
+	} else if !devMode {
+		log.Fatal("OIDC_SIGNING_KEY_FILE is required, set AUTH_DEV_MODE=true to sign with a temporary key")
+	} else {
+		log.Println("Warning: OIDC_SIGNING_KEY_FILE not set, tokens are signed with a temporary key and a restart logs everyone out")
+		signingKey, err = service.GenerateRSAPrivateKey()
+		if err != nil {
+			log.Fatalf("Failed to generate OIDC signing key: %v", err)
+		}
+	}
+
+	// "open" keeps accepting tokens while Redis is down, "closed" rejects them
+	revocationFailMode := service.RevocationFailMode(os.Getenv("REVOCATION_FAIL_MODE"))
+	if revocationFailMode != service.RevocationFailClosed {
+		revocationFailMode = service.RevocationFailOpen
+	}
+	tokenService := service.NewJwtTokenService(oidcIssuer, signingKey, jwtSecretKey, redisClient, revocationFailMode)
+
+	hasherConfig := service.DefaultPasswordHasherConfig()
+	if algorithm := os.Getenv("PASSWORD_HASH_ALGORITHM"); algorithm != "" {
+		hasherConfig.Algorithm = algorithm
+	}
+	hasherConfig.Argon2Memory = uint32(getEnvInt("ARGON2_MEMORY_KB", int(hasherConfig.Argon2Memory)))
+	hasherConfig.Argon2Time = uint32(getEnvInt("ARGON2_ITERATIONS", int(hasherConfig.Argon2Time)))
+	hasherConfig.Argon2Threads = uint8(getEnvInt("ARGON2_PARALLELISM", int(hasherConfig.Argon2Threads)))
+	hasherConfig.BcryptCost = getEnvInt("BCRYPT_COST", hasherConfig.BcryptCost)
+
+	passwordHasher, err := service.NewPasswordHasher(hasherConfig)
+	if err != nil {
+		log.Fatalf("Invalid password hasher config: %v", err)
+	}
+
+	policyConfig := service.DefaultPasswordPolicyConfig()
+	policyConfig.MinLength = getEnvInt("PASSWORD_MIN_LENGTH", policyConfig.MinLength)
+	policyConfig.MaxLength = getEnvInt("PASSWORD_MAX_LENGTH", policyConfig.MaxLength)
+	policyConfig.RequireUpper = getEnvBool("PASSWORD_REQUIRE_UPPER", policyConfig.RequireUpper)
+	policyConfig.RequireLower = getEnvBool("PASSWORD_REQUIRE_LOWER", policyConfig.RequireLower)
+	policyConfig.RequireDigit = getEnvBool("PASSWORD_REQUIRE_DIGIT", policyConfig.RequireDigit)
+	policyConfig.RequireSymbol = getEnvBool("PASSWORD_REQUIRE_SYMBOL", policyConfig.RequireSymbol)
+	policyConfig.DisallowUsername = getEnvBool("PASSWORD_DISALLOW_USERNAME", policyConfig.DisallowUsername)
+
+	var passwordDictionary map[string]struct{}
+	if path := os.Getenv("PASSWORD_DICTIONARY_FILE"); path != "" {
+		passwordDictionary, err = service.LoadPasswordDictionary(path)
+		if err != nil {
+			log.Fatalf("Failed to load password dictionary: %v", err)
+		}
+	}
+
+	var breachedPasswords domain.BreachedPasswordChecker
+	if path := os.Getenv("BREACHED_PASSWORDS_FILE"); path != "" {
+		breachedPasswords, err = service.NewBreachedPasswordList(path)
+		if err != nil {
+			log.Fatalf("Failed to load breached password list: %v", err)
+		}
+	}
+
+	passwordPolicy := service.NewPasswordPolicy(policyConfig, passwordDictionary, breachedPasswords)
+
+	// links in emails point to the public entry point (the API gateway)
+	appBaseURL := os.Getenv("APP_BASE_URL")
+	if appBaseURL == "" {
+		appBaseURL = "http://localhost:8000"
+	}
+	// MAIL_OUTBOX_DIR writes emails to files instead of the log
+	mailer := service.NewLogMailer()
+	if dir := os.Getenv("MAIL_OUTBOX_DIR"); dir != "" {
+		mailer, err = service.NewFileMailer(dir)
+		if err != nil {
+			log.Fatalf("Failed to create mail outbox: %v", err)
+		}
+	}
+	// signs login links, a leaked secret lets anyone log in as anyone
+	magicLinkSecret := os.Getenv("MAGIC_LINK_SECRET")
+	switch {
+	case magicLinkSecret != "" && magicLinkSecret == jwtSecretKey:
+		log.Fatal("MAGIC_LINK_SECRET must differ from JWT_SECRET")
+	case magicLinkSecret == "" && !devMode:
+		log.Fatal("MAGIC_LINK_SECRET is required, set AUTH_DEV_MODE=true to use a temporary secret")
+	case magicLinkSecret == "":
+		log.Println("Warning: MAGIC_LINK_SECRET not set, login links only work on this instance until it restarts")
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatalf("Failed to generate magic link secret: %v", err)
+		}
+		magicLinkSecret = hex.EncodeToString(secret)
+	}
+
+	registrationMode := getRegistrationMode()
+	// erased accounts stay anonymized this long before they are purged
+	erasureGracePeriod := time.Duration(getEnvInt("ERASURE_GRACE_DAYS", 30)) * 24 * time.Hour
+
+	idTokenService := service.NewIDTokenService(oidcIssuer, signingKey)
+	serviceTokenService := service.NewServiceTokenService(oidcIssuer, signingKey, time.Duration(getEnvInt("SERVICE_TOKEN_TTL_SECONDS", 300))*time.Second)
+	auditLogger := service.NewAuditLogger(auditRepo)
+	revocationNotifier := service.NewRevocationNotifier(redisClient)
+
+	// optional mTLS on the gRPC server
+	var grpcCreds credentials.TransportCredentials
+	productCreds := insecure.NewCredentials()
+	tlsConfig := mtls.Config{
+		CertFile: os.Getenv("GRPC_TLS_CERT_FILE"),
+		KeyFile:  os.Getenv("GRPC_TLS_KEY_FILE"),
+		CAFile:   os.Getenv("GRPC_TLS_CA_FILE"),
+	}
+	if tlsConfig.Enabled() {
+		reloader, err := mtls.NewReloader(tlsConfig)
+		if err != nil {
+			log.Fatalf("Failed to load gRPC TLS certificates: %v", err)
+		}
+		// e.g. GRPC_TLS_ALLOWED_PEERS=api-gateway
+		var allowedPeers []string
+		if peers := os.Getenv("GRPC_TLS_ALLOWED_PEERS"); peers != "" {
+			allowedPeers = strings.Split(peers, ",")
+		}
+		grpcCreds = mtls.ServerCredentials(reloader, allowedPeers)
+		productCreds = mtls.ClientCredentials(reloader, "product-service")
+	}
+
+	// product-service is called for data exports, with auth-service's own
+	// service account
+	productAddr := os.Getenv("PRODUCT_SERVICE_ADDR")
+	if productAddr == "" {
+		productAddr = "localhost:50052"
+	}
+	productOpts := []grpclib.DialOption{grpclib.WithTransportCredentials(productCreds)}
+	if clientID := os.Getenv("SERVICE_CLIENT_ID"); clientID != "" {
+		tokenURL := os.Getenv("SERVICE_TOKEN_URL")
+		if tokenURL == "" {
+			tokenURL = oidcIssuer + "/oauth/token"
+		}
+
+		tokens := serviceauth.NewTokenSource(tokenURL, clientID, os.Getenv("SERVICE_CLIENT_SECRET"))
+		productOpts = append(productOpts,
+			grpclib.WithUnaryInterceptor(serviceauth.UnaryClientInterceptor(tokens)),
+			grpclib.WithStreamInterceptor(serviceauth.StreamClientInterceptor(tokens)),
+		)
+	} else {
+		log.Println("Warning: SERVICE_CLIENT_ID not set, calls to product-service are not authenticated")
+	}
+	productConn, err := grpclib.Dial(productAddr, productOpts...)
+	if err != nil {
+		log.Fatalf("Failed to connect to product-service: %v", err)
+	}
+	defer productConn.Close()
+	productExporter := service.NewProductExporter(productpb.NewProductServiceClient(productConn))
 
 	// init use cases
-	authUseCase := usecase.NewAuthUseCase(userRepo, tokenService)
+	authUseCase := usecase.NewAuthUseCase(userRepo, orgRepo, inviteRepo, tokenService, passwordHasher, passwordPolicy, mailer, auditLogger, revocationNotifier, keyring, registrationMode, appBaseURL)
+	adminUseCase := usecase.NewAdminUseCase(authUseCase, userRepo, orgRepo, tokenService, auditLogger, revocationNotifier)
+	auditUseCase := usecase.NewAuditUseCase(authUseCase, auditRepo)
+	oauthUseCase := usecase.NewOAuthUseCase(authUseCase, tokenService, serviceTokenService, oauthClientRepo, passwordHasher, auditLogger, revocationNotifier)
+	apiKeyUseCase := usecase.NewAPIKeyUseCase(authUseCase, userRepo, apiKeyRepo, auditLogger, revocationNotifier)
+	orgUseCase := usecase.NewOrganizationUseCase(authUseCase, orgRepo, tokenService, mailer, auditLogger, revocationNotifier, appBaseURL)
+	inviteUseCase := usecase.NewRegistrationInviteUseCase(authUseCase, inviteRepo, auditLogger)
+	privacyUseCase := usecase.NewPrivacyUseCase(authUseCase, userRepo, orgRepo, apiKeyRepo, auditRepo, userEventRepo, passwordHasher, tokenService, productExporter, auditLogger, revocationNotifier, erasureGracePeriod)
+	magicLinkUseCase := usecase.NewMagicLinkUseCase(userRepo, orgRepo, magicLinkRepo, tokenService, service.NewLinkSigner(magicLinkSecret), service.NewRateLimiter(redisClient), mailer, auditLogger, appBaseURL)
+	oidcUseCase := usecase.NewOIDCUseCase(authUseCase, userRepo, tokenService, idTokenService, oauthClientRepo, authCodeRepo, passwordHasher, auditLogger)
+
+	go purgeErasedAccounts(privacyUseCase)
+	go reencryptUsers(db, keyring)
+
+	// promote the configured users so there is always a way in. By id, with
+	// open registration anyone could take a configured username
+	if admins := os.Getenv("ADMIN_USER_IDS"); admins != "" {
+		bootstrapAdmins(userRepo, strings.Split(admins, ","))
+	}
+	if os.Getenv("ADMIN_USERNAMES") != "" {
+		log.Println("Warning: ADMIN_USERNAMES is ignored, use ADMIN_USER_IDS")
+	}
+
+	// resource servers allowed to introspect and revoke tokens,
+	// format: client_id:secret,client_id:secret
+	if clients := os.Getenv("OAUTH_CLIENTS"); clients != "" {
+		bootstrapOAuthClients(oauthClientRepo, passwordHasher, strings.Split(clients, ","))
+	}
+
+	// services calling each other over gRPC,
+	// format: client_id:secret:scope scope,client_id:secret:scope
+	if accounts := os.Getenv("SERVICE_ACCOUNTS"); accounts != "" {
+		bootstrapServiceAccounts(oauthClientRepo, passwordHasher, strings.Split(accounts, ","))
+	}
+
+	// gRPC callers present service tokens signed with our own key
+	serviceVerifier := serviceauth.NewVerifier(oidcIssuer, service.ServiceTokenKeys(signingKey))
 
 	// init HTTP handler
 	authHandler := http.NewAuthHandler(authUseCase)
+	oauthHandler := http.NewOAuthHandler(oauthUseCase)
+	oidcHandler := http.NewOIDCHandler(oidcUseCase, oauthUseCase, idTokenService, oidcIssuer)
 
 	// init gRPC handler
-	grpcHandler := grpc.NewGRPCHandler(authUseCase)
+	grpcHandler := grpc.NewGRPCHandler(authUseCase, adminUseCase, auditUseCase, apiKeyUseCase, orgUseCase, inviteUseCase, magicLinkUseCase, privacyUseCase, revocationNotifier)
 
 	// init gin router
 	router := gin.Default()
 	router.Use(CorsMiddleware())
+	router.Use(http.RequestMetaMiddleware())
 
 	// register routes
 	authHandler.RegisterRoutes(router)
+	oauthHandler.RegisterRoutes(router)
+	oidcHandler.RegisterRoutes(router)
 
 	// channel get signal shutdown
 	sigChan := make(chan os.Signal, 1)
@@ -103,6 +367,13 @@ func main() {
 		}
 	}()
 
+	// /debug/vars stays off the public port
+	metricsAddr := os.Getenv("METRICS_ADDR")
+	if metricsAddr == "" {
+		metricsAddr = "localhost:9091"
+	}
+	go serveMetrics(metricsAddr)
+
 	// start gRPC server
 	grpcPort := os.Getenv("GRPC_PORT")
 	if grpcPort == "" {
@@ -111,7 +382,7 @@ func main() {
 
 	go func() {
 		log.Printf("gRPC server is running on :%s", grpcPort)
-		if err := grpcHandler.Serve(":" + grpcPort); err != nil {
+		if err := grpcHandler.Serve(":"+grpcPort, serviceVerifier, getServiceAuthMode(), grpcCreds); err != nil {
 			errChan <- fmt.Errorf("Failed to start gRPC server: %v", err)
 		}
 	}()
@@ -128,6 +399,185 @@ func main() {
 	log.Println("Shutting down server...")
 }
 
+// serveMetrics serves expvar on an internal address
+func serveMetrics(addr string) {
+	mux := nethttp.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	log.Printf("Metrics are served on %s/debug/vars", addr)
+	if err := nethttp.ListenAndServe(addr, mux); err != nil {
+		log.Printf("Warning: metrics server stopped: %v", err)
+	}
+}
+
+func bootstrapAdmins(userRepo domain.UserRepository, ids []string) {
+	for _, entry := range ids {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(entry, 10, 64)
+		if err != nil {
+			log.Printf("Warning: invalid ADMIN_USER_IDS entry %q", entry)
+			continue
+		}
+
+		user, err := userRepo.FindByID(id)
+		if err != nil {
+			log.Printf("Warning: admin user %d not found: %v", id, err)
+			continue
+		}
+		if user.IsAdmin() {
+			continue
+		}
+
+		user.Role = domain.RoleAdmin
+		if err := userRepo.Update(user); err != nil {
+			log.Printf("Warning: failed to promote user %d to admin: %v", id, err)
+			continue
+		}
+		log.Printf("Promoted user %d to admin", id)
+	}
+}
+
+func bootstrapOAuthClients(clientRepo domain.OAuthClientRepository, hasher domain.PasswordHasher, entries []string) {
+	// resource servers revoke the session tokens they receive
+	scopes := domain.ScopeIntrospect + " " + domain.ScopeRevoke + " " + domain.ScopeRevokeAny
+
+	for _, entry := range entries {
+		clientID, secret, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || clientID == "" || secret == "" {
+			log.Printf("Warning: invalid OAUTH_CLIENTS entry %q", entry)
+			continue
+		}
+
+		upsertOAuthClient(clientRepo, hasher, clientID, secret, scopes, false)
+	}
+}
+
+func bootstrapServiceAccounts(clientRepo domain.OAuthClientRepository, hasher domain.PasswordHasher, entries []string) {
+	for _, entry := range entries {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" || strings.TrimSpace(parts[2]) == "" {
+			log.Printf("Warning: invalid SERVICE_ACCOUNTS entry %q", entry)
+			continue
+		}
+
+		upsertOAuthClient(clientRepo, hasher, parts[0], parts[1], strings.Join(strings.Fields(parts[2]), " "), true)
+	}
+}
+
+func upsertOAuthClient(clientRepo domain.OAuthClientRepository, hasher domain.PasswordHasher, clientID, secret, scopes string, serviceAccount bool) {
+	client, err := clientRepo.FindByClientID(clientID)
+	if err != nil {
+		client = &domain.OAuthClient{ClientID: clientID, Name: clientID}
+	}
+	// a secret that still matches keeps its hash, hashing on every start is slow
+	if match, _ := hasher.Verify(secret, client.SecretHash); !match || hasher.NeedsRehash(client.SecretHash) {
+		if client.SecretHash, err = hasher.Hash(secret); err != nil {
+			log.Printf("Warning: failed to hash the secret of OAuth client %q: %v", clientID, err)
+			return
+		}
+	}
+	client.Scopes = scopes
+	client.ServiceAccount = serviceAccount
+
+	if client.ID == 0 {
+		err = clientRepo.Create(client)
+	} else {
+		err = clientRepo.Update(client)
+	}
+	if err != nil {
+		log.Printf("Warning: failed to register OAuth client %q: %v", clientID, err)
+	}
+}
+
+// getServiceAuthMode reads SERVICE_AUTH_MODE, "permissive" only logs calls
+// without a valid service token
+func getServiceAuthMode() serviceauth.Mode {
+	if serviceauth.Mode(os.Getenv("SERVICE_AUTH_MODE")) == serviceauth.ModePermissive {
+		log.Println("Warning: SERVICE_AUTH_MODE=permissive, unauthenticated gRPC calls are accepted")
+		return serviceauth.ModePermissive
+	}
+	return serviceauth.ModeRequired
+}
+
+// purgeErasedAccounts removes erased accounts once their grace period is
+// over, it never returns
+func purgeErasedAccounts(privacyUseCase domain.PrivacyUseCase) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		purged, err := privacyUseCase.PurgeErased(context.Background())
+		if err != nil {
+			log.Printf("Failed to purge erased accounts: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d erased accounts", purged)
+		}
+		<-ticker.C
+	}
+}
+
+// reencryptUsers encrypts plaintext rows and rows encrypted with a key that
+// is no longer active, it keeps checking for rows written by older instances
+func reencryptUsers(db *gorm.DB, keyring domain.Keyring) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		updated, err := repository.ReencryptUsers(db, keyring)
+		if err != nil {
+			log.Printf("Failed to re-encrypt users: %v", err)
+		} else if updated > 0 {
+			log.Printf("Re-encrypted the personal data of %d users", updated)
+		}
+		<-ticker.C
+	}
+}
+
+// getRegistrationMode reads REGISTRATION_MODE, registration is open by default
+func getRegistrationMode() string {
+	switch mode := os.Getenv("REGISTRATION_MODE"); mode {
+	case "", domain.RegistrationOpen:
+		return domain.RegistrationOpen
+	case domain.RegistrationInviteOnly, domain.RegistrationClosed:
+		log.Printf("Registration mode: %s", mode)
+		return mode
+	default:
+		log.Fatalf("Invalid value for REGISTRATION_MODE: %q (open, invite_only or closed)", mode)
+		return ""
+	}
+}
+
+func getEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("Invalid value for %s: %v", key, err)
+	}
+
+	return n
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Fatalf("Invalid value for %s: %v", key, err)
+	}
+
+	return b
+}
+
 func CorsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
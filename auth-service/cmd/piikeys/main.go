@@ -0,0 +1,40 @@
+// piikeys adds a key encryption key to the PII keyring of auth-service and
+// creates the blind index key on first use. The newest key becomes the
+// active one on the next start, older keys must be kept until
+// re-encryption finished
+package main
+
+import (
+	"auth-service/internal/service"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func main() {
+	dir := flag.String("dir", "keys", "keyring directory")
+	flag.Parse()
+
+	if err := os.MkdirAll(*dir, 0o700); err != nil {
+		log.Fatalf("Failed to create %s: %v", *dir, err)
+	}
+
+	// the blind index key can't be rotated, never replace it
+	indexPath := filepath.Join(*dir, service.BlindIndexKeyFile)
+	if err := service.GenerateKeyFile(indexPath); err == nil {
+		log.Printf("Wrote %s", indexPath)
+	} else if !errors.Is(err, os.ErrExist) {
+		log.Fatalf("Failed to write %s: %v", indexPath, err)
+	}
+
+	// ids sort by creation time, so the highest is the newest
+	id := time.Now().UTC().Format("20060102T150405")
+	path := filepath.Join(*dir, service.KeyFileName(id))
+	if err := service.GenerateKeyFile(path); err != nil {
+		log.Fatalf("Failed to write %s: %v", path, err)
+	}
+	log.Printf("Wrote %s", path)
+}
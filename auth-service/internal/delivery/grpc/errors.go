@@ -0,0 +1,74 @@
+package grpc
+
+import (
+	"auth-service/internal/domain"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatusError converts domain errors into gRPC status errors carrying
+// details the gateway can turn into structured responses
+func toStatusError(err error) error {
+	var policyErr *domain.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		st := status.New(codes.InvalidArgument, "password does not meet policy")
+		failure := &errdetails.PreconditionFailure{}
+		for _, v := range policyErr.Violations {
+			failure.Violations = append(failure.Violations, &errdetails.PreconditionFailure_Violation{
+				Type:        v.Code,
+				Subject:     "password",
+				Description: v.Message,
+			})
+		}
+
+		return withDetails(st, failure)
+	}
+
+	var validationErr *domain.ValidationError
+	if errors.As(err, &validationErr) {
+		st := status.New(codes.InvalidArgument, validationErr.Error())
+		return withDetails(st, &errdetails.PreconditionFailure{
+			Violations: []*errdetails.PreconditionFailure_Violation{{
+				Type:        "invalid",
+				Subject:     validationErr.Field,
+				Description: validationErr.Message,
+			}},
+		})
+	}
+
+	switch {
+	case errors.Is(err, domain.ErrInvalidToken), errors.Is(err, domain.ErrInvalidCredentials), errors.Is(err, domain.ErrTokenRevoked), errors.Is(err, domain.ErrMagicLinkInvalid):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, domain.ErrForbidden), errors.Is(err, domain.ErrAccountDisabled), errors.Is(err, domain.ErrSelfModification),
+		errors.Is(err, domain.ErrRegistrationClosed), errors.Is(err, domain.ErrInviteRequired), errors.Is(err, domain.ErrInviteNotFound),
+		errors.Is(err, domain.ErrImpersonationForbidden), errors.Is(err, domain.ErrCannotImpersonate):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, domain.ErrInvalidRole):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrIncorrectPassword), errors.Is(err, domain.ErrInvalidVerification):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrUserNotFound), errors.Is(err, domain.ErrAPIKeyNotFound), errors.Is(err, domain.ErrOrganizationNotFound), errors.Is(err, domain.ErrInvitationNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrUsernameTaken), errors.Is(err, domain.ErrEmailTaken), errors.Is(err, domain.ErrAlreadyMember):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, domain.ErrAPIKeyLimit), errors.Is(err, domain.ErrOwnsSharedOrganization), errors.Is(err, domain.ErrUserErased):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrRateLimited):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, domain.ErrRevocationUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	return err
+}
+
+func withDetails(st *status.Status, failure *errdetails.PreconditionFailure) error {
+	if detailed, err := st.WithDetails(failure); err == nil {
+		return detailed.Err()
+	}
+
+	return st.Err()
+}
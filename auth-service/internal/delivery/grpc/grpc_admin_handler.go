@@ -0,0 +1,105 @@
+package grpc
+
+import (
+	"auth-service/internal/domain"
+	"context"
+	pb "grpc/pb/auth"
+	"time"
+)
+
+func (h *GRPCHandler) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	users, total, err := h.adminUseCase.ListUsers(ctx, req.Token, domain.UserFilter{
+		Page:   req.Page,
+		Limit:  req.PerPage,
+		Search: req.Search,
+		Role:   req.Role,
+		Status: domain.UserStatus(req.Status),
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	protoUsers := make([]*pb.UserData, len(users))
+	for i, user := range users {
+		protoUsers[i] = toProtoUser(&user)
+	}
+
+	page, perPage := req.Page, req.PerPage
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = 10
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+
+	return &pb.ListUsersResponse{
+		Users: protoUsers,
+		Meta: &pb.Meta{
+			Total:      int32(total),
+			Page:       page,
+			PerPage:    perPage,
+			TotalPages: int32((total + int64(perPage) - 1) / int64(perPage)),
+		},
+	}, nil
+}
+
+func (h *GRPCHandler) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.UserData, error) {
+	user, err := h.adminUseCase.GetUser(ctx, req.Token, req.Id)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toProtoUser(user), nil
+}
+
+func (h *GRPCHandler) SetUserDisabled(ctx context.Context, req *pb.SetUserDisabledRequest) (*pb.UserData, error) {
+	user, err := h.adminUseCase.SetUserDisabled(ctx, req.Token, req.Id, req.Disabled)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toProtoUser(user), nil
+}
+
+func (h *GRPCHandler) SetUserRole(ctx context.Context, req *pb.SetUserRoleRequest) (*pb.UserData, error) {
+	user, err := h.adminUseCase.SetUserRole(ctx, req.Token, req.Id, req.Role)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toProtoUser(user), nil
+}
+
+func (h *GRPCHandler) ForceLogout(ctx context.Context, req *pb.ForceLogoutRequest) (*pb.ForceLogoutResponse, error) {
+	err := h.adminUseCase.ForceLogout(ctx, req.Token, req.Id)
+	if err != nil {
+		return &pb.ForceLogoutResponse{Success: false}, toStatusError(err)
+	}
+
+	return &pb.ForceLogoutResponse{Success: true}, nil
+}
+
+func (h *GRPCHandler) RestoreUser(ctx context.Context, req *pb.RestoreUserRequest) (*pb.UserData, error) {
+	user, err := h.adminUseCase.RestoreUser(ctx, req.Token, req.Id)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toProtoUser(user), nil
+}
+
+func (h *GRPCHandler) Impersonate(ctx context.Context, req *pb.ImpersonateRequest) (*pb.ImpersonateResponse, error) {
+	ttl := time.Duration(req.TtlMinutes) * time.Minute
+	token, expiresAt, err := h.adminUseCase.Impersonate(ctx, req.Token, req.Id, req.Reason, ttl)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &pb.ImpersonateResponse{
+		Token:     token,
+		ExpiresAt: expiresAt.Format(time.RFC3339),
+	}, nil
+}
@@ -0,0 +1,62 @@
+package grpc
+
+import (
+	"auth-service/internal/domain"
+	"context"
+	pb "grpc/pb/auth"
+	"time"
+)
+
+func (h *GRPCHandler) CreateAPIKey(ctx context.Context, req *pb.CreateAPIKeyRequest) (*pb.CreateAPIKeyResponse, error) {
+	key, plainKey, err := h.apiKeyUseCase.CreateAPIKey(ctx, req.Token, req.Name, req.Scopes, int(req.ExpiresInDays))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &pb.CreateAPIKeyResponse{
+		ApiKey: toProtoAPIKey(key),
+		Key:    plainKey,
+	}, nil
+}
+
+func (h *GRPCHandler) ListAPIKeys(ctx context.Context, req *pb.ListAPIKeysRequest) (*pb.ListAPIKeysResponse, error) {
+	keys, err := h.apiKeyUseCase.ListAPIKeys(ctx, req.Token)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	protoKeys := make([]*pb.APIKey, len(keys))
+	for i, key := range keys {
+		protoKeys[i] = toProtoAPIKey(&key)
+	}
+
+	return &pb.ListAPIKeysResponse{ApiKeys: protoKeys}, nil
+}
+
+func (h *GRPCHandler) RevokeAPIKey(ctx context.Context, req *pb.RevokeAPIKeyRequest) (*pb.RevokeAPIKeyResponse, error) {
+	if err := h.apiKeyUseCase.RevokeAPIKey(ctx, req.Token, req.Id); err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &pb.RevokeAPIKeyResponse{Success: true}, nil
+}
+
+func toProtoAPIKey(key *domain.APIKey) *pb.APIKey {
+	data := &pb.APIKey{
+		Id:             key.ID,
+		OrganizationId: key.OrganizationID,
+		Name:           key.Name,
+		Prefix:         key.Prefix,
+		Scopes:         key.ScopeList(),
+		LastUsedIp:     key.LastUsedIP,
+		CreatedAt:      key.CreatedAt.Format(time.RFC3339),
+	}
+	if key.ExpiresAt != nil {
+		data.ExpiresAt = key.ExpiresAt.Format(time.RFC3339)
+	}
+	if key.LastUsedAt != nil {
+		data.LastUsedAt = key.LastUsedAt.Format(time.RFC3339)
+	}
+
+	return data
+}
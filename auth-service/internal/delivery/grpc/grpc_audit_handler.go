@@ -0,0 +1,108 @@
+package grpc
+
+import (
+	"auth-service/internal/domain"
+	"context"
+	pb "grpc/pb/auth"
+	"time"
+)
+
+func (h *GRPCHandler) ListAuditEvents(ctx context.Context, req *pb.ListAuditEventsRequest) (*pb.ListAuditEventsResponse, error) {
+	filter, err := toAuditFilter(req.Filter)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	filter.Page = req.Page
+	filter.Limit = req.PerPage
+
+	events, total, err := h.auditUseCase.ListEvents(ctx, req.Token, filter)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	protoEvents := make([]*pb.AuditEvent, len(events))
+	for i, event := range events {
+		protoEvents[i] = toProtoAuditEvent(&event)
+	}
+
+	page, perPage := req.Page, req.PerPage
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = 50
+	}
+	if perPage > 500 {
+		perPage = 500
+	}
+
+	return &pb.ListAuditEventsResponse{
+		Events: protoEvents,
+		Meta: &pb.Meta{
+			Total:      int32(total),
+			Page:       page,
+			PerPage:    perPage,
+			TotalPages: int32((total + int64(perPage) - 1) / int64(perPage)),
+		},
+	}, nil
+}
+
+func (h *GRPCHandler) ExportAuditEvents(req *pb.ExportAuditEventsRequest, stream pb.AuthService_ExportAuditEventsServer) error {
+	filter, err := toAuditFilter(req.Filter)
+	if err != nil {
+		return toStatusError(err)
+	}
+
+	err = h.auditUseCase.ExportEvents(stream.Context(), req.Token, filter, func(event *domain.AuditEvent) error {
+		return stream.Send(toProtoAuditEvent(event))
+	})
+	if err != nil {
+		return toStatusError(err)
+	}
+
+	return nil
+}
+
+func toAuditFilter(filter *pb.AuditEventFilter) (domain.AuditFilter, error) {
+	var result domain.AuditFilter
+	if filter == nil {
+		return result, nil
+	}
+
+	result.UserID = filter.UserId
+	result.ActorID = filter.ActorId
+	result.Type = filter.Type
+	result.IP = filter.Ip
+	result.RequestID = filter.RequestId
+
+	if filter.From != "" {
+		from, err := time.Parse(time.RFC3339, filter.From)
+		if err != nil {
+			return result, &domain.ValidationError{Field: "from", Message: "must be an RFC 3339 timestamp"}
+		}
+		result.From = from
+	}
+	if filter.To != "" {
+		to, err := time.Parse(time.RFC3339, filter.To)
+		if err != nil {
+			return result, &domain.ValidationError{Field: "to", Message: "must be an RFC 3339 timestamp"}
+		}
+		result.To = to
+	}
+
+	return result, nil
+}
+
+func toProtoAuditEvent(event *domain.AuditEvent) *pb.AuditEvent {
+	return &pb.AuditEvent{
+		Id:        event.ID,
+		Type:      event.Type,
+		UserId:    event.UserID,
+		ActorId:   event.ActorID,
+		Ip:        event.IP,
+		UserAgent: event.UserAgent,
+		RequestId: event.RequestID,
+		Metadata:  event.Metadata,
+		CreatedAt: event.CreatedAt.Format(time.RFC3339),
+	}
+}
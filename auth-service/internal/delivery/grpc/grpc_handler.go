@@ -4,72 +4,122 @@ import (
 	"auth-service/internal/domain"
 	"context"
 	pb "grpc/pb/auth"
+	"grpc/serviceauth"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 type GRPCHandler struct {
 	pb.UnimplementedAuthServiceServer
-	authUseCase domain.AuthUseCase
+	authUseCase    domain.AuthUseCase
+	adminUseCase   domain.AdminUseCase
+	auditUseCase   domain.AuditUseCase
+	apiKeyUseCase  domain.APIKeyUseCase
+	orgUseCase     domain.OrganizationUseCase
+	inviteUseCase  domain.RegistrationInviteUseCase
+	magicLinks     domain.MagicLinkUseCase
+	privacyUseCase domain.PrivacyUseCase
+	revocations    domain.RevocationNotifier
 }
 
-func NewGRPCHandler(authUseCase domain.AuthUseCase) *GRPCHandler {
+func NewGRPCHandler(authUseCase domain.AuthUseCase, adminUseCase domain.AdminUseCase, auditUseCase domain.AuditUseCase, apiKeyUseCase domain.APIKeyUseCase, orgUseCase domain.OrganizationUseCase, inviteUseCase domain.RegistrationInviteUseCase, magicLinks domain.MagicLinkUseCase, privacyUseCase domain.PrivacyUseCase, revocations domain.RevocationNotifier) *GRPCHandler {
 	return &GRPCHandler{
-		authUseCase: authUseCase,
+		authUseCase:    authUseCase,
+		adminUseCase:   adminUseCase,
+		auditUseCase:   auditUseCase,
+		apiKeyUseCase:  apiKeyUseCase,
+		orgUseCase:     orgUseCase,
+		inviteUseCase:  inviteUseCase,
+		magicLinks:     magicLinks,
+		privacyUseCase: privacyUseCase,
+		revocations:    revocations,
 	}
 }
 
 func (h *GRPCHandler) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.AuthResponse, error) {
-	user, token, err := h.authUseCase.Register(req.Username, req.Email, req.Password)
+	user, token, err := h.authUseCase.Register(ctx, req.Username, req.Email, req.Password, req.InviteCode)
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(err)
 	}
 
 	return &pb.AuthResponse{
 		Token: token,
-		User: &pb.UserData{
-			Id:       user.ID,
-			Username: user.Username,
-			Email:    user.Email,
-		},
+		User:  toProtoUser(user),
 	}, nil
 }
 
 func (h *GRPCHandler) Login(ctx context.Context, req *pb.LoginRequest) (*pb.AuthResponse, error) {
-	user, token, err := h.authUseCase.Login(req.Username, req.Password)
+	user, token, err := h.authUseCase.Login(ctx, req.Username, req.Password)
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(err)
 	}
 
 	return &pb.AuthResponse{
 		Token: token,
-		User: &pb.UserData{
-			Id:       user.ID,
-			Username: user.Username,
-			Email:    user.Email,
-		},
+		User:  toProtoUser(user),
 	}, nil
 }
 
 func (h *GRPCHandler) Validate(ctx context.Context, req *pb.ValidateRequest) (*pb.ValidateResponse, error) {
-	user, err := h.authUseCase.ValidateToken(req.Token)
-	if err != nil {
+	if strings.HasPrefix(req.Token, domain.APIKeyPrefix) {
+		user, key, err := h.apiKeyUseCase.ValidateAPIKey(ctx, req.Token)
+		if err != nil {
+			return &pb.ValidateResponse{
+				Valid: false,
+				User:  nil,
+			}, nil
+		}
+
+		resp := &pb.ValidateResponse{
+			Valid:    true,
+			User:     toProtoUser(user),
+			AuthType: "api_key",
+			Scopes:   key.ScopeList(),
+		}
+		h.setOrganization(ctx, resp, user.ID, key.OrganizationID)
+
+		return resp, nil
+	}
+
+	user, claims, err := h.authUseCase.ValidateTokenClaims(ctx, req.Token)
+	// tokens issued to OAuth clients are not first-party sessions
+	if err != nil || claims.ClientID != "" {
 		return &pb.ValidateResponse{
 			Valid: false,
 			User:  nil,
 		}, nil
 	}
 
-	return &pb.ValidateResponse{
-		Valid: true,
-		User: &pb.UserData{
-			Id:       user.ID,
-			Username: user.Username,
-			Email:    user.Email,
-		},
-	}, nil
+	resp := &pb.ValidateResponse{
+		Valid:    true,
+		User:     toProtoUser(user),
+		AuthType: "jwt",
+		ActorId:  claims.ActorID,
+	}
+	h.setOrganization(ctx, resp, user.ID, claims.OrganizationID)
+
+	return resp, nil
+}
+
+// setOrganization adds the active organization, left empty when the user is
+// no longer a member so tenant data is not reachable
+func (h *GRPCHandler) setOrganization(ctx context.Context, resp *pb.ValidateResponse, userID, orgID uint64) {
+	membership, err := h.orgUseCase.ActiveMembership(ctx, userID, orgID)
+	if err != nil {
+		return
+	}
+
+	resp.OrganizationId = membership.OrganizationID
+	resp.OrganizationRole = membership.Role
 }
 
 func (h *GRPCHandler) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
-	err := h.authUseCase.Logout(req.Token)
+	err := h.authUseCase.Logout(ctx, req.Token)
 	if err != nil {
 		return &pb.LogoutResponse{
 			Success: false,
@@ -81,9 +131,138 @@ func (h *GRPCHandler) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.Lo
 	}, nil
 }
 
+func (h *GRPCHandler) RequestMagicLink(ctx context.Context, req *pb.RequestMagicLinkRequest) (*pb.RequestMagicLinkResponse, error) {
+	if err := h.magicLinks.RequestMagicLink(ctx, req.Email); err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &pb.RequestMagicLinkResponse{Success: true}, nil
+}
+
+func (h *GRPCHandler) ConsumeMagicLink(ctx context.Context, req *pb.ConsumeMagicLinkRequest) (*pb.AuthResponse, error) {
+	user, token, err := h.magicLinks.ConsumeMagicLink(ctx, req.Token)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &pb.AuthResponse{
+		Token: token,
+		User:  toProtoUser(user),
+	}, nil
+}
+
+// WatchRevocations streams revocations until the caller disconnects, callers
+// must drop their cache when the stream ends because events may be missed
+func (h *GRPCHandler) WatchRevocations(req *pb.WatchRevocationsRequest, stream pb.AuthService_WatchRevocationsServer) error {
+	events, unsubscribe := h.revocations.Subscribe()
+	defer unsubscribe()
+
+	// headers tell the caller that it is subscribed
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return status.Error(codes.Unavailable, "revocation watcher fell behind")
+			}
+			err := stream.Send(&pb.RevocationEvent{
+				TokenHash: event.TokenHash,
+				UserId:    event.UserID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (h *GRPCHandler) GetProfile(ctx context.Context, req *pb.GetProfileRequest) (*pb.UserData, error) {
+	user, err := h.authUseCase.GetProfile(ctx, req.Token)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toProtoUser(user), nil
+}
+
+func (h *GRPCHandler) UpdateProfile(ctx context.Context, req *pb.UpdateProfileRequest) (*pb.UserData, error) {
+	user, err := h.authUseCase.UpdateProfile(ctx, req.Token, domain.ProfileUpdate{
+		DisplayName: req.DisplayName,
+		AvatarURL:   req.AvatarUrl,
+		Locale:      req.Locale,
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toProtoUser(user), nil
+}
+
+func (h *GRPCHandler) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	err := h.authUseCase.ChangePassword(ctx, req.Token, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		return &pb.ChangePasswordResponse{Success: false}, toStatusError(err)
+	}
+
+	return &pb.ChangePasswordResponse{Success: true}, nil
+}
+
+func (h *GRPCHandler) ChangeEmail(ctx context.Context, req *pb.ChangeEmailRequest) (*pb.ChangeEmailResponse, error) {
+	err := h.authUseCase.ChangeEmail(ctx, req.Token, req.NewEmail, req.Password)
+	if err != nil {
+		return &pb.ChangeEmailResponse{Success: false}, toStatusError(err)
+	}
+
+	return &pb.ChangeEmailResponse{Success: true}, nil
+}
+
+func (h *GRPCHandler) ConfirmEmailChange(ctx context.Context, req *pb.ConfirmEmailChangeRequest) (*pb.UserData, error) {
+	user, err := h.authUseCase.ConfirmEmailChange(ctx, req.VerificationToken)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toProtoUser(user), nil
+}
+
+func (h *GRPCHandler) DeleteAccount(ctx context.Context, req *pb.DeleteAccountRequest) (*pb.DeleteAccountResponse, error) {
+	err := h.authUseCase.DeleteAccount(ctx, req.Token, req.Password)
+	if err != nil {
+		return &pb.DeleteAccountResponse{Success: false}, toStatusError(err)
+	}
+
+	return &pb.DeleteAccountResponse{Success: true}, nil
+}
+
 // serve start gRPC server
-func (h *GRPCHandler) Serve(address string) error {
-	server := NewGRPCServer(address)
+func (h *GRPCHandler) Serve(address string, verifier *serviceauth.Verifier, mode serviceauth.Mode, creds credentials.TransportCredentials) error {
+	server := NewGRPCServer(address, verifier, mode, creds)
 	server.RegisterGRPCServices(h)
 	return server.Start()
 }
+
+// helper func to convert domain User to proto UserData
+func toProtoUser(user *domain.User) *pb.UserData {
+	data := &pb.UserData{
+		Id:           user.ID,
+		Username:     user.Username,
+		Email:        user.Email,
+		DisplayName:  user.DisplayName,
+		AvatarUrl:    user.AvatarURL,
+		Locale:       user.Locale,
+		PendingEmail: user.PendingEmail,
+		CreatedAt:    user.CreatedAt.Format(time.RFC3339),
+		Role:         user.Role,
+		Disabled:     user.IsDisabled(),
+	}
+	if user.DeletedAt.Valid {
+		data.DeletedAt = user.DeletedAt.Time.Format(time.RFC3339)
+	}
+
+	return data
+}
@@ -0,0 +1,115 @@
+package grpc
+
+import (
+	"auth-service/internal/domain"
+	"context"
+	pb "grpc/pb/auth"
+	"time"
+)
+
+func (h *GRPCHandler) CreateOrganization(ctx context.Context, req *pb.CreateOrganizationRequest) (*pb.CreateOrganizationResponse, error) {
+	org, token, err := h.orgUseCase.CreateOrganization(ctx, req.Token, req.Name)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &pb.CreateOrganizationResponse{
+		Organization: &pb.Organization{
+			Id:        org.ID,
+			Name:      org.Name,
+			CreatedAt: org.CreatedAt.Format(time.RFC3339),
+			Role:      domain.OrgRoleOwner,
+		},
+		Token: token,
+	}, nil
+}
+
+func (h *GRPCHandler) ListOrganizations(ctx context.Context, req *pb.ListOrganizationsRequest) (*pb.ListOrganizationsResponse, error) {
+	memberships, err := h.orgUseCase.ListOrganizations(ctx, req.Token)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	orgs := make([]*pb.Organization, 0, len(memberships))
+	for _, membership := range memberships {
+		if membership.Organization == nil {
+			continue
+		}
+		orgs = append(orgs, &pb.Organization{
+			Id:        membership.Organization.ID,
+			Name:      membership.Organization.Name,
+			CreatedAt: membership.Organization.CreatedAt.Format(time.RFC3339),
+			Role:      membership.Role,
+		})
+	}
+
+	return &pb.ListOrganizationsResponse{Organizations: orgs}, nil
+}
+
+func (h *GRPCHandler) SwitchOrganization(ctx context.Context, req *pb.SwitchOrganizationRequest) (*pb.SwitchOrganizationResponse, error) {
+	token, err := h.orgUseCase.SwitchOrganization(ctx, req.Token, req.OrganizationId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &pb.SwitchOrganizationResponse{Token: token}, nil
+}
+
+func (h *GRPCHandler) ListMembers(ctx context.Context, req *pb.ListMembersRequest) (*pb.ListMembersResponse, error) {
+	memberships, err := h.orgUseCase.ListMembers(ctx, req.Token, req.OrganizationId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	members := make([]*pb.Member, len(memberships))
+	for i, membership := range memberships {
+		members[i] = toProtoMember(&membership)
+	}
+
+	return &pb.ListMembersResponse{Members: members}, nil
+}
+
+func (h *GRPCHandler) InviteMember(ctx context.Context, req *pb.InviteMemberRequest) (*pb.InviteMemberResponse, error) {
+	invitation, err := h.orgUseCase.InviteMember(ctx, req.Token, req.OrganizationId, req.Email, req.Role)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &pb.InviteMemberResponse{
+		Id:        invitation.ID,
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		ExpiresAt: invitation.ExpiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+func (h *GRPCHandler) AcceptInvitation(ctx context.Context, req *pb.AcceptInvitationRequest) (*pb.Member, error) {
+	membership, err := h.orgUseCase.AcceptInvitation(ctx, req.Token, req.InvitationToken)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toProtoMember(membership), nil
+}
+
+func (h *GRPCHandler) RemoveMember(ctx context.Context, req *pb.RemoveMemberRequest) (*pb.RemoveMemberResponse, error) {
+	if err := h.orgUseCase.RemoveMember(ctx, req.Token, req.OrganizationId, req.UserId); err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &pb.RemoveMemberResponse{Success: true}, nil
+}
+
+func toProtoMember(membership *domain.Membership) *pb.Member {
+	member := &pb.Member{
+		OrganizationId: membership.OrganizationID,
+		UserId:         membership.UserID,
+		Role:           membership.Role,
+		JoinedAt:       membership.CreatedAt.Format(time.RFC3339),
+	}
+	if membership.User != nil {
+		member.Username = membership.User.Username
+	}
+
+	return member
+}
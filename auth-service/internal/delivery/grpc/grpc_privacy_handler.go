@@ -0,0 +1,79 @@
+package grpc
+
+import (
+	"context"
+	pb "grpc/pb/auth"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// new user events are picked up from the database at this interval
+	userEventPollInterval = 2 * time.Second
+	userEventBatchSize    = 100
+)
+
+func (h *GRPCHandler) ExportMyData(ctx context.Context, req *pb.ExportMyDataRequest) (*pb.ExportMyDataResponse, error) {
+	archive, err := h.privacyUseCase.ExportMyData(ctx, req.Token)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &pb.ExportMyDataResponse{Archive: archive}, nil
+}
+
+func (h *GRPCHandler) EraseMyAccount(ctx context.Context, req *pb.EraseMyAccountRequest) (*pb.EraseMyAccountResponse, error) {
+	purgeAfter, err := h.privacyUseCase.EraseMyAccount(ctx, req.Token, req.Password)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &pb.EraseMyAccountResponse{
+		Success:    true,
+		PurgeAfter: purgeAfter.Format(time.RFC3339),
+	}, nil
+}
+
+// WatchUserEvents sends the stored events after req.AfterId and keeps
+// polling for new ones until the caller disconnects
+func (h *GRPCHandler) WatchUserEvents(req *pb.WatchUserEventsRequest, stream pb.AuthService_WatchUserEventsServer) error {
+	ctx := stream.Context()
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(userEventPollInterval)
+	defer ticker.Stop()
+
+	cursor := req.AfterId
+	for {
+		events, err := h.privacyUseCase.UserEvents(ctx, cursor, userEventBatchSize)
+		if err != nil {
+			return toStatusError(err)
+		}
+		for _, event := range events {
+			err := stream.Send(&pb.UserEvent{
+				Id:        event.ID,
+				Type:      event.Type,
+				UserId:    event.UserID,
+				CreatedAt: event.CreatedAt.Format(time.RFC3339),
+			})
+			if err != nil {
+				return err
+			}
+			cursor = event.ID
+		}
+
+		// a full batch means there may be more waiting
+		if len(events) == userEventBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package grpc
+
+import (
+	"auth-service/internal/domain"
+	"context"
+	pb "grpc/pb/auth"
+	"time"
+)
+
+func (h *GRPCHandler) CreateRegistrationInvite(ctx context.Context, req *pb.CreateRegistrationInviteRequest) (*pb.CreateRegistrationInviteResponse, error) {
+	invite, code, err := h.inviteUseCase.CreateInvite(ctx, req.Token, req.Role, int(req.MaxUses), int(req.ExpiresInDays))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &pb.CreateRegistrationInviteResponse{
+		Invite: toProtoRegistrationInvite(invite),
+		Code:   code,
+	}, nil
+}
+
+func (h *GRPCHandler) ListRegistrationInvites(ctx context.Context, req *pb.ListRegistrationInvitesRequest) (*pb.ListRegistrationInvitesResponse, error) {
+	invites, err := h.inviteUseCase.ListInvites(ctx, req.Token)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	protoInvites := make([]*pb.RegistrationInvite, len(invites))
+	for i, invite := range invites {
+		protoInvites[i] = toProtoRegistrationInvite(&invite)
+	}
+
+	return &pb.ListRegistrationInvitesResponse{Invites: protoInvites}, nil
+}
+
+func (h *GRPCHandler) RevokeRegistrationInvite(ctx context.Context, req *pb.RevokeRegistrationInviteRequest) (*pb.RevokeRegistrationInviteResponse, error) {
+	if err := h.inviteUseCase.RevokeInvite(ctx, req.Token, req.Id); err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &pb.RevokeRegistrationInviteResponse{Success: true}, nil
+}
+
+func toProtoRegistrationInvite(invite *domain.RegistrationInvite) *pb.RegistrationInvite {
+	data := &pb.RegistrationInvite{
+		Id:        invite.ID,
+		Prefix:    invite.Prefix,
+		Role:      invite.Role,
+		MaxUses:   int32(invite.MaxUses),
+		Uses:      int32(invite.Uses),
+		ExpiresAt: invite.ExpiresAt.Format(time.RFC3339),
+		CreatedBy: invite.CreatedBy,
+		CreatedAt: invite.CreatedAt.Format(time.RFC3339),
+	}
+	if invite.RevokedAt != nil {
+		data.RevokedAt = invite.RevokedAt.Format(time.RFC3339)
+	}
+
+	return data
+}
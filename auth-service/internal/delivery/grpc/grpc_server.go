@@ -6,8 +6,10 @@ import (
 	"net"
 
 	pb "grpc/pb/auth"
+	"grpc/serviceauth"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 type GRPCServer struct {
@@ -15,9 +17,22 @@ type GRPCServer struct {
 	server  *grpc.Server
 }
 
-func NewGRPCServer(address string) *GRPCServer {
-	// create new server
-	server := grpc.NewServer()
+// servicePolicy lets any service with the auth.rpc scope call every method,
+// end users are still authorized per call through their own token
+var servicePolicy = serviceauth.Policy{"*": serviceauth.ScopeAuthRPC}
+
+// NewGRPCServer uses plaintext when creds is nil, mtls.ServerCredentials otherwise
+func NewGRPCServer(address string, verifier *serviceauth.Verifier, mode serviceauth.Mode, creds credentials.TransportCredentials) *GRPCServer {
+	// create new server, callers must present a service token
+	serviceAuth := serviceauth.NewServerAuth(verifier, servicePolicy, mode)
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(serviceAuth.UnaryInterceptor(), requestMetaUnaryInterceptor),
+		grpc.ChainStreamInterceptor(serviceAuth.StreamInterceptor(), requestMetaStreamInterceptor),
+	}
+	if creds != nil {
+		opts = append(opts, grpc.Creds(creds))
+	}
+	server := grpc.NewServer(opts...)
 
 	return &GRPCServer{
 		address: address,
@@ -0,0 +1,68 @@
+package grpc
+
+import (
+	"auth-service/internal/domain"
+	"context"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// metadata keys set by the API gateway for the original client
+const (
+	mdForwardedFor       = "x-forwarded-for"
+	mdForwardedUserAgent = "x-forwarded-user-agent"
+	mdRequestID          = "x-request-id"
+)
+
+// requestMetaUnaryInterceptor attaches client information to the context
+// so use cases can record it in the audit log
+func requestMetaUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return handler(withRequestMeta(ctx), req)
+}
+
+func requestMetaStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &contextStream{ServerStream: ss, ctx: withRequestMeta(ss.Context())})
+}
+
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func withRequestMeta(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	meta := domain.RequestMeta{
+		IP:        strings.TrimSpace(strings.Split(first(mdForwardedFor), ",")[0]),
+		UserAgent: first(mdForwardedUserAgent),
+		RequestID: first(mdRequestID),
+	}
+
+	if meta.IP == "" {
+		if p, ok := peer.FromContext(ctx); ok {
+			host, _, err := net.SplitHostPort(p.Addr.String())
+			if err == nil {
+				meta.IP = host
+			}
+		}
+	}
+	if meta.UserAgent == "" {
+		meta.UserAgent = first("user-agent")
+	}
+
+	return domain.WithRequestMeta(ctx, meta)
+}
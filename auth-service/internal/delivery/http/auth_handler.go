@@ -2,6 +2,7 @@ package http
 
 import (
 	"auth-service/internal/domain"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -21,10 +22,13 @@ func NewAuthHandler(authUseCase domain.AuthUseCase) *AuthHandler {
 type registerRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=50"`
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required"`
+	// required while registration is invite-only
+	InviteCode string `json:"invite_code"`
 }
 
 type loginRequest struct {
+	// username or email, case is ignored
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
@@ -41,8 +45,18 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	user, token, err := h.authUseCase.Register(req.Username, req.Email, req.Password)
+	user, token, err := h.authUseCase.Register(c.Request.Context(), req.Username, req.Email, req.Password, req.InviteCode)
 	if err != nil {
+		var policyErr *domain.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "password does not meet policy", "violations": policyErr.Violations})
+			return
+		}
+		if errors.Is(err, domain.ErrRegistrationClosed) || errors.Is(err, domain.ErrInviteRequired) || errors.Is(err, domain.ErrInviteNotFound) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -64,7 +78,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	user, token, err := h.authUseCase.Login(req.Username, req.Password)
+	user, token, err := h.authUseCase.Login(c.Request.Context(), req.Username, req.Password)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -87,7 +101,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		return
 	}
 
-	err := h.authUseCase.Logout(token)
+	err := h.authUseCase.Logout(c.Request.Context(), token)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -103,7 +117,7 @@ func (h *AuthHandler) Me(c *gin.Context) {
 		return
 	}
 
-	user, err := h.authUseCase.ValidateToken(token)
+	user, err := h.authUseCase.ValidateToken(c.Request.Context(), token)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"auth-service/internal/domain"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OAuthHandler serves the RFC 7662 introspection and RFC 7009 revocation
+// endpoints for resource servers that can't use the gRPC Validate call
+type OAuthHandler struct {
+	oauthUseCase domain.OAuthUseCase
+}
+
+func NewOAuthHandler(oauthUseCase domain.OAuthUseCase) *OAuthHandler {
+	return &OAuthHandler{
+		oauthUseCase: oauthUseCase,
+	}
+}
+
+func (h *OAuthHandler) Introspect(c *gin.Context) {
+	client, ok := h.authenticateClient(c)
+	if !ok {
+		return
+	}
+
+	token := c.PostForm("token")
+	if token == "" {
+		oauthError(c, http.StatusBadRequest, "invalid_request", "missing token")
+		return
+	}
+
+	result, err := h.oauthUseCase.Introspect(c.Request.Context(), client, token)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *OAuthHandler) Revoke(c *gin.Context) {
+	client, ok := h.authenticateClient(c)
+	if !ok {
+		return
+	}
+
+	// token_type_hint is optional and we only have one token type
+	token := c.PostForm("token")
+	if token == "" {
+		oauthError(c, http.StatusBadRequest, "invalid_request", "missing token")
+		return
+	}
+
+	if err := h.oauthUseCase.Revoke(c.Request.Context(), client, token); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// authenticateClient accepts HTTP Basic auth or client_id/client_secret form
+// parameters, as allowed by RFC 6749 section 2.3.1
+func (h *OAuthHandler) authenticateClient(c *gin.Context) (*domain.OAuthClient, bool) {
+	clientID, clientSecret, ok := c.Request.BasicAuth()
+	if !ok {
+		clientID = c.PostForm("client_id")
+		clientSecret = c.PostForm("client_secret")
+	}
+
+	client, err := h.oauthUseCase.AuthenticateClient(c.Request.Context(), clientID, clientSecret)
+	if err != nil {
+		h.handleError(c, err)
+		return nil, false
+	}
+
+	return client, true
+}
+
+func (h *OAuthHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidClient):
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		oauthError(c, http.StatusUnauthorized, "invalid_client", err.Error())
+	case errors.Is(err, domain.ErrForbidden):
+		oauthError(c, http.StatusForbidden, "unauthorized_client", "client is not allowed to use this endpoint")
+	case errors.Is(err, domain.ErrRevocationUnavailable):
+		oauthError(c, http.StatusServiceUnavailable, "temporarily_unavailable", err.Error())
+	default:
+		oauthError(c, http.StatusInternalServerError, "server_error", err.Error())
+	}
+}
+
+func oauthError(c *gin.Context, status int, code, description string) {
+	c.JSON(status, gin.H{"error": code, "error_description": description})
+}
+
+// Routes
+func (h *OAuthHandler) RegisterRoutes(router *gin.Engine) {
+	oauth := router.Group("/oauth")
+	{
+		oauth.POST("/introspect", h.Introspect)
+		oauth.POST("/revoke", h.Revoke)
+	}
+}
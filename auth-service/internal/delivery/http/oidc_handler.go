@@ -0,0 +1,411 @@
+package http
+
+import (
+	"auth-service/internal/domain"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OIDCHandler makes auth-service an OpenID Connect provider using the
+// authorization code flow with PKCE, its token endpoint also serves the
+// client credentials grant for service accounts
+type OIDCHandler struct {
+	oidcUseCase    domain.OIDCUseCase
+	oauthUseCase   domain.OAuthUseCase
+	idTokenService domain.IDTokenService
+	issuer         string
+}
+
+func NewOIDCHandler(oidcUseCase domain.OIDCUseCase, oauthUseCase domain.OAuthUseCase, idTokenService domain.IDTokenService, issuer string) *OIDCHandler {
+	return &OIDCHandler{
+		oidcUseCase:    oidcUseCase,
+		oauthUseCase:   oauthUseCase,
+		idTokenService: idTokenService,
+		issuer:         strings.TrimRight(issuer, "/"),
+	}
+}
+
+type registerClientRequest struct {
+	Name         string   `json:"name" binding:"required"`
+	RedirectURIs []string `json:"redirect_uris" binding:"required"`
+	Public       bool     `json:"public"`
+}
+
+var authorizeTemplate = template.Must(template.New("authorize").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Sign in to {{.ClientName}}</title>
+</head>
+<body>
+<h1>Sign in to continue to {{.ClientName}}</h1>
+<p>{{.ClientName}} will be able to see:</p>
+<ul>
+{{range .Scopes}}<li>{{.}}</li>
+{{end}}</ul>
+{{if .Error}}<p style="color: red">{{.Error}}</p>{{end}}
+<form method="post" action="/oauth/authorize">
+<input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+<input type="hidden" name="response_type" value="{{.Request.ResponseType}}">
+<input type="hidden" name="client_id" value="{{.Request.ClientID}}">
+<input type="hidden" name="redirect_uri" value="{{.Request.RedirectURI}}">
+<input type="hidden" name="scope" value="{{.Request.Scope}}">
+<input type="hidden" name="state" value="{{.Request.State}}">
+<input type="hidden" name="nonce" value="{{.Request.Nonce}}">
+<input type="hidden" name="code_challenge" value="{{.Request.CodeChallenge}}">
+<input type="hidden" name="code_challenge_method" value="{{.Request.CodeChallengeMethod}}">
+<p><label>Username <input type="text" name="username" autocomplete="username" required></label></p>
+<p><label>Password <input type="password" name="password" autocomplete="current-password" required></label></p>
+<p>
+<button type="submit" name="consent" value="approve">Allow</button>
+<button type="submit" name="consent" value="deny" formnovalidate>Deny</button>
+</p>
+</form>
+</body>
+</html>
+`))
+
+var scopeDescriptions = map[string]string{
+	domain.ScopeOpenID:  "your user id",
+	domain.ScopeProfile: "your username, display name, avatar and locale",
+	domain.ScopeEmail:   "your email address",
+}
+
+type authorizePage struct {
+	ClientName string
+	Scopes     []string
+	Error      string
+	Request    domain.AuthorizationRequest
+	CSRFToken  string
+}
+
+// the login form is protected with a double submit cookie, a new token is
+// set every time the form is rendered
+const (
+	csrfCookieName = "oauth_csrf"
+	csrfCookieTTL  = 15 * 60 // seconds
+)
+
+func (h *OIDCHandler) Discovery(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"issuer":                                h.issuer,
+		"authorization_endpoint":                h.issuer + "/oauth/authorize",
+		"token_endpoint":                        h.issuer + "/oauth/token",
+		"userinfo_endpoint":                     h.issuer + "/oauth/userinfo",
+		"jwks_uri":                              h.issuer + "/oauth/jwks",
+		"introspection_endpoint":                h.issuer + "/oauth/introspect",
+		"revocation_endpoint":                   h.issuer + "/oauth/revoke",
+		"response_types_supported":              []string{"code"},
+		"grant_types_supported":                 []string{"authorization_code", "client_credentials"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"scopes_supported":                      []string{domain.ScopeOpenID, domain.ScopeProfile, domain.ScopeEmail},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"claims_supported": []string{
+			"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce",
+			"preferred_username", "name", "picture", "locale", "updated_at", "email",
+		},
+	})
+}
+
+func (h *OIDCHandler) JWKS(c *gin.Context) {
+	c.JSON(http.StatusOK, h.idTokenService.JWKS())
+}
+
+// AuthorizePage shows the login and consent form
+func (h *OIDCHandler) AuthorizePage(c *gin.Context) {
+	// keep the page out of frames to prevent clickjacking the consent
+	c.Header("X-Frame-Options", "DENY")
+	req := authorizationRequestFrom(c.Query)
+
+	client, err := h.oidcUseCase.ValidateAuthorizationRequest(c.Request.Context(), req)
+	if err != nil {
+		h.authorizeError(c, client, req, err)
+		return
+	}
+
+	h.renderAuthorizePage(c, http.StatusOK, client, req, "")
+}
+
+// Authorize handles the submitted login and consent form
+func (h *OIDCHandler) Authorize(c *gin.Context) {
+	c.Header("X-Frame-Options", "DENY")
+	req := authorizationRequestFrom(c.PostForm)
+
+	client, err := h.oidcUseCase.ValidateAuthorizationRequest(c.Request.Context(), req)
+	if err != nil {
+		h.authorizeError(c, client, req, err)
+		return
+	}
+
+	// a form posted from another site has no matching cookie
+	if !validCSRFToken(c) {
+		h.renderAuthorizePage(c, http.StatusForbidden, client, req, "The form has expired, please try again")
+		return
+	}
+
+	if c.PostForm("consent") != "approve" {
+		redirectWithParams(c, req.RedirectURI, url.Values{
+			"error":             {"access_denied"},
+			"error_description": {"the user denied the request"},
+			"state":             {req.State},
+		})
+		return
+	}
+
+	code, err := h.oidcUseCase.Authorize(c.Request.Context(), req, c.PostForm("username"), c.PostForm("password"))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCredentials) || errors.Is(err, domain.ErrAccountDisabled) {
+			h.renderAuthorizePage(c, http.StatusUnauthorized, client, req, err.Error())
+			return
+		}
+		h.authorizeError(c, client, req, err)
+		return
+	}
+
+	redirectWithParams(c, req.RedirectURI, url.Values{
+		"code":  {code},
+		"state": {req.State},
+	})
+}
+
+func (h *OIDCHandler) Token(c *gin.Context) {
+	clientID, clientSecret, ok := c.Request.BasicAuth()
+	if !ok {
+		clientID = c.PostForm("client_id")
+		clientSecret = c.PostForm("client_secret")
+	}
+
+	var resp *domain.TokenResponse
+	var err error
+	switch c.PostForm("grant_type") {
+	case "authorization_code":
+		resp, err = h.oidcUseCase.ExchangeCode(c.Request.Context(), domain.TokenExchange{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			Code:         c.PostForm("code"),
+			RedirectURI:  c.PostForm("redirect_uri"),
+			CodeVerifier: c.PostForm("code_verifier"),
+		})
+	case "client_credentials":
+		var client *domain.OAuthClient
+		client, err = h.oauthUseCase.AuthenticateClient(c.Request.Context(), clientID, clientSecret)
+		if err == nil {
+			resp, err = h.oauthUseCase.IssueServiceToken(c.Request.Context(), client, c.PostForm("scope"))
+		}
+	default:
+		oauthError(c, http.StatusBadRequest, "unsupported_grant_type", "only authorization_code and client_credentials are supported")
+		return
+	}
+	if err != nil {
+		var oauthErr *domain.OAuthError
+		switch {
+		case errors.As(err, &oauthErr):
+			oauthError(c, http.StatusBadRequest, oauthErr.Code, oauthErr.Description)
+		case errors.Is(err, domain.ErrInvalidClient):
+			c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+			oauthError(c, http.StatusUnauthorized, "invalid_client", err.Error())
+		default:
+			oauthError(c, http.StatusInternalServerError, "server_error", err.Error())
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *OIDCHandler) UserInfo(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.Header("WWW-Authenticate", `Bearer`)
+		oauthError(c, http.StatusUnauthorized, "invalid_token", "missing token")
+		return
+	}
+
+	info, err := h.oidcUseCase.UserInfo(c.Request.Context(), token)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrForbidden):
+			c.Header("WWW-Authenticate", `Bearer error="insufficient_scope", scope="openid"`)
+			oauthError(c, http.StatusForbidden, "insufficient_scope", "the openid scope is required")
+		case errors.Is(err, domain.ErrRevocationUnavailable):
+			oauthError(c, http.StatusServiceUnavailable, "temporarily_unavailable", err.Error())
+		default:
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			oauthError(c, http.StatusUnauthorized, "invalid_token", err.Error())
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, info)
+}
+
+// RegisterClient creates an OIDC client, only admins can call it
+func (h *OIDCHandler) RegisterClient(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		return
+	}
+
+	var req registerClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	client, secret, err := h.oidcUseCase.RegisterClient(c.Request.Context(), token, req.Name, req.RedirectURIs, req.Public)
+	if err != nil {
+		var validationErr *domain.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrInvalidToken):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	resp := gin.H{
+		"client_id":     client.ClientID,
+		"name":          client.Name,
+		"redirect_uris": strings.Fields(client.RedirectURIs),
+		"scope":         client.Scopes,
+		"public":        client.Public,
+	}
+	// the secret is not stored in plain text, this is the only time it is shown
+	if secret != "" {
+		resp["client_secret"] = secret
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// authorizeError redirects protocol errors back to the client when the
+// redirect URI is trusted, otherwise it shows them to the user
+func (h *OIDCHandler) authorizeError(c *gin.Context, client *domain.OAuthClient, req domain.AuthorizationRequest, err error) {
+	code, description := "server_error", err.Error()
+	var oauthErr *domain.OAuthError
+	if errors.As(err, &oauthErr) {
+		code, description = oauthErr.Code, oauthErr.Description
+	}
+
+	if client == nil {
+		c.String(http.StatusBadRequest, "%s: %s", code, description)
+		return
+	}
+
+	redirectWithParams(c, req.RedirectURI, url.Values{
+		"error":             {code},
+		"error_description": {description},
+		"state":             {req.State},
+	})
+}
+
+func (h *OIDCHandler) renderAuthorizePage(c *gin.Context, status int, client *domain.OAuthClient, req domain.AuthorizationRequest, errMessage string) {
+	var scopes []string
+	for _, scope := range strings.Fields(req.Scope) {
+		scopes = append(scopes, scopeDescriptions[scope])
+	}
+
+	csrfToken, err := newCSRFToken()
+	if err != nil {
+		c.String(http.StatusInternalServerError, "server_error: %s", err.Error())
+		return
+	}
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(csrfCookieName, csrfToken, csrfCookieTTL, "/oauth/authorize", "", strings.HasPrefix(h.issuer, "https://"), true)
+
+	c.Header("Cache-Control", "no-store")
+	c.Status(status)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+
+	err = authorizeTemplate.Execute(c.Writer, authorizePage{
+		ClientName: client.Name,
+		Scopes:     scopes,
+		Error:      errMessage,
+		Request:    req,
+		CSRFToken:  csrfToken,
+	})
+	if err != nil {
+		c.Error(err)
+	}
+}
+
+func newCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// validCSRFToken compares the posted token with the cookie set by the form
+func validCSRFToken(c *gin.Context) bool {
+	cookie, err := c.Cookie(csrfCookieName)
+	if err != nil || cookie == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookie), []byte(c.PostForm("csrf_token"))) == 1
+}
+
+func authorizationRequestFrom(get func(string) string) domain.AuthorizationRequest {
+	return domain.AuthorizationRequest{
+		ResponseType:        get("response_type"),
+		ClientID:            get("client_id"),
+		RedirectURI:         get("redirect_uri"),
+		Scope:               get("scope"),
+		State:               get("state"),
+		Nonce:               get("nonce"),
+		CodeChallenge:       get("code_challenge"),
+		CodeChallengeMethod: get("code_challenge_method"),
+	}
+}
+
+func redirectWithParams(c *gin.Context, redirectURI string, params url.Values) {
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		c.String(http.StatusBadRequest, "invalid redirect_uri")
+		return
+	}
+
+	query := target.Query()
+	for key, values := range params {
+		if len(values) > 0 && values[0] != "" {
+			query.Set(key, values[0])
+		}
+	}
+	target.RawQuery = query.Encode()
+
+	c.Redirect(http.StatusFound, target.String())
+}
+
+// Routes
+func (h *OIDCHandler) RegisterRoutes(router *gin.Engine) {
+	router.GET("/.well-known/openid-configuration", h.Discovery)
+
+	oauth := router.Group("/oauth")
+	{
+		oauth.GET("/jwks", h.JWKS)
+		oauth.GET("/authorize", h.AuthorizePage)
+		oauth.POST("/authorize", h.Authorize)
+		oauth.POST("/token", h.Token)
+		oauth.GET("/userinfo", h.UserInfo)
+		oauth.POST("/userinfo", h.UserInfo)
+		oauth.POST("/clients", h.RegisterClient)
+	}
+}
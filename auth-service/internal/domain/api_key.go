@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// APIKeyPrefix marks API keys so they can be told apart from JWTs
+const APIKeyPrefix = "ak_"
+
+// scopes that can be granted to API keys
+const (
+	ScopeProductsRead  = "products:read"
+	ScopeProductsWrite = "products:write"
+)
+
+var APIKeyScopes = []string{ScopeProductsRead, ScopeProductsWrite}
+
+// APIKey is a long-lived credential for automation, only the hash of the key
+// is stored and the plain key is shown once on creation
+type APIKey struct {
+	ID     uint64 `gorm:"primaryKey" json:"id"`
+	UserID uint64 `gorm:"index;not null" json:"user_id"`
+	// organization that was active when the key was created
+	OrganizationID uint64     `gorm:"index;not null;default:0" json:"organization_id"`
+	Name           string     `gorm:"size:100;not null" json:"name"`
+	Prefix         string     `gorm:"size:20;not null" json:"prefix"` // shown in listings to identify the key
+	KeyHash        string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	Scopes         string     `gorm:"size:500" json:"scopes"` // space separated
+	ExpiresAt      *time.Time `json:"expires_at"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+	LastUsedIP     string     `gorm:"size:45" json:"last_used_ip"`
+	RevokedAt      *time.Time `gorm:"index" json:"revoked_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func (k *APIKey) IsExpired() bool {
+	return k.ExpiresAt != nil && time.Now().After(*k.ExpiresAt)
+}
+
+func (k *APIKey) ScopeList() []string {
+	return strings.Fields(k.Scopes)
+}
+
+type APIKeyRepository interface {
+	Create(key *APIKey) error
+	FindByHash(keyHash string) (*APIKey, error)
+	// ListByUser returns the keys that are not revoked
+	ListByUser(userID uint64) ([]APIKey, error)
+	CountActiveByUser(userID uint64) (int64, error)
+	Revoke(userID, id uint64) error
+	TouchLastUsed(id uint64, usedAt time.Time, ip string) error
+}
+
+type APIKeyUseCase interface {
+	// CreateAPIKey returns the stored key and the plain key, which can't be
+	// recovered later
+	CreateAPIKey(ctx context.Context, token, name string, scopes []string, expiresInDays int) (*APIKey, string, error)
+	ListAPIKeys(ctx context.Context, token string) ([]APIKey, error)
+	RevokeAPIKey(ctx context.Context, token string, id uint64) error
+	ValidateAPIKey(ctx context.Context, key string) (*User, *APIKey, error)
+}
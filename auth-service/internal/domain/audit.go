@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// audit event types
+const (
+	AuditRegister           = "register"
+	AuditLoginSuccess       = "login_success"
+	AuditLoginFailure       = "login_failure"
+	AuditLogout             = "logout"
+	AuditPasswordChange     = "password_change"
+	AuditEmailChangeRequest = "email_change_requested"
+	AuditEmailChange        = "email_change"
+	AuditAccountDeleted     = "account_deleted"
+	AuditRoleChange         = "role_change"
+	AuditUserDisabled       = "user_disabled"
+	AuditUserEnabled        = "user_enabled"
+	AuditUserRestored       = "user_restored"
+	AuditTokenRevocation    = "token_revocation"
+	AuditOAuthAuthorize     = "oauth_authorize"
+	AuditOAuthClientCreated = "oauth_client_created"
+	AuditAPIKeyCreated      = "api_key_created"
+	AuditAPIKeyRevoked      = "api_key_revoked"
+	AuditOrgCreated         = "organization_created"
+	AuditOrgMemberInvited   = "organization_member_invited"
+	AuditOrgMemberJoined    = "organization_member_joined"
+	AuditOrgMemberRemoved   = "organization_member_removed"
+	AuditInviteCreated      = "registration_invite_created"
+	AuditInviteRevoked      = "registration_invite_revoked"
+	AuditMagicLinkRequested = "magic_link_requested"
+	AuditAccountErased      = "account_erased"
+	AuditDataExported       = "data_exported"
+	AuditImpersonation      = "impersonation_started"
+)
+
+// AuditEvent is an append-only record of a security relevant action
+type AuditEvent struct {
+	ID   uint64 `gorm:"primaryKey" json:"id"`
+	Type string `gorm:"size:50;index;not null" json:"type"`
+	// user the event is about, 0 when unknown (e.g. failed login)
+	UserID uint64 `gorm:"index" json:"user_id,omitempty"`
+	// user who performed the action when it differs from UserID
+	ActorID   uint64            `gorm:"index" json:"actor_id,omitempty"`
+	IP        string            `gorm:"size:45" json:"ip,omitempty"`
+	UserAgent string            `gorm:"size:500" json:"user_agent,omitempty"`
+	RequestID string            `gorm:"size:100;index" json:"request_id,omitempty"`
+	Metadata  map[string]string `gorm:"type:jsonb;serializer:json" json:"metadata,omitempty"`
+	CreatedAt time.Time         `gorm:"index" json:"created_at"`
+}
+
+type AuditFilter struct {
+	Page      int32
+	Limit     int32
+	UserID    uint64
+	ActorID   uint64
+	Type      string
+	IP        string
+	RequestID string
+	From      time.Time
+	To        time.Time
+}
+
+// AuditRepository has no update or delete on purpose, except for erasure
+type AuditRepository interface {
+	Create(event *AuditEvent) error
+	List(filter AuditFilter) ([]AuditEvent, int64, error)
+	// Each calls fn for every matching event in id order, ignoring paging
+	Each(filter AuditFilter, fn func(event *AuditEvent) error) error
+}
+
+// AuditLogger records events, filling request metadata from the context
+type AuditLogger interface {
+	Record(ctx context.Context, event AuditEvent)
+}
+
+type AuditUseCase interface {
+	ListEvents(ctx context.Context, token string, filter AuditFilter) ([]AuditEvent, int64, error)
+	ExportEvents(ctx context.Context, token string, filter AuditFilter, fn func(event *AuditEvent) error) error
+}
+
+// RequestMeta describes the client behind a request
+type RequestMeta struct {
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+type requestMetaKey struct{}
+
+func WithRequestMeta(ctx context.Context, meta RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, meta)
+}
+
+func RequestMetaFromContext(ctx context.Context) RequestMeta {
+	meta, _ := ctx.Value(requestMetaKey{}).(RequestMeta)
+	return meta
+}
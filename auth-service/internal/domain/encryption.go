@@ -0,0 +1,20 @@
+package domain
+
+// Keyring encrypts personal data at rest. Every value gets its own data
+// key, which is wrapped with the active key encryption key. Older keys are
+// kept so existing values can still be decrypted after a rotation
+type Keyring interface {
+	// Encrypt binds the ciphertext to associatedData (the column name), it
+	// must be passed again to Decrypt
+	Encrypt(plaintext, associatedData []byte) (string, error)
+	Decrypt(ciphertext string, associatedData []byte) ([]byte, error)
+	// IsEncrypted tells ciphertexts apart from values stored before
+	// encryption was enabled
+	IsEncrypted(value string) bool
+	// ActivePrefix starts every value encrypted with the active key, values
+	// without it need to be re-encrypted
+	ActivePrefix() string
+	// BlindIndex is a keyed hash for equality lookups on encrypted columns,
+	// its key is never rotated
+	BlindIndex(value string) string
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrRevocationUnavailable is returned when the revocation store cannot be
+	// reached and the service is configured to fail closed
+	ErrRevocationUnavailable = errors.New("token revocation status unavailable")
+
+	ErrInvalidToken        = errors.New("invalid token")
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrIncorrectPassword   = errors.New("current password is incorrect")
+	ErrUserNotFound        = errors.New("user not found")
+	ErrUsernameTaken       = errors.New("username already exist")
+	ErrEmailTaken          = errors.New("email already exist")
+	ErrInvalidVerification = errors.New("invalid or expired verification token")
+	ErrTokenRevoked        = errors.New("token has been revoked")
+	ErrAccountDisabled     = errors.New("account is disabled")
+	ErrForbidden           = errors.New("permission denied")
+	ErrInvalidRole         = errors.New("invalid role")
+	ErrSelfModification    = errors.New("admins cannot disable, demote or log out themselves")
+	ErrInvalidClient       = errors.New("invalid client credentials")
+	ErrClientNotFound      = errors.New("client not found")
+
+	ErrAuthorizationCodeNotFound = errors.New("authorization code is invalid, expired or already used")
+	ErrAPIKeyNotFound            = errors.New("api key not found")
+	ErrAPIKeyLimit               = errors.New("maximum number of api keys reached")
+	// also returned to non-members, so organization ids can't be probed
+	ErrOrganizationNotFound = errors.New("organization not found")
+	ErrInvitationNotFound   = errors.New("invitation is invalid, expired or already used")
+	ErrAlreadyMember        = errors.New("user is already a member of the organization")
+
+	ErrRegistrationClosed = errors.New("registration is closed")
+	ErrInviteRequired     = errors.New("an invite code is required to register")
+	ErrInviteNotFound     = errors.New("invite code is invalid, expired or used up")
+
+	ErrMagicLinkInvalid = errors.New("login link is invalid, expired or already used")
+	ErrRateLimited      = errors.New("too many requests, try again later")
+
+	ErrUserErased             = errors.New("user has been erased")
+	ErrOwnsSharedOrganization = errors.New("remove the other members of the organizations you own before erasing your account")
+
+	ErrImpersonationForbidden = errors.New("not allowed while impersonating a user")
+	ErrCannotImpersonate      = errors.New("admins, disabled users and yourself can't be impersonated")
+)
+
+// ValidationError reports an invalid input field
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// MagicLink is a single-use passwordless login, the link carries a signed
+// token and only the hash of its nonce is stored
+type MagicLink struct {
+	ID        uint64     `gorm:"primaryKey" json:"id"`
+	UserID    uint64     `gorm:"index;not null" json:"user_id"`
+	NonceHash string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type MagicLinkRepository interface {
+	Create(link *MagicLink) error
+	// Consume marks an unused, unexpired link as used and returns it, a link
+	// can only be consumed once
+	Consume(nonceHash string) (*MagicLink, error)
+}
+
+// LinkSigner signs the payload of emailed links so tampered or forged ones
+// are rejected before the database is queried
+type LinkSigner interface {
+	Sign(payload string) string
+	// Verify returns the payload of a token made by Sign
+	Verify(token string) (string, error)
+}
+
+type MagicLinkUseCase interface {
+	// RequestMagicLink mails a login link, it succeeds for unknown addresses
+	// too so it can't be used to find accounts
+	RequestMagicLink(ctx context.Context, email string) error
+	// ConsumeMagicLink logs the user in like Login
+	ConsumeMagicLink(ctx context.Context, token string) (*User, string, error)
+}
@@ -0,0 +1,12 @@
+package domain
+
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers transactional emails such as verification links
+type Mailer interface {
+	Send(mail Mail) error
+}
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// scopes a client needs to call the token endpoints
+const (
+	ScopeIntrospect = "introspect"
+	// revokes tokens issued to the client itself
+	ScopeRevoke = "revoke"
+	// revokes any token, for resource servers that receive session tokens
+	ScopeRevokeAny = "revoke_any"
+)
+
+// OpenID Connect scopes
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+)
+
+// OAuthClient is a registered third-party application, the secret is only
+// stored as a hash made by the PasswordHasher
+type OAuthClient struct {
+	ID         uint64 `gorm:"primaryKey" json:"id"`
+	ClientID   string `gorm:"size:100;uniqueIndex;not null" json:"client_id"`
+	SecretHash string `gorm:"size:255" json:"-"`
+	Name       string `gorm:"size:100" json:"name"`
+	Scopes     string `gorm:"size:500" json:"scopes"` // space separated
+	// exact redirect URIs allowed for the authorization code flow
+	RedirectURIs string `gorm:"size:2000" json:"redirect_uris"` // space separated
+	// public clients (SPAs, mobile apps) have no secret and rely on PKCE
+	Public bool `gorm:"not null;default:false" json:"public"`
+	// service accounts use the client credentials grant to call other services
+	ServiceAccount bool      `gorm:"not null;default:false" json:"service_account"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (c *OAuthClient) HasScope(scope string) bool {
+	for _, s := range strings.Fields(c.Scopes) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *OAuthClient) HasRedirectURI(uri string) bool {
+	for _, u := range strings.Fields(c.RedirectURIs) {
+		if u == uri {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenIntrospection is the RFC 7662 view of a token, only Active is set
+// for tokens that are expired, revoked or unknown
+type TokenIntrospection struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	Jti       string `json:"jti,omitempty"`
+}
+
+type OAuthClientRepository interface {
+	Create(client *OAuthClient) error
+	FindByClientID(clientID string) (*OAuthClient, error)
+	Update(client *OAuthClient) error
+}
+
+type OAuthUseCase interface {
+	AuthenticateClient(ctx context.Context, clientID, clientSecret string) (*OAuthClient, error)
+	Introspect(ctx context.Context, client *OAuthClient, token string) (*TokenIntrospection, error)
+	Revoke(ctx context.Context, client *OAuthClient, token string) error
+	// IssueServiceToken implements the client credentials grant for service accounts
+	IssueServiceToken(ctx context.Context, client *OAuthClient, scope string) (*TokenResponse, error)
+}
+
+// ServiceTokenService signs the short-lived tokens services present to each
+// other, they are verified with the published JWKS
+type ServiceTokenService interface {
+	GenerateServiceToken(clientID, scope string) (string, time.Duration, error)
+}
@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// AuthorizationCode is a single-use code issued by /oauth/authorize and
+// redeemed at /oauth/token, only its hash is stored
+type AuthorizationCode struct {
+	ID                  uint64    `gorm:"primaryKey"`
+	CodeHash            string    `gorm:"size:64;uniqueIndex;not null"`
+	ClientID            string    `gorm:"size:100;index;not null"`
+	UserID              uint64    `gorm:"index;not null"`
+	RedirectURI         string    `gorm:"size:500;not null"`
+	Scope               string    `gorm:"size:500"`
+	Nonce               string    `gorm:"size:255"`
+	CodeChallenge       string    `gorm:"size:128;not null"`
+	CodeChallengeMethod string    `gorm:"size:10;not null"`
+	ExpiresAt           time.Time `gorm:"not null"`
+	UsedAt              *time.Time
+	CreatedAt           time.Time // doubles as auth_time in the ID token
+}
+
+// AuthorizationRequest holds the query parameters of /oauth/authorize
+type AuthorizationRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+// TokenExchange holds the parameters of an authorization_code grant
+type TokenExchange struct {
+	ClientID     string
+	ClientSecret string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+}
+
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	IDToken     string `json:"id_token,omitempty"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+// IDTokenRequest describes the OpenID Connect ID token to issue
+type IDTokenRequest struct {
+	User     *User
+	ClientID string
+	Scope    string
+	Nonce    string
+	AuthTime time.Time
+}
+
+// UserInfo is the response of /oauth/userinfo, claims are filled per scope
+type UserInfo struct {
+	Sub               string `json:"sub"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Name              string `json:"name,omitempty"`
+	Picture           string `json:"picture,omitempty"`
+	Locale            string `json:"locale,omitempty"`
+	UpdatedAt         int64  `json:"updated_at,omitempty"`
+	Email             string `json:"email,omitempty"`
+}
+
+// JSONWebKey is the public part of a signing key, published on the JWKS endpoint
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// OAuthError carries an RFC 6749 error code, it is rendered as is to clients
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Description)
+}
+
+type AuthorizationCodeRepository interface {
+	Create(code *AuthorizationCode) error
+	// Consume marks an unused, unexpired code as used and returns it
+	Consume(codeHash string) (*AuthorizationCode, error)
+}
+
+type IDTokenService interface {
+	GenerateIDToken(req IDTokenRequest) (string, error)
+	JWKS() JSONWebKeySet
+}
+
+type OIDCUseCase interface {
+	// RegisterClient creates an OIDC client, the returned secret is only shown once
+	RegisterClient(ctx context.Context, token, name string, redirectURIs []string, public bool) (*OAuthClient, string, error)
+	ValidateAuthorizationRequest(ctx context.Context, req AuthorizationRequest) (*OAuthClient, error)
+	// Authorize logs the user in and returns the authorization code
+	Authorize(ctx context.Context, req AuthorizationRequest, username, password string) (string, error)
+	ExchangeCode(ctx context.Context, req TokenExchange) (*TokenResponse, error)
+	UserInfo(ctx context.Context, token string) (*UserInfo, error)
+}
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// roles inside an organization, independent of the global user role
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+// Organization is a tenant, products and API keys belong to one
+type Organization struct {
+	ID        uint64    `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"size:100;not null" json:"name"`
+	CreatedBy uint64    `gorm:"index;not null" json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Membership struct {
+	ID             uint64        `gorm:"primaryKey" json:"id"`
+	OrganizationID uint64        `gorm:"uniqueIndex:idx_membership_org_user;not null" json:"organization_id"`
+	UserID         uint64        `gorm:"uniqueIndex:idx_membership_org_user;index;not null" json:"user_id"`
+	Role           string        `gorm:"size:20;not null" json:"role"`
+	CreatedAt      time.Time     `json:"created_at"`
+	Organization   *Organization `gorm:"foreignKey:OrganizationID" json:"organization,omitempty"`
+	User           *User         `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// CanManageMembers is true for owners and admins
+func (m *Membership) CanManageMembers() bool {
+	return m.Role == OrgRoleOwner || m.Role == OrgRoleAdmin
+}
+
+// Invitation lets someone join an organization, it is bound to an email
+// address and only the hash of the token is stored
+type Invitation struct {
+	ID             uint64     `gorm:"primaryKey" json:"id"`
+	OrganizationID uint64     `gorm:"index;not null" json:"organization_id"`
+	Email          string     `gorm:"size:100;not null" json:"email"`
+	Role           string     `gorm:"size:20;not null" json:"role"`
+	TokenHash      string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	InvitedBy      uint64     `gorm:"not null" json:"invited_by"`
+	ExpiresAt      time.Time  `gorm:"not null" json:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type OrganizationRepository interface {
+	// Create stores the organization together with its owner membership
+	Create(org *Organization, ownerID uint64) error
+	FindMembership(orgID, userID uint64) (*Membership, error)
+	// ListMemberships returns the memberships of a user with their organization,
+	// oldest first
+	ListMemberships(userID uint64) ([]Membership, error)
+	// ListMembers returns the memberships of an organization with their user
+	ListMembers(orgID uint64) ([]Membership, error)
+	AddMember(membership *Membership) error
+	RemoveMember(orgID, userID uint64) error
+
+	CreateInvitation(invitation *Invitation) error
+	FindInvitationByHash(tokenHash string) (*Invitation, error)
+	// AcceptInvitation marks the invitation as used and adds the membership
+	// in one transaction
+	AcceptInvitation(invitation *Invitation, membership *Membership) error
+}
+
+type OrganizationUseCase interface {
+	// CreateOrganization makes the caller the owner and returns a token with
+	// the new organization active
+	CreateOrganization(ctx context.Context, token, name string) (*Organization, string, error)
+	ListOrganizations(ctx context.Context, token string) ([]Membership, error)
+	// SwitchOrganization returns a new token with another active organization
+	SwitchOrganization(ctx context.Context, token string, orgID uint64) (string, error)
+	ListMembers(ctx context.Context, token string, orgID uint64) ([]Membership, error)
+	// InviteMember mails an invitation link to the address
+	InviteMember(ctx context.Context, token string, orgID uint64, email, role string) (*Invitation, error)
+	AcceptInvitation(ctx context.Context, token, invitationToken string) (*Membership, error)
+	// RemoveMember is allowed to owners and admins, and to members leaving
+	RemoveMember(ctx context.Context, token string, orgID, userID uint64) error
+
+	// ActiveMembership checks that the user still belongs to the organization
+	// a token or API key was issued for
+	ActiveMembership(ctx context.Context, userID, orgID uint64) (*Membership, error)
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "strings"
+
+// PasswordViolation describes a single password rule that was not met
+type PasswordViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError is returned when a password doesn't satisfy the policy
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Message
+	}
+
+	return "password does not meet policy: " + strings.Join(messages, "; ")
+}
+
+type PasswordPolicy interface {
+	Validate(password, username, email string) []PasswordViolation
+}
+
+// BreachedPasswordChecker looks a password up in a list of known breached
+// passwords without the password itself leaving the process
+type BreachedPasswordChecker interface {
+	IsBreached(password string) (bool, error)
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// user event types, consumed by other services through WatchUserEvents
+const (
+	// personal data of the user was erased, references to the user must be
+	// anonymized
+	UserEventErased = "user_erased"
+)
+
+// UserEvent is stored before it is delivered, consumers resume from the last
+// id they processed so no event is lost while they are down
+type UserEvent struct {
+	ID        uint64    `gorm:"primaryKey" json:"id"`
+	Type      string    `gorm:"size:50;not null" json:"type"`
+	UserID    uint64    `gorm:"index;not null" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UserEventRepository interface {
+	// ListAfter returns up to limit events with an id above afterID, oldest
+	// first
+	ListAfter(afterID uint64, limit int) ([]UserEvent, error)
+}
+
+// DataExport is the archive returned by ExportMyData
+type DataExport struct {
+	ExportedAt    time.Time    `json:"exported_at"`
+	Account       *User        `json:"account"`
+	Organizations []Membership `json:"organizations"`
+	// access tokens are not stored, API keys are the long-lived credentials
+	APIKeys     []APIKey     `json:"api_keys"`
+	AuditEvents []AuditEvent `json:"audit_events"`
+	// products created by the user, as returned by product-service
+	Products json.RawMessage `json:"products"`
+}
+
+// ProductExporter fetches the products a user created from product-service,
+// the call is made on behalf of the user with their token
+type ProductExporter interface {
+	ExportProducts(ctx context.Context, token string) (json.RawMessage, error)
+}
+
+type PrivacyUseCase interface {
+	// ExportMyData returns a JSON archive of the token owner's personal data
+	ExportMyData(ctx context.Context, token string) ([]byte, error)
+	// EraseMyAccount anonymizes the account right away and returns when the
+	// remaining records are purged
+	EraseMyAccount(ctx context.Context, token, password string) (time.Time, error)
+	// PurgeErased removes accounts erased before the grace period, it returns
+	// how many were purged
+	PurgeErased(ctx context.Context) (int, error)
+	// UserEvents returns events after afterID for WatchUserEvents
+	UserEvents(ctx context.Context, afterID uint64, limit int) ([]UserEvent, error)
+}
@@ -0,0 +1,12 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// RateLimiter counts attempts per key in fixed windows
+type RateLimiter interface {
+	// Allow records an attempt and reports whether it is within limit
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, error)
+}
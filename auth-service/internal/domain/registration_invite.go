@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// who can call Register, configured with REGISTRATION_MODE
+const (
+	RegistrationOpen       = "open"
+	RegistrationInviteOnly = "invite_only"
+	RegistrationClosed     = "closed"
+)
+
+// RegistrationInvite is a code created by an admin that lets people sign up
+// while registration is invite-only, only the hash of the code is stored
+type RegistrationInvite struct {
+	ID       uint64 `gorm:"primaryKey" json:"id"`
+	Prefix   string `gorm:"size:20;not null" json:"prefix"` // shown in listings to identify the code
+	CodeHash string `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	// role given to users registering with the code
+	Role      string     `gorm:"size:20;not null" json:"role"`
+	MaxUses   int        `gorm:"not null" json:"max_uses"`
+	Uses      int        `gorm:"not null;default:0" json:"uses"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt *time.Time `gorm:"index" json:"revoked_at"`
+	CreatedBy uint64     `gorm:"not null" json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type RegistrationInviteRepository interface {
+	Create(invite *RegistrationInvite) error
+	// FindUsableByHash only returns invites that are not revoked, expired or
+	// used up
+	FindUsableByHash(codeHash string) (*RegistrationInvite, error)
+	// List returns every invite, newest first
+	List() ([]RegistrationInvite, error)
+	Revoke(id uint64) error
+	// Consume takes one use, it fails when the invite became unusable in the
+	// meantime
+	Consume(id uint64) error
+	// Release gives back a use taken by a registration that failed
+	Release(id uint64) error
+}
+
+type RegistrationInviteUseCase interface {
+	// CreateInvite returns the stored invite and the plain code, which can't
+	// be recovered later
+	CreateInvite(ctx context.Context, token, role string, maxUses, expiresInDays int) (*RegistrationInvite, string, error)
+	ListInvites(ctx context.Context, token string) ([]RegistrationInvite, error)
+	RevokeInvite(ctx context.Context, token string, id uint64) error
+}
@@ -1,40 +1,209 @@
 package domain
 
 import (
+	"context"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// User keeps username and email as entered, lookups and the unique indexes
+// on lower(username) and email_index ignore case. Email is encrypted at
+// rest, EmailIndex is its blind index
 type User struct {
-	ID        uint64         `gorm:"primaryKey" json:"id"`
-	Username  string         `gorm:"uniqueIndex;size:100;not null" json:"username"`
-	Email     string         `gorm:"uniqueIndex;size:100;not null" json:"email"`
-	Password  string         `gorm:"size:100;not null" json:"-"`
+	ID          uint64 `gorm:"primaryKey" json:"id"`
+	Username    string `gorm:"uniqueIndex;size:100;not null" json:"username"`
+	Email       string `gorm:"type:text;not null;serializer:pii" json:"email"`
+	EmailIndex  string `gorm:"size:64" json:"-"`
+	Password    string `gorm:"size:255;not null" json:"-"`
+	DisplayName string `gorm:"size:100" json:"display_name"`
+	AvatarURL   string `gorm:"size:500" json:"avatar_url"`
+	Locale      string `gorm:"size:35" json:"locale"`
+	Role        string `gorm:"size:20;not null;default:user" json:"role"`
+
+	// set by admins, disabled users can't log in or use their tokens
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	// tokens issued before this time are rejected (force logout)
+	TokensValidAfter *time.Time `json:"-"`
+
+	// email change waiting for verification
+	PendingEmail         string     `gorm:"type:text;serializer:pii" json:"-"`
+	EmailChangeTokenHash string     `gorm:"size:64;index" json:"-"`
+	EmailChangeExpiresAt *time.Time `json:"-"`
+
+	// set when the user asked to be forgotten, the account is anonymized and
+	// purged after a grace period
+	ErasedAt *time.Time `gorm:"index" json:"-"`
+
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// NormalizeIdentity is the form usernames and emails are compared in
+func NormalizeIdentity(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// IdentityCollision is a group of users whose username or email only differ
+// in case, they block the case-insensitive unique index until resolved
+type IdentityCollision struct {
+	Field   string // "username" or "email"
+	UserIDs []uint64
+}
+
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+func (u *User) IsDisabled() bool {
+	return u.DisabledAt != nil
+}
+
+// UserStatus filters users in admin listings
+type UserStatus string
+
+const (
+	UserStatusAll      UserStatus = ""
+	UserStatusActive   UserStatus = "active"
+	UserStatusDisabled UserStatus = "disabled"
+	UserStatusDeleted  UserStatus = "deleted"
+)
+
+type UserFilter struct {
+	Page   int32
+	Limit  int32
+	Search string // matches part of the username or the whole email
+	Role   string
+	Status UserStatus
+}
+
+// ProfileUpdate holds the profile fields to change, nil means unchanged
+type ProfileUpdate struct {
+	DisplayName *string
+	AvatarURL   *string
+	Locale      *string
+}
+
 type UserRepository interface {
 	Create(user *User) error
 	FindByID(id uint64) (*User, error)
+	FindByIDUnscoped(id uint64) (*User, error) // includes soft-deleted users
+	// FindByUsername and FindByEmail ignore case, an exact match wins while
+	// older accounts still collide
 	FindByUsername(username string) (*User, error)
 	FindByEmail(email string) (*User, error)
+	FindByEmailChangeToken(tokenHash string) (*User, error)
 	Update(user *User) error
 	Delete(id uint64) error
+	Restore(id uint64) error
+	List(filter UserFilter) ([]User, int64, error)
+	// Erase anonymizes and soft-deletes the user, removes their credentials
+	// and memberships and stores a UserEventErased, in one transaction
+	Erase(user *User) error
+	// PurgeErased hard-deletes users erased before the given time and
+	// anonymizes their audit events in one transaction, it returns their ids
+	PurgeErased(before time.Time) ([]uint64, error)
 }
 
 type AuthUseCase interface {
-	Register(username, email, password string) (*User, string, error)
-	Login(username, password string) (*User, string, error)
-	ValidateToken(token string) (*User, error)
-	Logout(token string) error
+	// Register needs a valid invite code while registration is invite-only
+	Register(ctx context.Context, username, email, password, inviteCode string) (*User, string, error)
+	// Login and Authenticate accept a username or an email address
+	Login(ctx context.Context, login, password string) (*User, string, error)
+	Authenticate(ctx context.Context, login, password string) (*User, error)
+	ValidateToken(ctx context.Context, token string) (*User, error)
+	ValidateTokenClaims(ctx context.Context, token string) (*User, *TokenClaims, error)
+	Logout(ctx context.Context, token string) error
+
+	GetProfile(ctx context.Context, token string) (*User, error)
+	UpdateProfile(ctx context.Context, token string, update ProfileUpdate) (*User, error)
+	ChangePassword(ctx context.Context, token, currentPassword, newPassword string) error
+	ChangeEmail(ctx context.Context, token, newEmail, password string) error
+	ConfirmEmailChange(ctx context.Context, verificationToken string) (*User, error)
+	DeleteAccount(ctx context.Context, token, password string) error
+}
+
+// AdminUseCase holds operator actions, every call requires an admin token
+type AdminUseCase interface {
+	ListUsers(ctx context.Context, token string, filter UserFilter) ([]User, int64, error)
+	GetUser(ctx context.Context, token string, id uint64) (*User, error)
+	SetUserDisabled(ctx context.Context, token string, id uint64, disabled bool) (*User, error)
+	SetUserRole(ctx context.Context, token string, id uint64, role string) (*User, error)
+	ForceLogout(ctx context.Context, token string, id uint64) error
+	RestoreUser(ctx context.Context, token string, id uint64) (*User, error)
+	// Impersonate issues a short-lived token for another user that names the
+	// admin as its actor, it returns the token and when it expires
+	Impersonate(ctx context.Context, token string, id uint64, reason string, ttl time.Duration) (string, time.Time, error)
+}
+
+// TokenRequest describes the access token to issue
+type TokenRequest struct {
+	UserID uint64
+	Role   string
+	Scope  string // space separated, empty for full user sessions
+	// OAuth client the token was issued to, empty for first-party logins
+	ClientID string
+	// active organization, products are scoped to it
+	OrganizationID uint64
+	// admin acting as the user, 0 unless impersonating
+	ActorID uint64
+	// lifetime of the token, 0 uses the default
+	ExpiresIn time.Duration
+}
+
+// TokenClaims is the verified content of an access token
+type TokenClaims struct {
+	ID       string // jti, used as the revocation key
+	UserID   uint64
+	Role     string
+	Scope    string
+	ClientID string
+	// active organization, 0 when none
+	OrganizationID uint64
+	// admin acting as the user (act claim), 0 unless impersonating
+	ActorID   uint64
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+func (c *TokenClaims) IsImpersonation() bool {
+	return c.ActorID != 0
 }
 
 type TokenService interface {
-	GenerateToken(userID uint64) (string, error)
-	ValidateToken(token string) (uint64, error)
+	GenerateToken(req TokenRequest) (string, error)
+	ValidateToken(token string) (*TokenClaims, error)
 	BlacklistToken(token string) error
-	IsTokenBlacklisted(token string) bool
+	IsTokenBlacklisted(claims *TokenClaims) (bool, error)
+}
+
+// RevocationEvent tells services that cache Validate results what to drop.
+// TokenHash is the sha256 hex of one token, UserID covers all of a user's
+// tokens and API keys
+type RevocationEvent struct {
+	TokenHash string `json:"token_hash,omitempty"`
+	UserID    uint64 `json:"user_id,omitempty"`
+}
+
+// RevocationNotifier fans revocations out to every auth-service instance
+// and their watchers
+type RevocationNotifier interface {
+	Publish(event RevocationEvent)
+	// Subscribe returns the events and a function to stop receiving them
+	Subscribe() (<-chan RevocationEvent, func())
+}
+
+// PasswordHasher hashes passwords into self-describing encoded strings, so
+// the algorithm and its parameters can change without breaking old hashes
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(password, encodedHash string) (bool, error)
+	NeedsRehash(encodedHash string) bool
 }
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"auth-service/internal/domain"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) domain.APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(key *domain.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *apiKeyRepository) FindByHash(keyHash string) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := r.db.Where("key_hash = ? AND revoked_at IS NULL", keyHash).First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (r *apiKeyRepository) ListByUser(userID uint64) ([]domain.APIKey, error) {
+	var keys []domain.APIKey
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL", userID).Order("id DESC").Find(&keys).Error
+
+	return keys, err
+}
+
+func (r *apiKeyRepository) CountActiveByUser(userID uint64) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.APIKey{}).
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userID, time.Now()).
+		Count(&count).Error
+
+	return count, err
+}
+
+func (r *apiKeyRepository) Revoke(userID, id uint64) error {
+	result := r.db.Model(&domain.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepository) TouchLastUsed(id uint64, usedAt time.Time, ip string) error {
+	return r.db.Model(&domain.APIKey{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_used_at": usedAt,
+		"last_used_ip": ip,
+	}).Error
+}
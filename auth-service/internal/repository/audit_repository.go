@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"auth-service/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+const auditBatchSize = 500
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) domain.AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Create(event *domain.AuditEvent) error {
+	return r.db.Create(event).Error
+}
+
+func (r *auditRepository) List(filter domain.AuditFilter) ([]domain.AuditEvent, int64, error) {
+	var events []domain.AuditEvent
+	var total int64
+
+	query := r.filtered(filter)
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// calculate offset
+	offset := (filter.Page - 1) * filter.Limit
+
+	err = query.Order("id DESC").Offset(int(offset)).Limit(int(filter.Limit)).Find(&events).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
+
+func (r *auditRepository) Each(filter domain.AuditFilter, fn func(event *domain.AuditEvent) error) error {
+	var batch []domain.AuditEvent
+
+	return r.filtered(filter).Order("id").FindInBatches(&batch, auditBatchSize, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			if err := fn(&batch[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+func (r *auditRepository) filtered(filter domain.AuditFilter) *gorm.DB {
+	query := r.db.Model(&domain.AuditEvent{})
+
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.IP != "" {
+		query = query.Where("ip = ?", filter.IP)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+
+	return query
+}
+
+// anonymizeAuditEvents clears ip, user agent and metadata of events about
+// or by the users, the events themselves are kept
+func anonymizeAuditEvents(tx *gorm.DB, userIDs []uint64) error {
+	return tx.Model(&domain.AuditEvent{}).
+		Where("user_id IN ? OR actor_id IN ?", userIDs, userIDs).
+		Updates(map[string]interface{}{"ip": "", "user_agent": "", "metadata": nil}).Error
+}
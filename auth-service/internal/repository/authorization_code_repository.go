@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"auth-service/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type authorizationCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewAuthorizationCodeRepository(db *gorm.DB) domain.AuthorizationCodeRepository {
+	return &authorizationCodeRepository{db: db}
+}
+
+func (r *authorizationCodeRepository) Create(code *domain.AuthorizationCode) error {
+	return r.db.Create(code).Error
+}
+
+// Consume flags the code as used in the same statement that checks it, so a
+// code replayed concurrently is only accepted once
+func (r *authorizationCodeRepository) Consume(codeHash string) (*domain.AuthorizationCode, error) {
+	var code domain.AuthorizationCode
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&domain.AuthorizationCode{}).
+			Where("code_hash = ? AND used_at IS NULL AND expires_at > ?", codeHash, now).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrAuthorizationCodeNotFound
+		}
+
+		return tx.Where("code_hash = ?", codeHash).First(&code).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &code, nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"auth-service/internal/domain"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// identityIndexes are the case-insensitive unique indexes, email is
+// encrypted so its index is on the blind index of the normalized address
+var identityIndexes = []struct {
+	field string
+	name  string
+	expr  string
+}{
+	{field: "username", name: "idx_users_lower_username", expr: "lower(username)"},
+	{field: "email", name: "idx_users_email_index", expr: "email_index"},
+}
+
+// indexes on the email column, useless once it holds ciphertexts
+var obsoleteIdentityIndexes = []string{"idx_users_email", "idx_users_lower_email"}
+
+// MigrateIdentityIndexes adds case-insensitive unique indexes on username
+// and email. A column whose existing values collide when case is ignored
+// doesn't get the index, the collisions are returned so they can be
+// resolved by hand and the index is added on the next start
+func MigrateIdentityIndexes(db *gorm.DB) ([]domain.IdentityCollision, error) {
+	for _, name := range obsoleteIdentityIndexes {
+		if err := db.Exec("DROP INDEX IF EXISTS " + name).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var collisions []domain.IdentityCollision
+	for _, index := range identityIndexes {
+		found, err := findIdentityCollisions(db, index.field, index.expr)
+		if err != nil {
+			return nil, err
+		}
+		if len(found) > 0 {
+			collisions = append(collisions, found...)
+			continue
+		}
+
+		// soft-deleted users are included, restoring one must not collide
+		sql := fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS %s ON users (%s)", index.name, index.expr)
+		if err := db.Exec(sql).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return collisions, nil
+}
+
+func findIdentityCollisions(db *gorm.DB, field, expr string) ([]domain.IdentityCollision, error) {
+	// only the ids are returned, they end up in the log
+	var rows []struct {
+		UserIDs string
+	}
+	err := db.Unscoped().Model(&domain.User{}).
+		Select("string_agg(id::text, ',' ORDER BY id) AS user_ids").
+		Where(expr + " <> ''").
+		Group(expr).
+		Having("count(*) > 1").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	collisions := make([]domain.IdentityCollision, len(rows))
+	for i, row := range rows {
+		collisions[i] = domain.IdentityCollision{Field: field}
+		for _, id := range strings.Split(row.UserIDs, ",") {
+			userID, err := strconv.ParseUint(id, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			collisions[i].UserIDs = append(collisions[i].UserIDs, userID)
+		}
+	}
+
+	return collisions, nil
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"auth-service/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type magicLinkRepository struct {
+	db *gorm.DB
+}
+
+func NewMagicLinkRepository(db *gorm.DB) domain.MagicLinkRepository {
+	return &magicLinkRepository{db: db}
+}
+
+func (r *magicLinkRepository) Create(link *domain.MagicLink) error {
+	return r.db.Create(link).Error
+}
+
+func (r *magicLinkRepository) Consume(nonceHash string) (*domain.MagicLink, error) {
+	var link domain.MagicLink
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// the used_at check makes a second consume fail
+		result := tx.Model(&domain.MagicLink{}).
+			Where("nonce_hash = ? AND used_at IS NULL AND expires_at > ?", nonceHash, time.Now()).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrMagicLinkInvalid
+		}
+
+		return tx.Where("nonce_hash = ?", nonceHash).First(&link).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &link, nil
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"auth-service/internal/domain"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type oauthClientRepository struct {
+	db *gorm.DB
+}
+
+func NewOAuthClientRepository(db *gorm.DB) domain.OAuthClientRepository {
+	return &oauthClientRepository{db: db}
+}
+
+func (r *oauthClientRepository) Create(client *domain.OAuthClient) error {
+	return r.db.Create(client).Error
+}
+
+func (r *oauthClientRepository) FindByClientID(clientID string) (*domain.OAuthClient, error) {
+	var client domain.OAuthClient
+	err := r.db.Where("client_id = ?", clientID).First(&client).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrClientNotFound
+		}
+		return nil, err
+	}
+
+	return &client, nil
+}
+
+func (r *oauthClientRepository) Update(client *domain.OAuthClient) error {
+	return r.db.Save(client).Error
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"auth-service/internal/domain"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type organizationRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) domain.OrganizationRepository {
+	return &organizationRepository{db: db}
+}
+
+func (r *organizationRepository) Create(org *domain.Organization, ownerID uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(org).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&domain.Membership{
+			OrganizationID: org.ID,
+			UserID:         ownerID,
+			Role:           domain.OrgRoleOwner,
+		}).Error
+	})
+}
+
+func (r *organizationRepository) FindMembership(orgID, userID uint64) (*domain.Membership, error) {
+	var membership domain.Membership
+	err := r.db.Preload("Organization").
+		Where("organization_id = ? AND user_id = ?", orgID, userID).
+		First(&membership).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+
+	return &membership, nil
+}
+
+func (r *organizationRepository) ListMemberships(userID uint64) ([]domain.Membership, error) {
+	var memberships []domain.Membership
+	err := r.db.Preload("Organization").Where("user_id = ?", userID).Order("id ASC").Find(&memberships).Error
+
+	return memberships, err
+}
+
+func (r *organizationRepository) ListMembers(orgID uint64) ([]domain.Membership, error) {
+	var memberships []domain.Membership
+	err := r.db.Preload("User").Where("organization_id = ?", orgID).Order("id ASC").Find(&memberships).Error
+
+	return memberships, err
+}
+
+func (r *organizationRepository) AddMember(membership *domain.Membership) error {
+	return r.db.Create(membership).Error
+}
+
+func (r *organizationRepository) RemoveMember(orgID, userID uint64) error {
+	result := r.db.Where("organization_id = ? AND user_id = ?", orgID, userID).Delete(&domain.Membership{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *organizationRepository) CreateInvitation(invitation *domain.Invitation) error {
+	return r.db.Create(invitation).Error
+}
+
+func (r *organizationRepository) FindInvitationByHash(tokenHash string) (*domain.Invitation, error) {
+	var invitation domain.Invitation
+	err := r.db.Where("token_hash = ? AND accepted_at IS NULL AND expires_at > ?", tokenHash, time.Now()).
+		First(&invitation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrInvitationNotFound
+		}
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+func (r *organizationRepository) AcceptInvitation(invitation *domain.Invitation, membership *domain.Membership) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// the accepted_at check makes a second accept fail
+		result := tx.Model(&domain.Invitation{}).
+			Where("id = ? AND accepted_at IS NULL", invitation.ID).
+			Update("accepted_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrInvitationNotFound
+		}
+
+		return tx.Create(membership).Error
+	})
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"auth-service/internal/domain"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const piiBatchSize = 100
+
+// BackfillEmailIndex computes the blind index of users that don't have one
+// yet, i.e. users stored before email was encrypted. It has to finish before
+// MigrateIdentityIndexes and before users are looked up by email
+func BackfillEmailIndex(db *gorm.DB, keyring domain.Keyring) (int, error) {
+	updated := 0
+	for {
+		var users []domain.User
+		err := db.Unscoped().Where("email_index IS NULL OR email_index = ''").
+			Order("id").Limit(piiBatchSize).Find(&users).Error
+		if err != nil {
+			return updated, err
+		}
+		if len(users) == 0 {
+			return updated, nil
+		}
+
+		for _, user := range users {
+			err := db.Unscoped().Model(&domain.User{}).Where("id = ?", user.ID).
+				UpdateColumn("email_index", emailIndex(keyring, user.Email)).Error
+			if err != nil {
+				return updated, err
+			}
+		}
+		updated += len(users)
+	}
+}
+
+// ReencryptUsers rewrites personal data that is still plaintext or was
+// encrypted with an older key, it returns the number of users rewritten.
+// Safe to run on several instances, a row rewritten twice stays valid
+func ReencryptUsers(db *gorm.DB, keyring domain.Keyring) (int, error) {
+	pattern := escapeLike(keyring.ActivePrefix()) + "%"
+
+	updated := 0
+	var lastID uint64
+	for {
+		var users []domain.User
+		err := db.Unscoped().
+			Where("id > ?", lastID).
+			Where("email NOT LIKE ? OR (pending_email <> '' AND pending_email NOT LIKE ?)", pattern, pattern).
+			Order("id").Limit(piiBatchSize).Find(&users).Error
+		if err != nil {
+			return updated, err
+		}
+		if len(users) == 0 {
+			return updated, nil
+		}
+
+		for i := range users {
+			// writing the decrypted values back encrypts them with the active key
+			err := db.Unscoped().Model(&users[i]).
+				Select("email", "pending_email").
+				UpdateColumns(&users[i]).Error
+			if err != nil {
+				return updated, err
+			}
+			lastID = users[i].ID
+		}
+		updated += len(users)
+	}
+}
+
+// emailIndex is the blind index of the normalized email, so lookups keep
+// ignoring case
+func emailIndex(keyring domain.Keyring, email string) string {
+	return keyring.BlindIndex(domain.NormalizeIdentity(email))
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"auth-service/internal/domain"
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+// piiSerializer encrypts string fields tagged `serializer:pii`, the column
+// name is bound to the ciphertext. Values written before encryption was
+// enabled are read as they are until ReencryptUsers rewrites them
+type piiSerializer struct {
+	keyring domain.Keyring
+}
+
+// RegisterPIISerializer has to run before the first query, gorm rejects
+// models that use an unknown serializer
+func RegisterPIISerializer(keyring domain.Keyring) {
+	schema.RegisterSerializer("pii", piiSerializer{keyring: keyring})
+}
+
+func (s piiSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var stored string
+	switch v := dbValue.(type) {
+	case nil:
+	case string:
+		stored = v
+	case []byte:
+		stored = string(v)
+	default:
+		return fmt.Errorf("unsupported value %T in %s", dbValue, field.DBName)
+	}
+
+	value := stored
+	if s.keyring.IsEncrypted(stored) {
+		plaintext, err := s.keyring.Decrypt(stored, []byte(field.DBName))
+		if err != nil {
+			return fmt.Errorf("decrypt %s: %w", field.DBName, err)
+		}
+		value = string(plaintext)
+	}
+
+	field.ReflectValueOf(ctx, dst).SetString(value)
+	return nil
+}
+
+// Value keeps empty strings as they are, so they can still be told apart
+func (s piiSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	value, _ := fieldValue.(string)
+	if value == "" {
+		return "", nil
+	}
+
+	return s.keyring.Encrypt([]byte(value), []byte(field.DBName))
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"auth-service/internal/domain"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type registrationInviteRepository struct {
+	db *gorm.DB
+}
+
+func NewRegistrationInviteRepository(db *gorm.DB) domain.RegistrationInviteRepository {
+	return &registrationInviteRepository{db: db}
+}
+
+func (r *registrationInviteRepository) Create(invite *domain.RegistrationInvite) error {
+	return r.db.Create(invite).Error
+}
+
+func (r *registrationInviteRepository) FindUsableByHash(codeHash string) (*domain.RegistrationInvite, error) {
+	var invite domain.RegistrationInvite
+	err := r.db.Where("code_hash = ? AND revoked_at IS NULL AND expires_at > ? AND uses < max_uses", codeHash, time.Now()).
+		First(&invite).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrInviteNotFound
+		}
+		return nil, err
+	}
+
+	return &invite, nil
+}
+
+func (r *registrationInviteRepository) List() ([]domain.RegistrationInvite, error) {
+	var invites []domain.RegistrationInvite
+	err := r.db.Order("id DESC").Find(&invites).Error
+
+	return invites, err
+}
+
+func (r *registrationInviteRepository) Revoke(id uint64) error {
+	result := r.db.Model(&domain.RegistrationInvite{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrInviteNotFound
+	}
+
+	return nil
+}
+
+func (r *registrationInviteRepository) Consume(id uint64) error {
+	// the conditions are checked in the update so concurrent registrations
+	// can't use more than max_uses
+	result := r.db.Model(&domain.RegistrationInvite{}).
+		Where("id = ? AND revoked_at IS NULL AND expires_at > ? AND uses < max_uses", id, time.Now()).
+		Update("uses", gorm.Expr("uses + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrInviteNotFound
+	}
+
+	return nil
+}
+
+func (r *registrationInviteRepository) Release(id uint64) error {
+	return r.db.Model(&domain.RegistrationInvite{}).
+		Where("id = ? AND uses > 0", id).
+		Update("uses", gorm.Expr("uses - 1")).Error
+}
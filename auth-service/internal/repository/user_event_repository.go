@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"auth-service/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type userEventRepository struct {
+	db *gorm.DB
+}
+
+func NewUserEventRepository(db *gorm.DB) domain.UserEventRepository {
+	return &userEventRepository{db: db}
+}
+
+func (r *userEventRepository) ListAfter(afterID uint64, limit int) ([]domain.UserEvent, error) {
+	var events []domain.UserEvent
+	err := r.db.Where("id > ?", afterID).Order("id ASC").Limit(limit).Find(&events).Error
+
+	return events, err
+}
@@ -3,19 +3,26 @@ package repository
 import (
 	"auth-service/internal/domain"
 	"errors"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// email is encrypted by the pii serializer, the repository keeps its blind
+// index up to date
 type userRepository struct {
-	db *gorm.DB
+	db      *gorm.DB
+	keyring domain.Keyring
 }
 
-func NewUserRepository(db *gorm.DB) domain.UserRepository {
-	return &userRepository{db: db}
+func NewUserRepository(db *gorm.DB, keyring domain.Keyring) domain.UserRepository {
+	return &userRepository{db: db, keyring: keyring}
 }
 
 func (r *userRepository) Create(user *domain.User) error {
+	user.EmailIndex = emailIndex(r.keyring, user.Email)
 	return r.db.Create(user).Error
 }
 
@@ -24,7 +31,20 @@ func (r *userRepository) FindByID(id uint64) (*domain.User, error) {
 	err := r.db.First(&user, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return &user, err
+}
+
+func (r *userRepository) FindByIDUnscoped(id uint64) (*domain.User, error) {
+	var user domain.User
+	err := r.db.Unscoped().First(&user, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -34,10 +54,12 @@ func (r *userRepository) FindByID(id uint64) (*domain.User, error) {
 
 func (r *userRepository) FindByUsername(username string) (*domain.User, error) {
 	var user domain.User
-	err := r.db.Where("username = ?", username).First(&user).Error
+	err := r.db.Where("lower(username) = ?", domain.NormalizeIdentity(username)).
+		Clauses(exactMatchFirst("username", username)).
+		Take(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, domain.ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -46,11 +68,31 @@ func (r *userRepository) FindByUsername(username string) (*domain.User, error) {
 }
 
 func (r *userRepository) FindByEmail(email string) (*domain.User, error) {
+	var users []domain.User
+	err := r.db.Where("email_index = ?", emailIndex(r.keyring, email)).Order("id").Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, domain.ErrUserNotFound
+	}
+
+	// the ciphertext can't be compared in SQL, pick the exact match here
+	for i := range users {
+		if users[i].Email == strings.TrimSpace(email) {
+			return &users[i], nil
+		}
+	}
+
+	return &users[0], nil
+}
+
+func (r *userRepository) FindByEmailChangeToken(tokenHash string) (*domain.User, error) {
 	var user domain.User
-	err := r.db.Where("email = ?", email).First(&user).Error
+	err := r.db.Where("email_change_token_hash = ?", tokenHash).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, domain.ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -59,9 +101,104 @@ func (r *userRepository) FindByEmail(email string) (*domain.User, error) {
 }
 
 func (r *userRepository) Update(user *domain.User) error {
+	user.EmailIndex = emailIndex(r.keyring, user.Email)
 	return r.db.Save(user).Error
 }
 
 func (r *userRepository) Delete(id uint64) error {
 	return r.db.Delete(&domain.User{}, id).Error
 }
+
+func (r *userRepository) Restore(id uint64) error {
+	return r.db.Unscoped().Model(&domain.User{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+func (r *userRepository) List(filter domain.UserFilter) ([]domain.User, int64, error) {
+	var users []domain.User
+	var total int64
+
+	query := r.db.Model(&domain.User{})
+
+	switch filter.Status {
+	case domain.UserStatusActive:
+		query = query.Where("disabled_at IS NULL")
+	case domain.UserStatusDisabled:
+		query = query.Where("disabled_at IS NOT NULL")
+	case domain.UserStatusDeleted:
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+
+	if filter.Search != "" {
+		query = query.Where("username ILIKE ? OR email_index = ?", "%"+filter.Search+"%", emailIndex(r.keyring, filter.Search))
+	}
+
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// calculate offset
+	offset := (filter.Page - 1) * filter.Limit
+
+	err = query.Order("id").Offset(int(offset)).Limit(int(filter.Limit)).Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// exactMatchFirst orders a case-insensitive lookup so an exact match is
+// picked over accounts that only differ in case
+func exactMatchFirst(column, value string) clause.OrderBy {
+	return clause.OrderBy{Expression: clause.Expr{
+		SQL:                column + " = ? DESC, id",
+		Vars:               []interface{}{value},
+		WithoutParentheses: true,
+	}}
+}
+
+func (r *userRepository) Erase(user *domain.User) error {
+	user.EmailIndex = emailIndex(r.keyring, user.Email)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(user).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&domain.User{}, user.ID).Error; err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{&domain.APIKey{}, &domain.Membership{}, &domain.MagicLink{}} {
+			if err := tx.Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&domain.UserEvent{Type: domain.UserEventErased, UserID: user.ID}).Error
+	})
+}
+
+func (r *userRepository) PurgeErased(before time.Time) ([]uint64, error) {
+	var ids []uint64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&domain.User{}).Where("erased_at < ?", before).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		// in the same transaction, the ids are gone once the users are
+		if err := anonymizeAuditEvents(tx, ids); err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&domain.User{}).Error
+	})
+
+	return ids, err
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"auth-service/internal/domain"
+	"context"
+	"log"
+	"strings"
+	"unicode/utf8"
+)
+
+// longest metadata value stored, longer ones are cut
+const maxAuditMetadataLength = 500
+
+type auditLogger struct {
+	auditRepo domain.AuditRepository
+}
+
+func NewAuditLogger(auditRepo domain.AuditRepository) domain.AuditLogger {
+	return &auditLogger{auditRepo: auditRepo}
+}
+
+// Record never fails the calling action, errors are only logged
+func (l *auditLogger) Record(ctx context.Context, event domain.AuditEvent) {
+	meta := domain.RequestMetaFromContext(ctx)
+	event.IP = truncate(meta.IP, 45)
+	event.UserAgent = truncate(meta.UserAgent, 500)
+	event.RequestID = truncate(meta.RequestID, 100)
+	if event.Metadata != nil {
+		metadata := make(map[string]string, len(event.Metadata))
+		for key, value := range event.Metadata {
+			metadata[truncate(key, maxAuditMetadataLength)] = truncate(value, maxAuditMetadataLength)
+		}
+		event.Metadata = metadata
+	}
+
+	if err := l.auditRepo.Create(&event); err != nil {
+		log.Printf("Failed to record audit event %s for user %d: %v", event.Type, event.UserID, err)
+	}
+}
+
+// truncate cuts s to at most max bytes on a character boundary. Clients
+// control most of these values, invalid UTF-8 and NUL bytes are dropped
+// since Postgres rejects them and the event would be lost
+func truncate(s string, max int) string {
+	s = strings.ToValidUTF8(strings.ReplaceAll(s, "\x00", ""), "")
+	if len(s) <= max {
+		return s
+	}
+
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		max  int
+		want string
+	}{
+		{"short", "curl/8.0", 500, "curl/8.0"},
+		{"exact", "abc", 3, "abc"},
+		{"ascii", "abcdef", 3, "abc"},
+		{"inside a two byte character", "aé", 2, "a"},
+		{"after a two byte character", "aéb", 3, "aé"},
+		{"inside a four byte character", "ab😀", 5, "ab"},
+		{"invalid utf-8", "a\xffb\xc3", 500, "ab"},
+		{"nul bytes", "a\x00b", 500, "ab"},
+		{"invalid utf-8 before the cut", "\xff\xfeabc", 2, "ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncate(tt.s, tt.max)
+			if got != tt.want {
+				t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncate(%q, %d) = %q, not valid UTF-8", tt.s, tt.max, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"auth-service/internal/domain"
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const hashPrefixLength = 5
+
+// breachedPasswordList is a local copy of a breached password corpus in the
+// Have I Been Pwned format: one upper-case SHA-1 hash per line, optionally
+// followed by ":count". Hashes are bucketed by their 5 character prefix the
+// same way the k-anonymity range API works, so a remote range client can be
+// swapped in later without changing callers.
+type breachedPasswordList struct {
+	ranges map[string]map[string]struct{}
+}
+
+func NewBreachedPasswordList(path string) (domain.BreachedPasswordChecker, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %v", err)
+	}
+	defer file.Close()
+
+	list := &breachedPasswordList{ranges: make(map[string]map[string]struct{})}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		hash, _, _ := strings.Cut(line, ":")
+		hash = strings.ToUpper(hash)
+		if len(hash) != sha1.Size*2 {
+			continue
+		}
+
+		prefix, suffix := hash[:hashPrefixLength], hash[hashPrefixLength:]
+		if list.ranges[prefix] == nil {
+			list.ranges[prefix] = make(map[string]struct{})
+		}
+		list.ranges[prefix][suffix] = struct{}{}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breached password list: %v", err)
+	}
+
+	return list, nil
+}
+
+func (l *breachedPasswordList) IsBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	suffixes, ok := l.ranges[hash[:hashPrefixLength]]
+	if !ok {
+		return false, nil
+	}
+
+	_, breached := suffixes[hash[hashPrefixLength:]]
+	return breached, nil
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"auth-service/internal/domain"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileMailer writes every email as an .eml file into an outbox directory,
+// for local development and manual testing of emailed links
+type fileMailer struct {
+	dir string
+}
+
+func NewFileMailer(dir string) (domain.Mailer, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	return &fileMailer{dir: dir}, nil
+}
+
+func (m *fileMailer) Send(mail domain.Mail) error {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000"), hex.EncodeToString(suffix))
+	content := fmt.Sprintf("Date: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n",
+		now.Format(time.RFC1123Z), mail.To, mail.Subject, mail.Body)
+
+	// links in the body are credentials, keep the files private
+	return os.WriteFile(filepath.Join(m.dir, name), []byte(content), 0o600)
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"auth-service/internal/domain"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const idTokenDuration = time.Hour
+
+// idTokenService signs OpenID Connect ID tokens with RS256, they must be
+// verifiable by clients using the published JWKS
+type idTokenService struct {
+	issuer string
+	key    *rsa.PrivateKey
+	keyID  string
+}
+
+func NewIDTokenService(issuer string, key *rsa.PrivateKey) domain.IDTokenService {
+	return &idTokenService{
+		issuer: issuer,
+		key:    key,
+		keyID:  rsaKeyID(&key.PublicKey),
+	}
+}
+
+func (s *idTokenService) GenerateIDToken(req domain.IDTokenRequest) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":       s.issuer,
+		"sub":       strconv.FormatUint(req.User.ID, 10),
+		"aud":       req.ClientID,
+		"azp":       req.ClientID,
+		"iat":       now.Unix(),
+		"exp":       now.Add(idTokenDuration).Unix(),
+		"auth_time": req.AuthTime.Unix(),
+	}
+	if req.Nonce != "" {
+		claims["nonce"] = req.Nonce
+	}
+
+	scopes := strings.Fields(req.Scope)
+	for _, scope := range scopes {
+		switch scope {
+		case domain.ScopeProfile:
+			claims["preferred_username"] = req.User.Username
+			claims["updated_at"] = req.User.UpdatedAt.Unix()
+			if req.User.DisplayName != "" {
+				claims["name"] = req.User.DisplayName
+			}
+			if req.User.AvatarURL != "" {
+				claims["picture"] = req.User.AvatarURL
+			}
+			if req.User.Locale != "" {
+				claims["locale"] = req.User.Locale
+			}
+		case domain.ScopeEmail:
+			claims["email"] = req.User.Email
+		}
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = s.keyID
+
+	return token.SignedString(s.key)
+}
+
+func (s *idTokenService) JWKS() domain.JSONWebKeySet {
+	pub := s.key.PublicKey
+
+	return domain.JSONWebKeySet{
+		Keys: []domain.JSONWebKey{{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: "RS256",
+			Kid: s.keyID,
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	}
+}
+
+// LoadRSAPrivateKey reads a PEM encoded PKCS#1 or PKCS#8 RSA key
+func LoadRSAPrivateKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %v", err)
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+
+	return key, nil
+}
+
+// GenerateRSAPrivateKey creates a throwaway signing key, ID tokens signed with
+// it become unverifiable after a restart
+func GenerateRSAPrivateKey() (*rsa.PrivateKey, error) {
+	return rsa.GenerateKey(rand.Reader, 2048)
+}
+
+// rsaKeyID derives a stable kid from the public key
+func rsaKeyID(pub *rsa.PublicKey) string {
+	sum := sha256.Sum256(x509.MarshalPKCS1PublicKey(pub))
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
@@ -3,45 +3,160 @@ package service
 import (
 	"auth-service/internal/domain"
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"expvar"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/redis/go-redis/v9"
 )
 
+// RevocationFailMode decides what happens when the revocation store (Redis)
+// cannot be reached while checking a token
+type RevocationFailMode string
+
+const (
+	// RevocationFailOpen accepts tokens that are not in the local cache
+	RevocationFailOpen RevocationFailMode = "open"
+	// RevocationFailClosed rejects tokens that are not in the local cache
+	RevocationFailClosed RevocationFailMode = "closed"
+)
+
+const (
+	revokedKeyPrefix = "revoked:jti:"
+	// revocations stored by earlier versions, keyed by the whole token
+	legacyRevokedKeyPrefix = "blacklist:"
+	revocationChannel      = "auth:token-revoked"
+	redisTimeout           = 2 * time.Second
+	cachePruneInterval     = time.Minute
+	// token_use of access tokens, keeps service and ID tokens signed with the
+	// same key from being accepted as one
+	accessTokenUse = "access"
+)
+
+// degraded mode metrics, exposed on /debug/vars
+var (
+	revocationDegraded          = expvar.NewInt("auth_revocation_degraded")
+	revocationRedisErrors       = expvar.NewInt("auth_revocation_redis_errors_total")
+	revocationDegradedChecks    = expvar.NewInt("auth_revocation_degraded_checks_total")
+	revocationFailClosedRejects = expvar.NewInt("auth_revocation_fail_closed_rejects_total")
+)
+
 type jwtTokenService struct {
-	secretKey     string
+	issuer string
+	key    *rsa.PrivateKey
+	keyID  string
+	// HS256 tokens issued before the switch to RS256, accepted for one token
+	// lifetime after start
+	legacySecret  []byte
+	legacyUntil   time.Time
+	startedAt     time.Time
 	redisClient   *redis.Client
 	tokenDuration time.Duration
+	failMode      RevocationFailMode
+	revocations   *revocationCache
+	degraded      atomic.Bool
 }
 
 type Claims struct {
 	UserID uint64 `json:"user_id"`
+	Role   string `json:"role,omitempty"`
+	Scope  string `json:"scope,omitempty"`
+	// always "access", missing on legacy HS256 tokens
+	TokenUse string `json:"token_use,omitempty"`
+	// client_id as in RFC 9068, set for tokens issued through OAuth
+	ClientID       string `json:"client_id,omitempty"`
+	OrganizationID uint64 `json:"org_id,omitempty"`
+	// act as in RFC 8693, the admin impersonating the user
+	Act *ActorClaim `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func NewJwtTokenService(secretKey string, redisClient *redis.Client) domain.TokenService {
-	return &jwtTokenService{
-		secretKey:     secretKey,
+type ActorClaim struct {
+	Sub string `json:"sub"`
+}
+
+// revocationEvent is published to other instances when a token is revoked
+type revocationEvent struct {
+	JTI       string `json:"jti"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// NewJwtTokenService signs access tokens with key, the key published on the
+// JWKS endpoint, so other services can verify them without a shared secret.
+// legacySecret is the HS256 secret of earlier versions, empty rejects their
+// tokens right away
+func NewJwtTokenService(issuer string, key *rsa.PrivateKey, legacySecret string, redisClient *redis.Client, failMode RevocationFailMode) domain.TokenService {
+	now := time.Now()
+	s := &jwtTokenService{
+		issuer:        issuer,
+		key:           key,
+		keyID:         rsaKeyID(&key.PublicKey),
+		startedAt:     now,
 		redisClient:   redisClient,
 		tokenDuration: 24 * time.Hour, // token 24 jam
+		failMode:      failMode,
+		revocations:   newRevocationCache(),
+	}
+	if legacySecret != "" {
+		s.legacySecret = []byte(legacySecret)
+		s.legacyUntil = now.Add(s.tokenDuration)
+	}
+
+	if redisClient != nil {
+		go s.syncRevocations()
+		// the first pass finishes before any token is checked
+		s.migrateLegacyOnce()
+		go s.migrateLegacyRevocations()
 	}
+	go s.pruneRevocations()
+
+	return s
 }
 
-func (s *jwtTokenService) GenerateToken(userID uint64) (string, error) {
+func (s *jwtTokenService) GenerateToken(req domain.TokenRequest) (string, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	duration := s.tokenDuration
+	if req.ExpiresIn > 0 {
+		duration = req.ExpiresIn
+	}
+
 	claims := Claims{
-		UserID: userID,
+		UserID:         req.UserID,
+		Role:           req.Role,
+		Scope:          req.Scope,
+		ClientID:       req.ClientID,
+		OrganizationID: req.OrganizationID,
+		TokenUse:       accessTokenUse,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.tokenDuration)),
+			ID:        jti,
+			Issuer:    s.issuer,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
+	if req.ActorID != 0 {
+		claims.Act = &ActorClaim{Sub: strconv.FormatUint(req.ActorID, 10)}
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = s.keyID
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(s.secretKey))
+	signedToken, err := token.SignedString(s.key)
 	if err != nil {
 		return "", err
 	}
@@ -49,59 +164,275 @@ func (s *jwtTokenService) GenerateToken(userID uint64) (string, error) {
 	return signedToken, nil
 }
 
-func (s *jwtTokenService) ValidateToken(tokenString string) (uint64, error) {
+func (s *jwtTokenService) ValidateToken(tokenString string) (*domain.TokenClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
+		switch token.Method {
+		case jwt.SigningMethodRS256:
+			if kid, _ := token.Header["kid"].(string); kid != s.keyID {
+				return nil, errors.New("unknown signing key")
+			}
+			return &s.key.PublicKey, nil
+		case jwt.SigningMethodHS256:
+			if s.legacySecret == nil || !time.Now().Before(s.legacyUntil) {
+				return nil, errors.New("legacy tokens are not accepted anymore")
+			}
+			return s.legacySecret, nil
 		}
 
-		return []byte(s.secretKey), nil
-	})
+		return nil, errors.New("unexpected signing method")
+	}, jwt.WithExpirationRequired())
 
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	claims, ok := token.Claims.(*Claims)
 	if !ok || !token.Valid {
-		return 0, errors.New("invalid token")
+		return nil, errors.New("invalid token")
+	}
+	if token.Method == jwt.SigningMethodRS256 {
+		// service and ID tokens are signed with the same key
+		if claims.TokenUse != accessTokenUse || claims.Issuer != s.issuer {
+			return nil, errors.New("not an access token")
+		}
+	} else if !s.isLegacyToken(claims) {
+		return nil, errors.New("invalid legacy token")
 	}
 
-	return claims.UserID, nil
+	result := &domain.TokenClaims{
+		ID:             claims.ID,
+		UserID:         claims.UserID,
+		Role:           claims.Role,
+		Scope:          claims.Scope,
+		ClientID:       claims.ClientID,
+		OrganizationID: claims.OrganizationID,
+	}
+	if claims.Act != nil {
+		actorID, err := strconv.ParseUint(claims.Act.Sub, 10, 64)
+		if err != nil || actorID == 0 {
+			return nil, errors.New("invalid act claim")
+		}
+		result.ActorID = actorID
+	}
+	if claims.IssuedAt != nil {
+		result.IssuedAt = claims.IssuedAt.Time
+	}
+	result.ExpiresAt = claims.ExpiresAt.Time
+
+	// tokens issued before jti was introduced are keyed by their hash
+	if result.ID == "" {
+		sum := sha256.Sum256([]byte(tokenString))
+		result.ID = hex.EncodeToString(sum[:])
+	}
 
+	return result, nil
+}
+
+// isLegacyToken only lets through HS256 tokens that were issued before this
+// instance started and live no longer than a token did back then
+func (s *jwtTokenService) isLegacyToken(claims *Claims) bool {
+	if claims.IssuedAt == nil {
+		return false
+	}
+	if claims.IssuedAt.After(s.startedAt) {
+		return false
+	}
+	return !claims.ExpiresAt.After(claims.IssuedAt.Add(s.tokenDuration))
 }
 
 func (s *jwtTokenService) BlacklistToken(token string) error {
-	// if Redis is not available, assume success
+	claims, err := s.ValidateToken(token)
+	if err != nil {
+		// expired or forged tokens are already unusable
+		return nil
+	}
+
+	ttl := time.Until(claims.ExpiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	// always remember the revocation locally, even if Redis is down
+	s.revocations.add(claims.ID, claims.ExpiresAt)
+
 	if s.redisClient == nil {
-		log.Println("Warning: Redis not available, token blacklisting skipped")
+		log.Println("Warning: Redis not available, token revoked on this instance only")
 		return nil
 	}
 
-	ctx := context.Background()
-	// store token in redis with expiration time
-	err := s.redisClient.Set(ctx,
-		"blacklist:"+token,
-		true,
-		s.tokenDuration,
-	).Err()
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
 
+	// store jti in redis until the token would expire anyway
+	err = s.redisClient.Set(ctx, revokedKeyPrefix+claims.ID, true, ttl).Err()
 	if err != nil {
-		log.Printf("Error blacklisting token: %v", err)
+		s.markDegraded(err)
 		return fmt.Errorf("failed to blacklist token: %v", err)
 	}
+	s.markHealthy()
+
+	payload, _ := json.Marshal(revocationEvent{JTI: claims.ID, ExpiresAt: claims.ExpiresAt.Unix()})
+	if err := s.redisClient.Publish(ctx, revocationChannel, payload).Err(); err != nil {
+		log.Printf("Error publishing token revocation: %v", err)
+	}
 
 	return nil
 }
 
-func (s *jwtTokenService) IsTokenBlacklisted(token string) bool {
+func (s *jwtTokenService) IsTokenBlacklisted(claims *domain.TokenClaims) (bool, error) {
+	if s.revocations.contains(claims.ID) {
+		return true, nil
+	}
+
 	if s.redisClient == nil {
-		// if Redis is not available, assume token is valid
-		return false
+		return s.degradedResult()
 	}
 
-	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	exists, err := s.redisClient.Exists(ctx, revokedKeyPrefix+claims.ID).Result()
+	if err != nil {
+		s.markDegraded(err)
+		return s.degradedResult()
+	}
+	s.markHealthy()
+
+	if exists > 0 {
+		s.revocations.add(claims.ID, claims.ExpiresAt)
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// degradedResult applies the configured fail mode when Redis can't answer
+func (s *jwtTokenService) degradedResult() (bool, error) {
+	revocationDegradedChecks.Add(1)
+
+	if s.failMode == RevocationFailClosed {
+		revocationFailClosedRejects.Add(1)
+		return false, domain.ErrRevocationUnavailable
+	}
+
+	return false, nil
+}
+
+func (s *jwtTokenService) markDegraded(err error) {
+	revocationRedisErrors.Add(1)
+	if !s.degraded.Swap(true) {
+		revocationDegraded.Set(1)
+		log.Printf("Warning: revocation store unavailable, running in fail-%s mode: %v", s.failMode, err)
+	}
+}
+
+func (s *jwtTokenService) markHealthy() {
+	if s.degraded.Swap(false) {
+		revocationDegraded.Set(0)
+		log.Println("Revocation store recovered")
+	}
+}
+
+// migrateLegacyRevocations moves revocations of earlier versions to
+// revoked:jti:<id>. It repeats until every token those versions issued has
+// expired, since old instances can still write them during a deploy
+func (s *jwtTokenService) migrateLegacyRevocations() {
+	deadline := time.Now().Add(s.tokenDuration)
+	ticker := time.NewTicker(cachePruneInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.migrateLegacyOnce()
+		if time.Now().After(deadline) {
+			return
+		}
+	}
+}
+
+func (s *jwtTokenService) migrateLegacyOnce() {
+	migrated, err := s.migrateLegacyKeys()
+	if err != nil {
+		log.Printf("Warning: failed to migrate legacy token revocations: %v", err)
+	} else if migrated > 0 {
+		log.Printf("Migrated %d legacy token revocations", migrated)
+	}
+}
+
+func (s *jwtTokenService) migrateLegacyKeys() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	migrated := 0
+	iter := s.redisClient.Scan(ctx, 0, legacyRevokedKeyPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		ttl, err := s.redisClient.TTL(ctx, key).Result()
+		if err != nil {
+			return migrated, err
+		}
+
+		// keys without a ttl were written without an expiry, keep them as long
+		// as a token lives
+		if ttl < 0 {
+			ttl = s.tokenDuration
+		}
+		id := legacyTokenID(strings.TrimPrefix(key, legacyRevokedKeyPrefix))
+		if err := s.redisClient.Set(ctx, revokedKeyPrefix+id, true, ttl).Err(); err != nil {
+			return migrated, err
+		}
+		s.revocations.add(id, time.Now().Add(ttl))
+
+		if err := s.redisClient.Del(ctx, key).Err(); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+
+	return migrated, iter.Err()
+}
+
+// legacyTokenID is the id ValidateToken gives the token: its jti, or the hash
+// of the token for tokens issued before jti was introduced
+func legacyTokenID(tokenString string) string {
+	var claims Claims
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, &claims); err == nil && claims.ID != "" {
+		return claims.ID
+	}
+
+	sum := sha256.Sum256([]byte(tokenString))
+	return hex.EncodeToString(sum[:])
+}
+
+// syncRevocations listens for revocations made by other instances
+func (s *jwtTokenService) syncRevocations() {
+	sub := s.redisClient.Subscribe(context.Background(), revocationChannel)
+	defer sub.Close()
+
+	for msg := range sub.Channel() {
+		var event revocationEvent
+		if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+			log.Printf("Invalid revocation event: %v", err)
+			continue
+		}
+
+		s.revocations.add(event.JTI, time.Unix(event.ExpiresAt, 0))
+	}
+}
+
+func (s *jwtTokenService) pruneRevocations() {
+	ticker := time.NewTicker(cachePruneInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.revocations.prune()
+	}
+}
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
 
-	exists, _ := s.redisClient.Exists(ctx, "blacklist:"+token).Result()
-	return exists > 0
+	return hex.EncodeToString(b), nil
 }
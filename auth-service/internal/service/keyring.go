@@ -0,0 +1,234 @@
+package service
+
+import (
+	"auth-service/internal/domain"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// ciphertext format: enc:v1:<key id>:<wrapped data key>:<encrypted value>
+	ciphertextPrefix = "enc:v1:"
+	keyFilePrefix    = "kek-"
+	keyFileSuffix    = ".key"
+	// BlindIndexKeyFile holds the HMAC key of the blind indexes
+	BlindIndexKeyFile = "blind_index.key"
+)
+
+var (
+	keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+	errMalformedCiphertext = errors.New("malformed ciphertext")
+)
+
+type keyring struct {
+	keys     map[string]cipher.AEAD
+	activeID string
+	indexKey []byte
+}
+
+// LoadKeyring reads the key encryption keys (kek-<id>.key) and the blind
+// index key from dir, every file holds 32 random bytes in base64. The key
+// with activeID encrypts new values, an empty activeID picks the highest id
+func LoadKeyring(dir, activeID string) (domain.Keyring, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, keyFilePrefix+"*"+keyFileSuffix))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no %s*%s files in %s", keyFilePrefix, keyFileSuffix, dir)
+	}
+
+	k := &keyring{keys: make(map[string]cipher.AEAD)}
+	var ids []string
+	for _, path := range paths {
+		id := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), keyFilePrefix), keyFileSuffix)
+		if !keyIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("invalid key id %q", id)
+		}
+
+		key, err := readKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		k.keys[id] = aead
+		ids = append(ids, id)
+	}
+
+	if activeID == "" {
+		sort.Strings(ids)
+		activeID = ids[len(ids)-1]
+	}
+	if _, ok := k.keys[activeID]; !ok {
+		return nil, fmt.Errorf("active key %q not found in %s", activeID, dir)
+	}
+	k.activeID = activeID
+
+	if k.indexKey, err = readKeyFile(filepath.Join(dir, BlindIndexKeyFile)); err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// GenerateKeyFile writes a new random key in the format LoadKeyring reads
+func GenerateKeyFile(path string) error {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+
+	// O_EXCL, an existing key is never overwritten
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(base64.StdEncoding.EncodeToString(key) + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// KeyFileName is the file name of the key encryption key with the given id
+func KeyFileName(id string) string {
+	return keyFilePrefix + id + keyFileSuffix
+}
+
+func (k *keyring) Encrypt(plaintext, associatedData []byte) (string, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	encrypted, err := seal(data, plaintext, associatedData)
+	if err != nil {
+		return "", err
+	}
+	// the key id is authenticated with the data key
+	wrapped, err := seal(k.keys[k.activeID], dataKey, []byte(k.activeID))
+	if err != nil {
+		return "", err
+	}
+
+	return k.ActivePrefix() + encode(wrapped) + ":" + encode(encrypted), nil
+}
+
+func (k *keyring) Decrypt(ciphertext string, associatedData []byte) ([]byte, error) {
+	if !k.IsEncrypted(ciphertext) {
+		return nil, errMalformedCiphertext
+	}
+	parts := strings.Split(strings.TrimPrefix(ciphertext, ciphertextPrefix), ":")
+	if len(parts) != 3 {
+		return nil, errMalformedCiphertext
+	}
+
+	kek, ok := k.keys[parts[0]]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", parts[0])
+	}
+	wrapped, err := decode(parts[1])
+	if err != nil {
+		return nil, errMalformedCiphertext
+	}
+	encrypted, err := decode(parts[2])
+	if err != nil {
+		return nil, errMalformedCiphertext
+	}
+
+	dataKey, err := open(kek, wrapped, []byte(parts[0]))
+	if err != nil {
+		return nil, err
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return open(data, encrypted, associatedData)
+}
+
+func (k *keyring) IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, ciphertextPrefix)
+}
+
+func (k *keyring) ActivePrefix() string {
+	return ciphertextPrefix + k.activeID + ":"
+}
+
+func (k *keyring) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func readKeyFile(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("%s must hold 32 bytes in base64", path)
+	}
+
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal prepends the random nonce to the ciphertext
+func seal(aead cipher.AEAD, plaintext, associatedData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, associatedData), nil
+}
+
+func open(aead cipher.AEAD, sealed, associatedData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errMalformedCiphertext
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, associatedData)
+}
+
+func encode(b []byte) string {
+	return base64.RawStdEncoding.EncodeToString(b)
+}
+
+func decode(s string) ([]byte, error) {
+	return base64.RawStdEncoding.DecodeString(s)
+}
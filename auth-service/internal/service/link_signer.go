@@ -0,0 +1,51 @@
+package service
+
+import (
+	"auth-service/internal/domain"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var errInvalidSignature = errors.New("invalid link signature")
+
+// linkSigner appends an HMAC-SHA256 of the payload, tokens look like
+// <payload>.<signature>
+type linkSigner struct {
+	secretKey []byte
+}
+
+func NewLinkSigner(secretKey string) domain.LinkSigner {
+	return &linkSigner{secretKey: []byte(secretKey)}
+}
+
+func (s *linkSigner) Sign(payload string) string {
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.mac(payload))
+}
+
+func (s *linkSigner) Verify(token string) (string, error) {
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return "", errInvalidSignature
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(token[i+1:])
+	if err != nil {
+		return "", errInvalidSignature
+	}
+
+	payload := token[:i]
+	if !hmac.Equal(signature, s.mac(payload)) {
+		return "", errInvalidSignature
+	}
+
+	return payload, nil
+}
+
+func (s *linkSigner) mac(payload string) []byte {
+	h := hmac.New(sha256.New, s.secretKey)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
@@ -0,0 +1,18 @@
+package service
+
+import (
+	"auth-service/internal/domain"
+	"log"
+)
+
+// logMailer writes emails to the service log, for local development
+type logMailer struct{}
+
+func NewLogMailer() domain.Mailer {
+	return &logMailer{}
+}
+
+func (m *logMailer) Send(mail domain.Mail) error {
+	log.Printf("Mail to %s\nSubject: %s\n\n%s", mail.To, mail.Subject, mail.Body)
+	return nil
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"auth-service/internal/domain"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+var errUnknownHashFormat = errors.New("unknown password hash format")
+
+type PasswordHasherConfig struct {
+	Algorithm     string // algorithm used for new hashes
+	Argon2Memory  uint32 // KiB
+	Argon2Time    uint32 // iterations
+	Argon2Threads uint8
+	BcryptCost    int
+}
+
+// DefaultPasswordHasherConfig follows the OWASP argon2id recommendation
+func DefaultPasswordHasherConfig() PasswordHasherConfig {
+	return PasswordHasherConfig{
+		Algorithm:     AlgorithmArgon2id,
+		Argon2Memory:  64 * 1024,
+		Argon2Time:    3,
+		Argon2Threads: 2,
+		BcryptCost:    bcrypt.DefaultCost,
+	}
+}
+
+type passwordHasher struct {
+	config PasswordHasherConfig
+}
+
+func NewPasswordHasher(config PasswordHasherConfig) (domain.PasswordHasher, error) {
+	switch config.Algorithm {
+	case AlgorithmArgon2id:
+		if config.Argon2Memory == 0 || config.Argon2Time == 0 || config.Argon2Threads == 0 {
+			return nil, errors.New("argon2id memory, time and threads must be greater than 0")
+		}
+	case AlgorithmBcrypt:
+		if config.BcryptCost < bcrypt.MinCost || config.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm: %s", config.Algorithm)
+	}
+
+	return &passwordHasher{config: config}, nil
+}
+
+func (h *passwordHasher) Hash(password string) (string, error) {
+	if h.config.Algorithm == AlgorithmBcrypt {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.config.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hashed), nil
+	}
+
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	params := argon2Params{
+		memory:  h.config.Argon2Memory,
+		time:    h.config.Argon2Time,
+		threads: h.config.Argon2Threads,
+	}
+	key := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, argon2KeyLength)
+
+	return params.encode(salt, key), nil
+}
+
+func (h *passwordHasher) Verify(password, encodedHash string) (bool, error) {
+	if isBcryptHash(encodedHash) {
+		err := bcrypt.CompareHashAndPassword([]byte(encodedHash), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	params, salt, key, err := decodeArgon2Hash(encodedHash)
+	if err != nil {
+		return false, err
+	}
+
+	candidate := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, candidate) == 1, nil
+}
+
+// NeedsRehash reports whether the hash was made with another algorithm or
+// with parameters that differ from the current configuration
+func (h *passwordHasher) NeedsRehash(encodedHash string) bool {
+	if isBcryptHash(encodedHash) {
+		if h.config.Algorithm != AlgorithmBcrypt {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(encodedHash))
+		return err != nil || cost != h.config.BcryptCost
+	}
+
+	if h.config.Algorithm != AlgorithmArgon2id {
+		return true
+	}
+
+	params, salt, key, err := decodeArgon2Hash(encodedHash)
+	if err != nil {
+		return true
+	}
+
+	return params.memory != h.config.Argon2Memory ||
+		params.time != h.config.Argon2Time ||
+		params.threads != h.config.Argon2Threads ||
+		len(salt) != argon2SaltLength ||
+		len(key) != argon2KeyLength
+}
+
+type argon2Params struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+}
+
+// encode produces the PHC string format, e.g.
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+func (p argon2Params) encode(salt, key []byte) string {
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		p.memory, p.time, p.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+}
+
+func decodeArgon2Hash(encodedHash string) (argon2Params, []byte, []byte, error) {
+	var params argon2Params
+
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 || parts[1] != AlgorithmArgon2id {
+		return params, nil, nil, errUnknownHashFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, errUnknownHashFormat
+	}
+	if version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2 version: %d", version)
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return params, nil, nil, errUnknownHashFormat
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, errUnknownHashFormat
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, errUnknownHashFormat
+	}
+
+	return params, salt, key, nil
+}
+
+func isBcryptHash(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, "$2a$") ||
+		strings.HasPrefix(encodedHash, "$2b$") ||
+		strings.HasPrefix(encodedHash, "$2y$")
+}
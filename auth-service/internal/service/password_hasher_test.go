@@ -0,0 +1,87 @@
+package service
+
+import "testing"
+
+func TestPasswordHasherUpgrade(t *testing.T) {
+	// cheap parameters, only the differences matter
+	argon2Config := PasswordHasherConfig{Algorithm: AlgorithmArgon2id, Argon2Memory: 1024, Argon2Time: 1, Argon2Threads: 1, BcryptCost: 4}
+	bcryptConfig := PasswordHasherConfig{Algorithm: AlgorithmBcrypt, Argon2Memory: 1024, Argon2Time: 1, Argon2Threads: 1, BcryptCost: 4}
+	with := func(config PasswordHasherConfig, change func(*PasswordHasherConfig)) PasswordHasherConfig {
+		change(&config)
+		return config
+	}
+
+	tests := []struct {
+		name       string
+		stored     PasswordHasherConfig
+		current    PasswordHasherConfig
+		wantRehash bool
+	}{
+		{"argon2id unchanged", argon2Config, argon2Config, false},
+		{"bcrypt unchanged", bcryptConfig, bcryptConfig, false},
+		{"bcrypt to argon2id", bcryptConfig, argon2Config, true},
+		{"argon2id to bcrypt", argon2Config, bcryptConfig, true},
+		{"argon2id memory raised", argon2Config, with(argon2Config, func(c *PasswordHasherConfig) { c.Argon2Memory = 2048 }), true},
+		{"argon2id time raised", argon2Config, with(argon2Config, func(c *PasswordHasherConfig) { c.Argon2Time = 2 }), true},
+		{"argon2id threads raised", argon2Config, with(argon2Config, func(c *PasswordHasherConfig) { c.Argon2Threads = 2 }), true},
+		{"bcrypt cost raised", bcryptConfig, with(bcryptConfig, func(c *PasswordHasherConfig) { c.BcryptCost = 5 }), true},
+		{"bcrypt cost of another algorithm changed", argon2Config, with(argon2Config, func(c *PasswordHasherConfig) { c.BcryptCost = 5 }), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored, err := NewPasswordHasher(tt.stored)
+			if err != nil {
+				t.Fatal(err)
+			}
+			current, err := NewPasswordHasher(tt.current)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			hash, err := stored.Hash("correct horse")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// old hashes keep working until they are upgraded
+			if match, err := current.Verify("correct horse", hash); err != nil || !match {
+				t.Fatalf("Verify() = %v, %v, want true", match, err)
+			}
+			if match, err := current.Verify("wrong horse", hash); err != nil || match {
+				t.Fatalf("Verify() with a wrong password = %v, %v, want false", match, err)
+			}
+			if got := current.NeedsRehash(hash); got != tt.wantRehash {
+				t.Fatalf("NeedsRehash() = %v, want %v", got, tt.wantRehash)
+			}
+
+			rehashed, err := current.Hash("correct horse")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if match, err := current.Verify("correct horse", rehashed); err != nil || !match {
+				t.Fatalf("Verify() after the upgrade = %v, %v, want true", match, err)
+			}
+			if current.NeedsRehash(rehashed) {
+				t.Error("NeedsRehash() after the upgrade = true, want false")
+			}
+		})
+	}
+}
+
+func TestPasswordHasherUnknownFormat(t *testing.T) {
+	hasher, err := NewPasswordHasher(PasswordHasherConfig{Algorithm: AlgorithmArgon2id, Argon2Memory: 1024, Argon2Time: 1, Argon2Threads: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, hash := range []string{"", "plaintext", "$argon2i$v=19$m=1024,t=1,p=1$c2FsdA$a2V5", "$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$a2V5"} {
+		t.Run(hash, func(t *testing.T) {
+			if match, err := hasher.Verify("plaintext", hash); err == nil || match {
+				t.Errorf("Verify() = %v, %v, want an error", match, err)
+			}
+			if !hasher.NeedsRehash(hash) {
+				t.Error("NeedsRehash() = false, want true")
+			}
+		})
+	}
+}
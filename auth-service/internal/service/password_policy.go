@@ -0,0 +1,217 @@
+package service
+
+import (
+	"auth-service/internal/domain"
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// violation codes returned to clients
+const (
+	ViolationTooShort         = "too_short"
+	ViolationTooLong          = "too_long"
+	ViolationMissingUpper     = "missing_upper"
+	ViolationMissingLower     = "missing_lower"
+	ViolationMissingDigit     = "missing_digit"
+	ViolationMissingSymbol    = "missing_symbol"
+	ViolationSimilarUsername  = "similar_to_username"
+	ViolationDictionaryWord   = "dictionary_word"
+	ViolationBreachedPassword = "breached"
+)
+
+type PasswordPolicyConfig struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// reject passwords that contain, or are close to, the username or the
+	// local part of the email
+	DisallowUsername bool
+}
+
+func DefaultPasswordPolicyConfig() PasswordPolicyConfig {
+	return PasswordPolicyConfig{
+		MinLength:        8,
+		MaxLength:        72, // bcrypt ignores anything past 72 bytes
+		DisallowUsername: true,
+	}
+}
+
+type passwordPolicy struct {
+	config     PasswordPolicyConfig
+	dictionary map[string]struct{}
+	breached   domain.BreachedPasswordChecker
+}
+
+// NewPasswordPolicy builds the policy; dictionary and breached are optional
+func NewPasswordPolicy(config PasswordPolicyConfig, dictionary map[string]struct{}, breached domain.BreachedPasswordChecker) domain.PasswordPolicy {
+	return &passwordPolicy{
+		config:     config,
+		dictionary: dictionary,
+		breached:   breached,
+	}
+}
+
+// LoadPasswordDictionary reads a word list, one word per line
+func LoadPasswordDictionary(path string) (map[string]struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open password dictionary: %v", err)
+	}
+	defer file.Close()
+
+	words := make(map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		word := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if word != "" {
+			words[word] = struct{}{}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read password dictionary: %v", err)
+	}
+
+	return words, nil
+}
+
+func (p *passwordPolicy) Validate(password, username, email string) []domain.PasswordViolation {
+	var violations []domain.PasswordViolation
+	add := func(code, message string) {
+		violations = append(violations, domain.PasswordViolation{Code: code, Message: message})
+	}
+
+	length := utf8.RuneCountInString(password)
+	if length < p.config.MinLength {
+		add(ViolationTooShort, fmt.Sprintf("password must be at least %d characters", p.config.MinLength))
+	}
+	if p.config.MaxLength > 0 && len(password) > p.config.MaxLength {
+		add(ViolationTooLong, fmt.Sprintf("password must be at most %d bytes", p.config.MaxLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.config.RequireUpper && !hasUpper {
+		add(ViolationMissingUpper, "password must contain an upper-case letter")
+	}
+	if p.config.RequireLower && !hasLower {
+		add(ViolationMissingLower, "password must contain a lower-case letter")
+	}
+	if p.config.RequireDigit && !hasDigit {
+		add(ViolationMissingDigit, "password must contain a digit")
+	}
+	if p.config.RequireSymbol && !hasSymbol {
+		add(ViolationMissingSymbol, "password must contain a symbol")
+	}
+
+	if p.config.DisallowUsername && similarToIdentity(password, username, email) {
+		add(ViolationSimilarUsername, "password must not be similar to your username or email")
+	}
+
+	if p.inDictionary(password) {
+		add(ViolationDictionaryWord, "password is too common")
+	}
+
+	if p.breached != nil && password != "" {
+		breached, err := p.breached.IsBreached(password)
+		if err != nil {
+			log.Printf("Breached password check failed: %v", err)
+		} else if breached {
+			add(ViolationBreachedPassword, "password has appeared in a data breach, choose another one")
+		}
+	}
+
+	return violations
+}
+
+// inDictionary also catches common words with digits or symbols appended,
+// e.g. "password123!"
+func (p *passwordPolicy) inDictionary(password string) bool {
+	if len(p.dictionary) == 0 || password == "" {
+		return false
+	}
+
+	lowered := strings.ToLower(password)
+	if _, ok := p.dictionary[lowered]; ok {
+		return true
+	}
+
+	trimmed := strings.TrimRightFunc(lowered, func(r rune) bool {
+		return unicode.IsDigit(r) || unicode.IsPunct(r) || unicode.IsSymbol(r)
+	})
+	_, ok := p.dictionary[trimmed]
+	return ok
+}
+
+func similarToIdentity(password, username, email string) bool {
+	lowered := strings.ToLower(password)
+	localPart, _, _ := strings.Cut(strings.ToLower(email), "@")
+
+	for _, identity := range []string{strings.ToLower(username), localPart} {
+		if len(identity) < 3 {
+			continue
+		}
+
+		if strings.Contains(lowered, identity) || strings.Contains(lowered, reverse(identity)) {
+			return true
+		}
+		if levenshtein(lowered, identity) <= 2 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+
+	return string(runes)
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeBreachedList writes passwords in the Have I Been Pwned format
+func writeBreachedList(t *testing.T, passwords ...string) string {
+	t.Helper()
+
+	lines := []string{"# test corpus"}
+	for _, password := range passwords {
+		sum := sha1.Sum([]byte(password))
+		lines = append(lines, strings.ToUpper(hex.EncodeToString(sum[:]))+":42")
+	}
+
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	breached, err := NewBreachedPasswordList(writeBreachedList(t, "Breached#Pass1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dictionary := map[string]struct{}{"password": {}, "dragon": {}}
+
+	strict := DefaultPasswordPolicyConfig()
+	strict.RequireUpper = true
+	strict.RequireLower = true
+	strict.RequireDigit = true
+	strict.RequireSymbol = true
+	lenient := DefaultPasswordPolicyConfig()
+	lenient.DisallowUsername = false
+
+	tests := []struct {
+		name     string
+		config   PasswordPolicyConfig
+		password string
+		username string
+		email    string
+		want     []string
+	}{
+		{"valid", strict, "Correct-Horse-9", "alice", "alice.smith@example.com", nil},
+		{"too short", strict, "Ab1!", "alice", "alice.smith@example.com", []string{ViolationTooShort}},
+		{"too long", strict, "Aa1!" + strings.Repeat("x", 69), "alice", "alice.smith@example.com", []string{ViolationTooLong}},
+		{"length counts characters", strict, "Ää1!Ää-ä", "alice", "alice.smith@example.com", nil},
+		{"missing upper", strict, "correct-horse-9", "alice", "alice.smith@example.com", []string{ViolationMissingUpper}},
+		{"missing lower", strict, "CORRECT-HORSE-9", "alice", "alice.smith@example.com", []string{ViolationMissingLower}},
+		{"missing digit", strict, "Correct-Horse-x", "alice", "alice.smith@example.com", []string{ViolationMissingDigit}},
+		{"missing symbol", strict, "CorrectHorse99", "alice", "alice.smith@example.com", []string{ViolationMissingSymbol}},
+		{"space is a symbol", strict, "Correct Horse 9", "alice", "alice.smith@example.com", nil},
+		{"empty", strict, "", "alice", "alice.smith@example.com", []string{
+			ViolationTooShort, ViolationMissingUpper, ViolationMissingLower, ViolationMissingDigit, ViolationMissingSymbol,
+		}},
+		{"contains the username", strict, "Alice-Rocks-1", "alice", "alice.smith@example.com", []string{ViolationSimilarUsername}},
+		{"contains the reversed username", strict, "Ecila-Rocks-1", "alice", "alice.smith@example.com", []string{ViolationSimilarUsername}},
+		{"contains the email local part", strict, "Carol.Jones9!", "bob123", "carol.jones@example.com", []string{ViolationSimilarUsername}},
+		{"close to the username", strict, "Wonderlan1!", "wonderland", "w@example.com", []string{ViolationSimilarUsername}},
+		{"short identities are ignored", strict, "Al-is-fine-1", "al", "al@example.com", nil},
+		{"username allowed", lenient, "alice-rocks", "alice", "alice.smith@example.com", nil},
+		{"dictionary word", strict, "Password1!", "alice", "alice.smith@example.com", []string{ViolationDictionaryWord}},
+		{"dictionary word with suffix", lenient, "dragon!!99", "alice", "alice.smith@example.com", []string{ViolationDictionaryWord}},
+		{"breached", strict, "Breached#Pass1", "alice", "alice.smith@example.com", []string{ViolationBreachedPassword}},
+		{"breached is case sensitive", strict, "breached#pASS1", "alice", "alice.smith@example.com", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := NewPasswordPolicy(tt.config, dictionary, breached)
+
+			var got []string
+			for _, violation := range policy.Validate(tt.password, tt.username, tt.email) {
+				got = append(got, violation.Code)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate(%q) = %v, want %v", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyWithoutLists(t *testing.T) {
+	policy := NewPasswordPolicy(DefaultPasswordPolicyConfig(), nil, nil)
+
+	if violations := policy.Validate("password", "alice", "alice@example.com"); len(violations) != 0 {
+		t.Errorf("Validate() = %v, want no violations without a dictionary or breached list", violations)
+	}
+}
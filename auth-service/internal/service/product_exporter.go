@@ -0,0 +1,40 @@
+package service
+
+import (
+	"auth-service/internal/domain"
+	"context"
+	"encoding/json"
+	productpb "grpc/pb/product"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+const productExportTimeout = 30 * time.Second
+
+type productExporter struct {
+	productClient productpb.ProductServiceClient
+}
+
+func NewProductExporter(productClient productpb.ProductServiceClient) domain.ProductExporter {
+	return &productExporter{productClient: productClient}
+}
+
+func (e *productExporter) ExportProducts(ctx context.Context, token string) (json.RawMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, productExportTimeout)
+	defer cancel()
+
+	// product-service checks the user token like a gateway call
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	resp, err := e.productClient.ExportMyProducts(ctx, &productpb.ExportMyProductsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	products := resp.Products
+	if products == nil {
+		products = []*productpb.Product{}
+	}
+
+	return json.Marshal(products)
+}
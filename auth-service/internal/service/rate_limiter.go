@@ -0,0 +1,86 @@
+package service
+
+import (
+	"auth-service/internal/domain"
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	rateLimitKeyPrefix = "ratelimit:"
+	// finished local windows are dropped once there are this many
+	rateLimitPruneSize = 1000
+)
+
+type rateWindow struct {
+	count     int
+	expiresAt time.Time
+}
+
+// rateLimiter counts in Redis so the limit holds across instances, while
+// Redis is down it counts in memory on this instance
+type rateLimiter struct {
+	redisClient *redis.Client
+
+	mu    sync.Mutex
+	local map[string]rateWindow
+}
+
+func NewRateLimiter(redisClient *redis.Client) domain.RateLimiter {
+	return &rateLimiter{
+		redisClient: redisClient,
+		local:       make(map[string]rateWindow),
+	}
+}
+
+func (l *rateLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, error) {
+	if l.redisClient != nil {
+		count, err := l.incr(ctx, rateLimitKeyPrefix+key, window)
+		if err == nil {
+			return count <= int64(limit), nil
+		}
+		log.Printf("Warning: rate limit check in Redis failed, counting locally: %v", err)
+	}
+
+	return l.allowLocal(key, limit, window), nil
+}
+
+func (l *rateLimiter) incr(ctx context.Context, key string, window time.Duration) (int64, error) {
+	pipe := l.redisClient.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	// only the first attempt starts the window
+	pipe.ExpireNX(ctx, key, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
+
+func (l *rateLimiter) allowLocal(key string, limit int, window time.Duration) bool {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.local) >= rateLimitPruneSize {
+		for k, w := range l.local {
+			if !now.Before(w.expiresAt) {
+				delete(l.local, k)
+			}
+		}
+	}
+
+	w, ok := l.local[key]
+	if !ok || !now.Before(w.expiresAt) {
+		w = rateWindow{expiresAt: now.Add(window)}
+	}
+	w.count++
+	l.local[key] = w
+
+	return w.count <= limit
+}
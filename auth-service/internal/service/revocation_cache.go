@@ -0,0 +1,46 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// revocationCache keeps revoked jti values in memory until the token would
+// have expired anyway, so revocations survive a Redis outage on this instance
+type revocationCache struct {
+	mu      sync.RWMutex
+	entries map[string]time.Time
+}
+
+func newRevocationCache() *revocationCache {
+	return &revocationCache{entries: make(map[string]time.Time)}
+}
+
+func (c *revocationCache) add(jti string, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[jti] = expiresAt
+}
+
+func (c *revocationCache) contains(jti string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	expiresAt, ok := c.entries[jti]
+	return ok && time.Now().Before(expiresAt)
+}
+
+// prune drops entries whose token has already expired
+func (c *revocationCache) prune() {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for jti, expiresAt := range c.entries {
+		if !now.Before(expiresAt) {
+			delete(c.entries, jti)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"auth-service/internal/domain"
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	revocationEventsChannel = "auth:revocation-events"
+	// slow watchers are disconnected instead of blocking revocations
+	subscriberBuffer = 64
+)
+
+type revocationNotifier struct {
+	redisClient *redis.Client
+
+	mu          sync.Mutex
+	subscribers map[chan domain.RevocationEvent]struct{}
+}
+
+// NewRevocationNotifier delivers events through Redis pub/sub so watchers
+// connected to other instances see them too, without Redis only local
+// watchers are notified
+func NewRevocationNotifier(redisClient *redis.Client) domain.RevocationNotifier {
+	n := &revocationNotifier{
+		redisClient: redisClient,
+		subscribers: make(map[chan domain.RevocationEvent]struct{}),
+	}
+
+	if redisClient != nil {
+		go n.listen()
+	}
+
+	return n
+}
+
+func (n *revocationNotifier) Publish(event domain.RevocationEvent) {
+	if n.redisClient == nil {
+		n.broadcast(event)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	payload, _ := json.Marshal(event)
+	if err := n.redisClient.Publish(ctx, revocationEventsChannel, payload).Err(); err != nil {
+		log.Printf("Error publishing revocation event: %v", err)
+		// at least the watchers of this instance hear about it
+		n.broadcast(event)
+	}
+}
+
+func (n *revocationNotifier) Subscribe() (<-chan domain.RevocationEvent, func()) {
+	ch := make(chan domain.RevocationEvent, subscriberBuffer)
+
+	n.mu.Lock()
+	n.subscribers[ch] = struct{}{}
+	n.mu.Unlock()
+
+	return ch, func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+
+		if _, ok := n.subscribers[ch]; ok {
+			delete(n.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+func (n *revocationNotifier) broadcast(event domain.RevocationEvent) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for ch := range n.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// the watcher must drop its cache when the channel closes
+			log.Println("Warning: revocation watcher is too slow, disconnecting it")
+			delete(n.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+func (n *revocationNotifier) listen() {
+	sub := n.redisClient.Subscribe(context.Background(), revocationEventsChannel)
+	defer sub.Close()
+
+	for msg := range sub.Channel() {
+		var event domain.RevocationEvent
+		if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+			log.Printf("Invalid revocation event: %v", err)
+			continue
+		}
+
+		n.broadcast(event)
+	}
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"auth-service/internal/domain"
+	"crypto/rsa"
+	"grpc/serviceauth"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// serviceTokenService issues the tokens used between our services, they are
+// signed with the OIDC key so services only need the JWKS to verify them
+type serviceTokenService struct {
+	issuer   string
+	key      *rsa.PrivateKey
+	keyID    string
+	duration time.Duration
+}
+
+func NewServiceTokenService(issuer string, key *rsa.PrivateKey, duration time.Duration) domain.ServiceTokenService {
+	return &serviceTokenService{
+		issuer:   issuer,
+		key:      key,
+		keyID:    rsaKeyID(&key.PublicKey),
+		duration: duration,
+	}
+}
+
+func (s *serviceTokenService) GenerateServiceToken(clientID, scope string) (string, time.Duration, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", 0, err
+	}
+
+	now := time.Now()
+	claims := serviceauth.Claims{
+		Scope:    scope,
+		TokenUse: serviceauth.TokenUse,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Issuer:    s.issuer,
+			Subject:   clientID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.duration)),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = s.keyID
+
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return signed, s.duration, nil
+}
+
+// ServiceTokenKeys exposes the verification key to the local gRPC server
+func ServiceTokenKeys(key *rsa.PrivateKey) serviceauth.StaticKeys {
+	return serviceauth.StaticKeys{rsaKeyID(&key.PublicKey): &key.PublicKey}
+}
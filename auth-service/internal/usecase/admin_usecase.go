@@ -0,0 +1,269 @@
+package usecase
+
+import (
+	"auth-service/internal/domain"
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	defaultImpersonationTTL = 15 * time.Minute
+	maxImpersonationTTL     = time.Hour
+)
+
+type adminUseCase struct {
+	authUseCase  domain.AuthUseCase
+	userRepo     domain.UserRepository
+	orgRepo      domain.OrganizationRepository
+	tokenService domain.TokenService
+	audit        domain.AuditLogger
+	revocations  domain.RevocationNotifier
+}
+
+func NewAdminUseCase(authUseCase domain.AuthUseCase, userRepo domain.UserRepository, orgRepo domain.OrganizationRepository, tokenService domain.TokenService, audit domain.AuditLogger, revocations domain.RevocationNotifier) domain.AdminUseCase {
+	return &adminUseCase{
+		authUseCase:  authUseCase,
+		userRepo:     userRepo,
+		orgRepo:      orgRepo,
+		tokenService: tokenService,
+		audit:        audit,
+		revocations:  revocations,
+	}
+}
+
+func (u *adminUseCase) ListUsers(ctx context.Context, token string, filter domain.UserFilter) ([]domain.User, int64, error) {
+	if _, err := requireAdmin(ctx, u.authUseCase, token); err != nil {
+		return nil, 0, err
+	}
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 {
+		filter.Limit = 10
+	}
+	if filter.Limit > 100 {
+		filter.Limit = 100
+	}
+
+	return u.userRepo.List(filter)
+}
+
+func (u *adminUseCase) GetUser(ctx context.Context, token string, id uint64) (*domain.User, error) {
+	if _, err := requireAdmin(ctx, u.authUseCase, token); err != nil {
+		return nil, err
+	}
+
+	return u.userRepo.FindByIDUnscoped(id)
+}
+
+// SetUserDisabled blocks or unblocks an account, disabled users fail
+// ValidateToken on their next request
+func (u *adminUseCase) SetUserDisabled(ctx context.Context, token string, id uint64, disabled bool) (*domain.User, error) {
+	admin, err := requireAdmin(ctx, u.authUseCase, token)
+	if err != nil {
+		return nil, err
+	}
+	if admin.ID == id {
+		return nil, domain.ErrSelfModification
+	}
+
+	user, err := u.userRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if disabled && user.DisabledAt == nil {
+		now := time.Now()
+		user.DisabledAt = &now
+	} else if !disabled {
+		user.DisabledAt = nil
+	}
+
+	if err := u.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	eventType := domain.AuditUserEnabled
+	if disabled {
+		eventType = domain.AuditUserDisabled
+	}
+	u.audit.Record(ctx, domain.AuditEvent{Type: eventType, UserID: user.ID, ActorID: admin.ID})
+	if disabled {
+		u.revocations.Publish(domain.RevocationEvent{UserID: user.ID})
+	}
+
+	return user, nil
+}
+
+func (u *adminUseCase) SetUserRole(ctx context.Context, token string, id uint64, role string) (*domain.User, error) {
+	admin, err := requireAdmin(ctx, u.authUseCase, token)
+	if err != nil {
+		return nil, err
+	}
+	if role != domain.RoleUser && role != domain.RoleAdmin {
+		return nil, domain.ErrInvalidRole
+	}
+	if admin.ID == id {
+		return nil, domain.ErrSelfModification
+	}
+
+	user, err := u.userRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	oldRole := user.Role
+	user.Role = role
+	if err := u.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	u.audit.Record(ctx, domain.AuditEvent{
+		Type:     domain.AuditRoleChange,
+		UserID:   user.ID,
+		ActorID:  admin.ID,
+		Metadata: map[string]string{"old_role": oldRole, "new_role": role},
+	})
+	u.revocations.Publish(domain.RevocationEvent{UserID: user.ID})
+
+	return user, nil
+}
+
+// ForceLogout invalidates every token issued to the user so far
+func (u *adminUseCase) ForceLogout(ctx context.Context, token string, id uint64) error {
+	admin, err := requireAdmin(ctx, u.authUseCase, token)
+	if err != nil {
+		return err
+	}
+	if admin.ID == id {
+		return domain.ErrSelfModification
+	}
+
+	user, err := u.userRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	// token iat has second precision, round up so tokens issued earlier in
+	// this second are revoked too. Tokens issued later in it are as well
+	validAfter := time.Now().Truncate(time.Second).Add(time.Second)
+	user.TokensValidAfter = &validAfter
+
+	if err := u.userRepo.Update(user); err != nil {
+		return err
+	}
+	u.audit.Record(ctx, domain.AuditEvent{
+		Type:     domain.AuditTokenRevocation,
+		UserID:   user.ID,
+		ActorID:  admin.ID,
+		Metadata: map[string]string{"scope": "all_tokens"},
+	})
+	u.revocations.Publish(domain.RevocationEvent{UserID: user.ID})
+
+	return nil
+}
+
+// RestoreUser undoes a soft delete
+func (u *adminUseCase) RestoreUser(ctx context.Context, token string, id uint64) (*domain.User, error) {
+	admin, err := requireAdmin(ctx, u.authUseCase, token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := u.userRepo.FindByIDUnscoped(id)
+	if err != nil {
+		return nil, err
+	}
+	if !user.DeletedAt.Valid {
+		return user, nil
+	}
+	// erased accounts hold no personal data anymore
+	if user.ErasedAt != nil {
+		return nil, domain.ErrUserErased
+	}
+
+	if err := u.userRepo.Restore(id); err != nil {
+		return nil, err
+	}
+	u.audit.Record(ctx, domain.AuditEvent{Type: domain.AuditUserRestored, UserID: id, ActorID: admin.ID})
+
+	return u.userRepo.FindByID(id)
+}
+
+// Impersonate lets support staff see the app as the user. The token can't
+// be used for sensitive actions (see authenticateOwner) and can't be
+// extended, the session is audited with its reason
+func (u *adminUseCase) Impersonate(ctx context.Context, token string, id uint64, reason string, ttl time.Duration) (string, time.Time, error) {
+	admin, err := requireAdmin(ctx, u.authUseCase, token)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len([]rune(reason)) > 500 {
+		return "", time.Time{}, &domain.ValidationError{Field: "reason", Message: "must be between 1 and 500 characters"}
+	}
+	if ttl == 0 {
+		ttl = defaultImpersonationTTL
+	}
+	if ttl < time.Minute || ttl > maxImpersonationTTL {
+		return "", time.Time{}, &domain.ValidationError{Field: "ttl_minutes", Message: "must be between 1 and 60"}
+	}
+
+	user, err := u.userRepo.FindByID(id)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	// admins are excluded so an impersonation never grants admin rights
+	if user.ID == admin.ID || user.IsAdmin() || user.IsDisabled() {
+		return "", time.Time{}, domain.ErrCannotImpersonate
+	}
+
+	orgID, err := defaultOrganization(u.orgRepo, user)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	impersonationToken, err := u.tokenService.GenerateToken(domain.TokenRequest{
+		UserID:         user.ID,
+		Role:           user.Role,
+		OrganizationID: orgID,
+		ActorID:        admin.ID,
+		ExpiresIn:      ttl,
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	u.audit.Record(ctx, domain.AuditEvent{
+		Type:    domain.AuditImpersonation,
+		UserID:  user.ID,
+		ActorID: admin.ID,
+		Metadata: map[string]string{
+			"reason":     reason,
+			"expires_at": expiresAt.UTC().Format(time.RFC3339),
+		},
+	})
+
+	return impersonationToken, expiresAt, nil
+}
+
+// requireAdmin resolves the token owner and checks the admin role,
+// impersonation tokens never count as admin even if the user was promoted
+func requireAdmin(ctx context.Context, authUseCase domain.AuthUseCase, token string) (*domain.User, error) {
+	user, claims, err := authUseCase.ValidateTokenClaims(ctx, token)
+	if err != nil || claims.ClientID != "" {
+		if errors.Is(err, domain.ErrRevocationUnavailable) {
+			return nil, err
+		}
+		return nil, domain.ErrInvalidToken
+	}
+
+	if !user.IsAdmin() || claims.IsImpersonation() {
+		return nil, domain.ErrForbidden
+	}
+
+	return user, nil
+}
@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"auth-service/internal/domain"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxAPIKeysPerUser     = 25
+	defaultAPIKeyLifetime = 90 // days
+	maxAPIKeyLifetime     = 365
+	// last used is only written once per interval to avoid a write per request
+	apiKeyTouchInterval = time.Minute
+)
+
+type apiKeyUseCase struct {
+	authUseCase domain.AuthUseCase
+	userRepo    domain.UserRepository
+	apiKeyRepo  domain.APIKeyRepository
+	audit       domain.AuditLogger
+	revocations domain.RevocationNotifier
+}
+
+func NewAPIKeyUseCase(authUseCase domain.AuthUseCase, userRepo domain.UserRepository, apiKeyRepo domain.APIKeyRepository, audit domain.AuditLogger, revocations domain.RevocationNotifier) domain.APIKeyUseCase {
+	return &apiKeyUseCase{
+		authUseCase: authUseCase,
+		userRepo:    userRepo,
+		apiKeyRepo:  apiKeyRepo,
+		audit:       audit,
+		revocations: revocations,
+	}
+}
+
+func (u *apiKeyUseCase) CreateAPIKey(ctx context.Context, token, name string, scopes []string, expiresInDays int) (*domain.APIKey, string, error) {
+	user, claims, err := authenticateSession(ctx, u.authUseCase, token)
+	if err != nil {
+		return nil, "", err
+	}
+	// a key would outlive the impersonation
+	if claims.IsImpersonation() {
+		return nil, "", domain.ErrImpersonationForbidden
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > 100 {
+		return nil, "", &domain.ValidationError{Field: "name", Message: "must be between 1 and 100 characters"}
+	}
+
+	scopes, err = normalizeAPIKeyScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if expiresInDays == 0 {
+		expiresInDays = defaultAPIKeyLifetime
+	}
+	if expiresInDays < 1 || expiresInDays > maxAPIKeyLifetime {
+		return nil, "", &domain.ValidationError{Field: "expires_in_days", Message: "must be between 1 and " + strconv.Itoa(maxAPIKeyLifetime)}
+	}
+
+	count, err := u.apiKeyRepo.CountActiveByUser(user.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	if count >= maxAPIKeysPerUser {
+		return nil, "", domain.ErrAPIKeyLimit
+	}
+
+	plainKey, err := newAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	expiresAt := time.Now().Add(time.Duration(expiresInDays) * 24 * time.Hour)
+	key := &domain.APIKey{
+		UserID:         user.ID,
+		OrganizationID: claims.OrganizationID,
+		Name:           name,
+		Prefix:         plainKey[:len(domain.APIKeyPrefix)+8],
+		KeyHash:        hashVerificationToken(plainKey),
+		Scopes:         strings.Join(scopes, " "),
+		ExpiresAt:      &expiresAt,
+	}
+
+	if err := u.apiKeyRepo.Create(key); err != nil {
+		return nil, "", err
+	}
+	u.audit.Record(ctx, domain.AuditEvent{
+		Type:     domain.AuditAPIKeyCreated,
+		UserID:   user.ID,
+		Metadata: map[string]string{"api_key_id": strconv.FormatUint(key.ID, 10), "name": key.Name, "scopes": key.Scopes},
+	})
+
+	return key, plainKey, nil
+}
+
+func (u *apiKeyUseCase) ListAPIKeys(ctx context.Context, token string) ([]domain.APIKey, error) {
+	user, err := authenticate(ctx, u.authUseCase, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.apiKeyRepo.ListByUser(user.ID)
+}
+
+func (u *apiKeyUseCase) RevokeAPIKey(ctx context.Context, token string, id uint64) error {
+	user, err := authenticate(ctx, u.authUseCase, token)
+	if err != nil {
+		return err
+	}
+
+	if err := u.apiKeyRepo.Revoke(user.ID, id); err != nil {
+		return err
+	}
+	u.audit.Record(ctx, domain.AuditEvent{
+		Type:     domain.AuditAPIKeyRevoked,
+		UserID:   user.ID,
+		Metadata: map[string]string{"api_key_id": strconv.FormatUint(id, 10)},
+	})
+	// only the hash of the key is stored, drop everything cached for the user
+	u.revocations.Publish(domain.RevocationEvent{UserID: user.ID})
+
+	return nil
+}
+
+// ValidateAPIKey resolves the key owner, keys follow the same account rules
+// as tokens (disabled users and force logouts)
+func (u *apiKeyUseCase) ValidateAPIKey(ctx context.Context, plainKey string) (*domain.User, *domain.APIKey, error) {
+	if !strings.HasPrefix(plainKey, domain.APIKeyPrefix) {
+		return nil, nil, domain.ErrInvalidToken
+	}
+
+	key, err := u.apiKeyRepo.FindByHash(hashVerificationToken(plainKey))
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return nil, nil, domain.ErrInvalidToken
+		}
+		return nil, nil, err
+	}
+	if key.IsExpired() {
+		return nil, nil, domain.ErrInvalidToken
+	}
+
+	user, err := u.userRepo.FindByID(key.UserID)
+	if err != nil {
+		return nil, nil, domain.ErrInvalidToken
+	}
+	if user.IsDisabled() {
+		return nil, nil, domain.ErrAccountDisabled
+	}
+	if user.TokensValidAfter != nil && key.CreatedAt.Before(*user.TokensValidAfter) {
+		return nil, nil, domain.ErrTokenRevoked
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval {
+		ip := domain.RequestMetaFromContext(ctx).IP
+		if err := u.apiKeyRepo.TouchLastUsed(key.ID, now, ip); err != nil {
+			log.Printf("Failed to update last use of api key %d: %v", key.ID, err)
+		}
+		key.LastUsedAt = &now
+		key.LastUsedIP = ip
+	}
+
+	return user, key, nil
+}
+
+func normalizeAPIKeyScopes(scopes []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var result []string
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if scope == "" || seen[scope] {
+			continue
+		}
+		if !containsScope(domain.APIKeyScopes, scope) {
+			return nil, &domain.ValidationError{Field: "scopes", Message: "unknown scope " + scope + ", allowed: " + strings.Join(domain.APIKeyScopes, ", ")}
+		}
+		seen[scope] = true
+		result = append(result, scope)
+	}
+
+	if len(result) == 0 {
+		return nil, &domain.ValidationError{Field: "scopes", Message: "at least one scope is required"}
+	}
+	sort.Strings(result)
+
+	return result, nil
+}
+
+func newAPIKey() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return domain.APIKeyPrefix + hex.EncodeToString(b), nil
+}
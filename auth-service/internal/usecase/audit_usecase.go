@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"auth-service/internal/domain"
+	"context"
+)
+
+type auditUseCase struct {
+	authUseCase domain.AuthUseCase
+	auditRepo   domain.AuditRepository
+}
+
+func NewAuditUseCase(authUseCase domain.AuthUseCase, auditRepo domain.AuditRepository) domain.AuditUseCase {
+	return &auditUseCase{
+		authUseCase: authUseCase,
+		auditRepo:   auditRepo,
+	}
+}
+
+func (u *auditUseCase) ListEvents(ctx context.Context, token string, filter domain.AuditFilter) ([]domain.AuditEvent, int64, error) {
+	if _, err := requireAdmin(ctx, u.authUseCase, token); err != nil {
+		return nil, 0, err
+	}
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 {
+		filter.Limit = 50
+	}
+	if filter.Limit > 500 {
+		filter.Limit = 500
+	}
+
+	return u.auditRepo.List(filter)
+}
+
+func (u *auditUseCase) ExportEvents(ctx context.Context, token string, filter domain.AuditFilter, fn func(event *domain.AuditEvent) error) error {
+	if _, err := requireAdmin(ctx, u.authUseCase, token); err != nil {
+		return err
+	}
+
+	return u.auditRepo.Each(filter, fn)
+}
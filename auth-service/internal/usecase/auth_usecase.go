@@ -2,36 +2,89 @@ package usecase
 
 import (
 	"auth-service/internal/domain"
+	"context"
 	"errors"
-
-	"golang.org/x/crypto/bcrypt"
+	"log"
+	"strconv"
+	"strings"
 )
 
 type authUseCase struct {
-	userRepo     domain.UserRepository
-	tokenService domain.TokenService
+	userRepo       domain.UserRepository
+	orgRepo        domain.OrganizationRepository
+	inviteRepo     domain.RegistrationInviteRepository
+	tokenService   domain.TokenService
+	passwordHasher domain.PasswordHasher
+	passwordPolicy domain.PasswordPolicy
+	mailer         domain.Mailer
+	audit          domain.AuditLogger
+	revocations    domain.RevocationNotifier
+	// audit events name addresses by their blind index only
+	keyring domain.Keyring
+	// open, invite_only or closed
+	registrationMode string
+	appBaseURL       string // used to build links sent by email
 }
 
-func NewAuthUseCase(userRepo domain.UserRepository, tokenService domain.TokenService) domain.AuthUseCase {
+func NewAuthUseCase(userRepo domain.UserRepository, orgRepo domain.OrganizationRepository, inviteRepo domain.RegistrationInviteRepository, tokenService domain.TokenService, passwordHasher domain.PasswordHasher, passwordPolicy domain.PasswordPolicy, mailer domain.Mailer, audit domain.AuditLogger, revocations domain.RevocationNotifier, keyring domain.Keyring, registrationMode, appBaseURL string) domain.AuthUseCase {
 	return &authUseCase{
-		userRepo:     userRepo,
-		tokenService: tokenService,
+		userRepo:         userRepo,
+		orgRepo:          orgRepo,
+		inviteRepo:       inviteRepo,
+		tokenService:     tokenService,
+		passwordHasher:   passwordHasher,
+		passwordPolicy:   passwordPolicy,
+		mailer:           mailer,
+		audit:            audit,
+		revocations:      revocations,
+		keyring:          keyring,
+		registrationMode: registrationMode,
+		appBaseURL:       appBaseURL,
 	}
 }
 
-func (a *authUseCase) Register(username, email, password string) (*domain.User, string, error) {
-	// check username exist
+func (a *authUseCase) Register(ctx context.Context, username, email, password, inviteCode string) (*domain.User, string, error) {
+	if a.registrationMode == domain.RegistrationClosed {
+		return nil, "", domain.ErrRegistrationClosed
+	}
+	if a.registrationMode == domain.RegistrationInviteOnly && inviteCode == "" {
+		return nil, "", domain.ErrInviteRequired
+	}
+
+	// a code is also accepted in open mode, it can preassign a role
+	var invite *domain.RegistrationInvite
+	if inviteCode != "" {
+		found, err := a.inviteRepo.FindUsableByHash(hashVerificationToken(inviteCode))
+		if err != nil {
+			return nil, "", err
+		}
+		invite = found
+	}
+
+	username = strings.TrimSpace(username)
+	email = strings.TrimSpace(email)
+	// "@" is reserved so a login is never both a username and an email
+	if username == "" || strings.Contains(username, "@") {
+		return nil, "", &domain.ValidationError{Field: "username", Message: "must not be empty or contain @"}
+	}
+
+	// check username exist, case is ignored
 	if _, err := a.userRepo.FindByUsername(username); err == nil {
-		return nil, "", errors.New("username already exist")
+		return nil, "", domain.ErrUsernameTaken
 	}
 
 	// check email exist
 	if _, err := a.userRepo.FindByEmail(email); err == nil {
-		return nil, "", errors.New("email already exist")
+		return nil, "", domain.ErrEmailTaken
+	}
+
+	// enforce password policy
+	if violations := a.passwordPolicy.Validate(password, username, email); len(violations) > 0 {
+		return nil, "", &domain.PasswordPolicyError{Violations: violations}
 	}
 
 	// hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword, err := a.passwordHasher.Hash(password)
 	if err != nil {
 		return nil, "", err
 	}
@@ -39,15 +92,32 @@ func (a *authUseCase) Register(username, email, password string) (*domain.User,
 	user := &domain.User{
 		Username: username,
 		Email:    email,
-		Password: string(hashedPassword),
+		Password: hashedPassword,
+		Role:     domain.RoleUser,
+	}
+
+	event := domain.AuditEvent{Type: domain.AuditRegister}
+	if invite != nil {
+		if err := a.inviteRepo.Consume(invite.ID); err != nil {
+			return nil, "", err
+		}
+		user.Role = invite.Role
+		event.Metadata = map[string]string{"invite_id": strconv.FormatUint(invite.ID, 10), "role": invite.Role}
 	}
 
 	if err := a.userRepo.Create(user); err != nil {
+		if invite != nil {
+			if releaseErr := a.inviteRepo.Release(invite.ID); releaseErr != nil {
+				log.Printf("Warning: failed to release invite %d: %v", invite.ID, releaseErr)
+			}
+		}
 		return nil, "", err
 	}
+	event.UserID = user.ID
+	a.audit.Record(ctx, event)
 
 	// generate token
-	token, err := a.tokenService.GenerateToken(user.ID)
+	token, err := a.issueToken(user)
 	if err != nil {
 		return nil, "", err
 	}
@@ -56,20 +126,14 @@ func (a *authUseCase) Register(username, email, password string) (*domain.User,
 
 }
 
-func (a *authUseCase) Login(username, password string) (*domain.User, string, error) {
-	user, err := a.userRepo.FindByUsername(username)
+func (a *authUseCase) Login(ctx context.Context, login, password string) (*domain.User, string, error) {
+	user, err := a.Authenticate(ctx, login, password)
 	if err != nil {
-		return nil, "", errors.New("invalid credentials")
-	}
-
-	// compare password
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
-	if err != nil {
-		return nil, "", errors.New("invalid credentials")
+		return nil, "", err
 	}
 
 	// generate token
-	token, err := a.tokenService.GenerateToken(user.ID)
+	token, err := a.issueToken(user)
 	if err != nil {
 		return nil, "", err
 	}
@@ -77,28 +141,143 @@ func (a *authUseCase) Login(username, password string) (*domain.User, string, er
 	return user, token, nil
 }
 
-func (a *authUseCase) ValidateToken(token string) (*domain.User, error) {
-	// check if token is blacklisted
-	if a.tokenService.IsTokenBlacklisted(token) {
-		return nil, errors.New("token is blacklisted")
+// Authenticate checks a username or email and password without issuing a token, it is
+// shared by Login and the OIDC authorization page
+func (a *authUseCase) Authenticate(ctx context.Context, login, password string) (*domain.User, error) {
+	login = strings.TrimSpace(login)
+	user, err := a.userRepo.FindByUsername(login)
+	if errors.Is(err, domain.ErrUserNotFound) && strings.Contains(login, "@") {
+		user, err = a.userRepo.FindByEmail(login)
+	}
+	if err != nil {
+		a.recordLoginFailure(ctx, 0, login, "unknown_user")
+		return nil, domain.ErrInvalidCredentials
 	}
 
-	// validate token
-	userID, err := a.tokenService.ValidateToken(token)
+	// compare password
+	match, err := a.passwordHasher.Verify(password, user.Password)
+	if err != nil || !match {
+		a.recordLoginFailure(ctx, user.ID, login, "wrong_password")
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	if user.IsDisabled() {
+		a.recordLoginFailure(ctx, user.ID, login, "disabled")
+		return nil, domain.ErrAccountDisabled
+	}
+
+	// upgrade hashes made with an outdated algorithm or parameters
+	if a.passwordHasher.NeedsRehash(user.Password) {
+		a.rehashPassword(user, password)
+	}
+	a.audit.Record(ctx, domain.AuditEvent{Type: domain.AuditLoginSuccess, UserID: user.ID})
+
+	return user, nil
+}
+
+// ValidateToken accepts first-party session tokens only, tokens issued to
+// OAuth clients are limited to the scopes the user consented to
+func (a *authUseCase) ValidateToken(ctx context.Context, token string) (*domain.User, error) {
+	user, claims, err := a.ValidateTokenClaims(ctx, token)
 	if err != nil {
 		return nil, err
 	}
 
+	if claims.ClientID != "" {
+		return nil, domain.ErrInvalidToken
+	}
+
+	return user, nil
+}
+
+// ValidateTokenClaims checks any access token, including ones issued to
+// OAuth clients, and returns its claims next to the owner
+func (a *authUseCase) ValidateTokenClaims(ctx context.Context, token string) (*domain.User, *domain.TokenClaims, error) {
+	// validate token
+	claims, err := a.tokenService.ValidateToken(token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// check if token is blacklisted
+	blacklisted, err := a.tokenService.IsTokenBlacklisted(claims)
+	if err != nil {
+		return nil, nil, err
+	}
+	if blacklisted {
+		return nil, nil, errors.New("token is blacklisted")
+	}
+
 	// get user by id
-	user, err := a.userRepo.FindByID(userID)
+	user, err := a.userRepo.FindByID(claims.UserID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return user, nil
+	if user.IsDisabled() {
+		return nil, nil, domain.ErrAccountDisabled
+	}
+
+	// reject tokens issued before a force logout
+	if user.TokensValidAfter != nil && claims.IssuedAt.Before(*user.TokensValidAfter) {
+		return nil, nil, domain.ErrTokenRevoked
+	}
+
+	return user, claims, nil
 }
 
-func (a *authUseCase) Logout(token string) error {
+func (a *authUseCase) Logout(ctx context.Context, token string) error {
 	// add token to blacklist
-	return a.tokenService.BlacklistToken(token)
+	if err := a.tokenService.BlacklistToken(token); err != nil {
+		return err
+	}
+	a.revocations.Publish(domain.RevocationEvent{TokenHash: hashVerificationToken(token)})
+
+	if claims, err := a.tokenService.ValidateToken(token); err == nil {
+		a.audit.Record(ctx, domain.AuditEvent{Type: domain.AuditLogout, UserID: claims.UserID})
+	}
+
+	return nil
+}
+
+// recordLoginFailure stores the blind index of the login, it is often an
+// email and unknown logins are never anonymized
+func (a *authUseCase) recordLoginFailure(ctx context.Context, userID uint64, login, reason string) {
+	a.audit.Record(ctx, domain.AuditEvent{
+		Type:     domain.AuditLoginFailure,
+		UserID:   userID,
+		Metadata: map[string]string{"login_index": a.identityIndex(login), "reason": reason},
+	})
+}
+
+// identityIndex matches the email_index of users for emails
+func (a *authUseCase) identityIndex(value string) string {
+	return a.keyring.BlindIndex(domain.NormalizeIdentity(value))
+}
+
+// issueToken starts a session in the user's default organization
+func (a *authUseCase) issueToken(user *domain.User) (string, error) {
+	orgID, err := defaultOrganization(a.orgRepo, user)
+	if err != nil {
+		return "", err
+	}
+
+	return a.tokenService.GenerateToken(domain.TokenRequest{
+		UserID:         user.ID,
+		Role:           user.Role,
+		OrganizationID: orgID,
+	})
+}
+
+func (a *authUseCase) rehashPassword(user *domain.User, password string) {
+	hashedPassword, err := a.passwordHasher.Hash(password)
+	if err != nil {
+		log.Printf("Failed to rehash password for user %d: %v", user.ID, err)
+		return
+	}
+
+	user.Password = hashedPassword
+	if err := a.userRepo.Update(user); err != nil {
+		log.Printf("Failed to store rehashed password for user %d: %v", user.ID, err)
+	}
 }
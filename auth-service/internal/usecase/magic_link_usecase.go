@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"auth-service/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	magicLinkTTL = 15 * time.Minute
+	// requests per address, counted for unknown addresses too
+	magicLinkRateLimit  = 3
+	magicLinkRateWindow = 15 * time.Minute
+)
+
+type magicLinkUseCase struct {
+	userRepo      domain.UserRepository
+	orgRepo       domain.OrganizationRepository
+	magicLinkRepo domain.MagicLinkRepository
+	tokenService  domain.TokenService
+	signer        domain.LinkSigner
+	rateLimiter   domain.RateLimiter
+	mailer        domain.Mailer
+	audit         domain.AuditLogger
+	appBaseURL    string // used to build login links
+}
+
+func NewMagicLinkUseCase(userRepo domain.UserRepository, orgRepo domain.OrganizationRepository, magicLinkRepo domain.MagicLinkRepository, tokenService domain.TokenService, signer domain.LinkSigner, rateLimiter domain.RateLimiter, mailer domain.Mailer, audit domain.AuditLogger, appBaseURL string) domain.MagicLinkUseCase {
+	return &magicLinkUseCase{
+		userRepo:      userRepo,
+		orgRepo:       orgRepo,
+		magicLinkRepo: magicLinkRepo,
+		tokenService:  tokenService,
+		signer:        signer,
+		rateLimiter:   rateLimiter,
+		mailer:        mailer,
+		audit:         audit,
+		appBaseURL:    appBaseURL,
+	}
+}
+
+func (u *magicLinkUseCase) RequestMagicLink(ctx context.Context, email string) error {
+	address, err := mail.ParseAddress(email)
+	if err != nil {
+		return &domain.ValidationError{Field: "email", Message: "must be a valid email address"}
+	}
+
+	allowed, err := u.rateLimiter.Allow(ctx, "magic_link:"+strings.ToLower(address.Address), magicLinkRateLimit, magicLinkRateWindow)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return domain.ErrRateLimited
+	}
+
+	user, err := u.userRepo.FindByEmail(address.Address)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.IsDisabled() {
+		return nil
+	}
+
+	nonce, nonceHash, err := newVerificationToken()
+	if err != nil {
+		return err
+	}
+
+	link := &domain.MagicLink{
+		UserID:    user.ID,
+		NonceHash: nonceHash,
+		ExpiresAt: time.Now().Add(magicLinkTTL),
+	}
+	if err := u.magicLinkRepo.Create(link); err != nil {
+		return err
+	}
+	u.audit.Record(ctx, domain.AuditEvent{Type: domain.AuditMagicLinkRequested, UserID: user.ID})
+
+	token := u.signer.Sign(nonce + "." + strconv.FormatInt(link.ExpiresAt.Unix(), 10))
+	return u.mailer.Send(domain.Mail{
+		To:      user.Email,
+		Subject: "Your login link",
+		Body: fmt.Sprintf("Hi %s,\n\nOpen this link to log in:\n%s/auth/magic-link/consume?token=%s\n\nThe link can be used once and expires in 15 minutes. If you did not request it, ignore this email.",
+			user.Username, u.appBaseURL, token),
+	})
+}
+
+func (u *magicLinkUseCase) ConsumeMagicLink(ctx context.Context, token string) (*domain.User, string, error) {
+	payload, err := u.signer.Verify(token)
+	if err != nil {
+		return nil, "", domain.ErrMagicLinkInvalid
+	}
+
+	// payload is <nonce>.<expiry unix>, expired links are rejected without
+	// a database lookup
+	nonce, expiry, ok := strings.Cut(payload, ".")
+	if !ok {
+		return nil, "", domain.ErrMagicLinkInvalid
+	}
+	expiresAt, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || time.Now().Unix() >= expiresAt {
+		return nil, "", domain.ErrMagicLinkInvalid
+	}
+
+	link, err := u.magicLinkRepo.Consume(hashVerificationToken(nonce))
+	if err != nil {
+		return nil, "", err
+	}
+
+	user, err := u.userRepo.FindByID(link.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, "", domain.ErrMagicLinkInvalid
+		}
+		return nil, "", err
+	}
+	if user.IsDisabled() {
+		u.audit.Record(ctx, domain.AuditEvent{
+			Type:     domain.AuditLoginFailure,
+			UserID:   user.ID,
+			Metadata: map[string]string{"reason": "disabled", "method": "magic_link"},
+		})
+		return nil, "", domain.ErrAccountDisabled
+	}
+
+	orgID, err := defaultOrganization(u.orgRepo, user)
+	if err != nil {
+		return nil, "", err
+	}
+	sessionToken, err := u.tokenService.GenerateToken(domain.TokenRequest{
+		UserID:         user.ID,
+		Role:           user.Role,
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	u.audit.Record(ctx, domain.AuditEvent{
+		Type:     domain.AuditLoginSuccess,
+		UserID:   user.ID,
+		Metadata: map[string]string{"method": "magic_link"},
+	})
+
+	return user, sessionToken, nil
+}
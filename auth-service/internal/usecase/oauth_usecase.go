@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"auth-service/internal/domain"
+	"context"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+)
+
+type oauthUseCase struct {
+	authUseCase         domain.AuthUseCase
+	tokenService        domain.TokenService
+	serviceTokenService domain.ServiceTokenService
+	clientRepo          domain.OAuthClientRepository
+	secretHasher        domain.PasswordHasher
+	audit               domain.AuditLogger
+	revocations         domain.RevocationNotifier
+}
+
+func NewOAuthUseCase(authUseCase domain.AuthUseCase, tokenService domain.TokenService, serviceTokenService domain.ServiceTokenService, clientRepo domain.OAuthClientRepository, secretHasher domain.PasswordHasher, audit domain.AuditLogger, revocations domain.RevocationNotifier) domain.OAuthUseCase {
+	return &oauthUseCase{
+		authUseCase:         authUseCase,
+		tokenService:        tokenService,
+		serviceTokenService: serviceTokenService,
+		clientRepo:          clientRepo,
+		secretHasher:        secretHasher,
+		audit:               audit,
+		revocations:         revocations,
+	}
+}
+
+func (u *oauthUseCase) AuthenticateClient(ctx context.Context, clientID, clientSecret string) (*domain.OAuthClient, error) {
+	if clientID == "" || clientSecret == "" {
+		return nil, domain.ErrInvalidClient
+	}
+
+	client, err := u.clientRepo.FindByClientID(clientID)
+	if err != nil {
+		if errors.Is(err, domain.ErrClientNotFound) {
+			return nil, domain.ErrInvalidClient
+		}
+		return nil, err
+	}
+
+	// public clients have no secret to check
+	if client.Public {
+		return nil, domain.ErrInvalidClient
+	}
+	if !verifyClientSecret(u.secretHasher, u.clientRepo, client, clientSecret) {
+		return nil, domain.ErrInvalidClient
+	}
+
+	return client, nil
+}
+
+// Introspect reports whether a token is currently usable, it applies the
+// same checks as ValidateToken (revocation, disabled user, force logout)
+// but also accepts tokens issued to OAuth clients
+func (u *oauthUseCase) Introspect(ctx context.Context, client *domain.OAuthClient, token string) (*domain.TokenIntrospection, error) {
+	if !client.HasScope(domain.ScopeIntrospect) {
+		return nil, domain.ErrForbidden
+	}
+
+	user, claims, err := u.authUseCase.ValidateTokenClaims(ctx, token)
+	if err != nil {
+		if errors.Is(err, domain.ErrRevocationUnavailable) {
+			return nil, err
+		}
+		return &domain.TokenIntrospection{Active: false}, nil
+	}
+
+	return &domain.TokenIntrospection{
+		Active:    true,
+		Scope:     claims.Scope,
+		ClientID:  claims.ClientID,
+		Username:  user.Username,
+		TokenType: "Bearer",
+		Exp:       claims.ExpiresAt.Unix(),
+		Iat:       claims.IssuedAt.Unix(),
+		Sub:       strconv.FormatUint(user.ID, 10),
+		Jti:       claims.ID,
+	}, nil
+}
+
+// Revoke follows RFC 7009, invalid or already revoked tokens are not an error
+func (u *oauthUseCase) Revoke(ctx context.Context, client *domain.OAuthClient, token string) error {
+	if !client.HasScope(domain.ScopeRevoke) {
+		return domain.ErrForbidden
+	}
+
+	claims, err := u.tokenService.ValidateToken(token)
+	if err != nil {
+		return nil
+	}
+	// RFC 7009 2.1, tokens of other clients and users are left alone without
+	// telling the caller they exist
+	if claims.ClientID != client.ClientID && !client.HasScope(domain.ScopeRevokeAny) {
+		return nil
+	}
+
+	if err := u.tokenService.BlacklistToken(token); err != nil {
+		return err
+	}
+	u.revocations.Publish(domain.RevocationEvent{TokenHash: hashVerificationToken(token)})
+	u.audit.Record(ctx, domain.AuditEvent{
+		Type:     domain.AuditTokenRevocation,
+		UserID:   claims.UserID,
+		Metadata: map[string]string{"scope": "single_token", "client_id": client.ClientID},
+	})
+
+	return nil
+}
+
+// IssueServiceToken grants the requested scopes, or all of the client's
+// scopes when none are requested
+func (u *oauthUseCase) IssueServiceToken(ctx context.Context, client *domain.OAuthClient, scope string) (*domain.TokenResponse, error) {
+	if !client.ServiceAccount {
+		return nil, &domain.OAuthError{Code: "unauthorized_client", Description: "client is not allowed to use the client_credentials grant"}
+	}
+
+	scopes := strings.Fields(scope)
+	if len(scopes) == 0 {
+		scopes = strings.Fields(client.Scopes)
+	}
+	for _, s := range scopes {
+		if !client.HasScope(s) {
+			return nil, &domain.OAuthError{Code: "invalid_scope", Description: "scope " + s + " is not allowed"}
+		}
+	}
+
+	granted := strings.Join(scopes, " ")
+	token, ttl, err := u.serviceTokenService.GenerateServiceToken(client.ClientID, granted)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(ttl.Seconds()),
+		Scope:       granted,
+	}, nil
+}
+
+// verifyClientSecret checks a secret against the stored hash. Secrets from
+// OAUTH_CLIENTS and SERVICE_ACCOUNTS are chosen by people, so they are hashed
+// like passwords; the unsalted sha256 hashes of earlier versions are still
+// accepted and replaced once the secret matched
+func verifyClientSecret(hasher domain.PasswordHasher, clientRepo domain.OAuthClientRepository, client *domain.OAuthClient, secret string) bool {
+	if isLegacySecretHash(client.SecretHash) {
+		if subtle.ConstantTimeCompare([]byte(hashVerificationToken(secret)), []byte(client.SecretHash)) != 1 {
+			return false
+		}
+	} else if match, err := hasher.Verify(secret, client.SecretHash); err != nil || !match {
+		return false
+	}
+
+	if hasher.NeedsRehash(client.SecretHash) {
+		hashed, err := hasher.Hash(secret)
+		if err != nil {
+			log.Printf("Warning: failed to rehash the secret of OAuth client %q: %v", client.ClientID, err)
+			return true
+		}
+		client.SecretHash = hashed
+		if err := clientRepo.Update(client); err != nil {
+			log.Printf("Warning: failed to rehash the secret of OAuth client %q: %v", client.ClientID, err)
+		}
+	}
+
+	return true
+}
+
+// isLegacySecretHash reports the sha256 hex digests client secrets were
+// stored as before
+func isLegacySecretHash(hash string) bool {
+	if len(hash) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
@@ -0,0 +1,315 @@
+package usecase
+
+import (
+	"auth-service/internal/domain"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const authorizationCodeTTL = 5 * time.Minute
+
+// scopes that can be granted to OIDC clients
+var oidcScopes = []string{domain.ScopeOpenID, domain.ScopeProfile, domain.ScopeEmail}
+
+type oidcUseCase struct {
+	authUseCase    domain.AuthUseCase
+	userRepo       domain.UserRepository
+	tokenService   domain.TokenService
+	idTokenService domain.IDTokenService
+	clientRepo     domain.OAuthClientRepository
+	codeRepo       domain.AuthorizationCodeRepository
+	secretHasher   domain.PasswordHasher
+	audit          domain.AuditLogger
+}
+
+func NewOIDCUseCase(authUseCase domain.AuthUseCase, userRepo domain.UserRepository, tokenService domain.TokenService, idTokenService domain.IDTokenService, clientRepo domain.OAuthClientRepository, codeRepo domain.AuthorizationCodeRepository, secretHasher domain.PasswordHasher, audit domain.AuditLogger) domain.OIDCUseCase {
+	return &oidcUseCase{
+		authUseCase:    authUseCase,
+		userRepo:       userRepo,
+		tokenService:   tokenService,
+		idTokenService: idTokenService,
+		clientRepo:     clientRepo,
+		codeRepo:       codeRepo,
+		secretHasher:   secretHasher,
+		audit:          audit,
+	}
+}
+
+func (u *oidcUseCase) RegisterClient(ctx context.Context, token, name string, redirectURIs []string, public bool) (*domain.OAuthClient, string, error) {
+	admin, err := requireAdmin(ctx, u.authUseCase, token)
+	if err != nil {
+		return nil, "", err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		return nil, "", &domain.ValidationError{Field: "name", Message: "must be between 1 and 100 characters"}
+	}
+	if len(redirectURIs) == 0 {
+		return nil, "", &domain.ValidationError{Field: "redirect_uris", Message: "at least one redirect URI is required"}
+	}
+	for _, uri := range redirectURIs {
+		if !validRedirectURI(uri) {
+			return nil, "", &domain.ValidationError{Field: "redirect_uris", Message: "must be absolute https URLs without fragment (http is allowed for localhost)"}
+		}
+	}
+
+	clientID, _, err := newVerificationToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	client := &domain.OAuthClient{
+		ClientID:     clientID[:32],
+		Name:         name,
+		Scopes:       strings.Join(oidcScopes, " "),
+		RedirectURIs: strings.Join(redirectURIs, " "),
+		Public:       public,
+	}
+
+	var secret string
+	if !public {
+		secret, _, err = newVerificationToken()
+		if err != nil {
+			return nil, "", err
+		}
+		client.SecretHash, err = u.secretHasher.Hash(secret)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := u.clientRepo.Create(client); err != nil {
+		return nil, "", err
+	}
+	u.audit.Record(ctx, domain.AuditEvent{
+		Type:     domain.AuditOAuthClientCreated,
+		ActorID:  admin.ID,
+		Metadata: map[string]string{"client_id": client.ClientID, "name": client.Name},
+	})
+
+	return client, secret, nil
+}
+
+// ValidateAuthorizationRequest checks the /oauth/authorize parameters. When the
+// client or redirect URI is invalid the client is nil and the error must be
+// shown to the user, otherwise it can be sent back to the redirect URI
+func (u *oidcUseCase) ValidateAuthorizationRequest(ctx context.Context, req domain.AuthorizationRequest) (*domain.OAuthClient, error) {
+	client, err := u.clientRepo.FindByClientID(req.ClientID)
+	if err != nil {
+		if errors.Is(err, domain.ErrClientNotFound) {
+			return nil, &domain.OAuthError{Code: "invalid_request", Description: "unknown client_id"}
+		}
+		return nil, err
+	}
+	if !client.HasRedirectURI(req.RedirectURI) {
+		return nil, &domain.OAuthError{Code: "invalid_request", Description: "redirect_uri is not registered for this client"}
+	}
+
+	if req.ResponseType != "code" {
+		return client, &domain.OAuthError{Code: "unsupported_response_type", Description: "only the code response type is supported"}
+	}
+
+	scopes := strings.Fields(req.Scope)
+	if !containsScope(scopes, domain.ScopeOpenID) {
+		return client, &domain.OAuthError{Code: "invalid_scope", Description: "the openid scope is required"}
+	}
+	for _, scope := range scopes {
+		if !containsScope(oidcScopes, scope) || !client.HasScope(scope) {
+			return client, &domain.OAuthError{Code: "invalid_scope", Description: "scope " + scope + " is not allowed"}
+		}
+	}
+
+	// PKCE is mandatory for every client, plain is not accepted
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != "S256" {
+		return client, &domain.OAuthError{Code: "invalid_request", Description: "a S256 code_challenge is required"}
+	}
+
+	return client, nil
+}
+
+func (u *oidcUseCase) Authorize(ctx context.Context, req domain.AuthorizationRequest, username, password string) (string, error) {
+	client, err := u.ValidateAuthorizationRequest(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	user, err := u.authUseCase.Authenticate(ctx, username, password)
+	if err != nil {
+		return "", err
+	}
+
+	code, codeHash, err := newVerificationToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = u.codeRepo.Create(&domain.AuthorizationCode{
+		CodeHash:            codeHash,
+		ClientID:            client.ClientID,
+		UserID:              user.ID,
+		RedirectURI:         req.RedirectURI,
+		Scope:               strings.Join(strings.Fields(req.Scope), " "),
+		Nonce:               req.Nonce,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		ExpiresAt:           time.Now().Add(authorizationCodeTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+	u.audit.Record(ctx, domain.AuditEvent{
+		Type:     domain.AuditOAuthAuthorize,
+		UserID:   user.ID,
+		Metadata: map[string]string{"client_id": client.ClientID, "scope": req.Scope},
+	})
+
+	return code, nil
+}
+
+func (u *oidcUseCase) ExchangeCode(ctx context.Context, req domain.TokenExchange) (*domain.TokenResponse, error) {
+	client, err := u.clientRepo.FindByClientID(req.ClientID)
+	if err != nil {
+		if errors.Is(err, domain.ErrClientNotFound) {
+			return nil, domain.ErrInvalidClient
+		}
+		return nil, err
+	}
+
+	if client.Public {
+		if req.ClientSecret != "" {
+			return nil, domain.ErrInvalidClient
+		}
+	} else if !verifyClientSecret(u.secretHasher, u.clientRepo, client, req.ClientSecret) {
+		return nil, domain.ErrInvalidClient
+	}
+
+	code, err := u.codeRepo.Consume(hashVerificationToken(req.Code))
+	if err != nil {
+		if errors.Is(err, domain.ErrAuthorizationCodeNotFound) {
+			return nil, &domain.OAuthError{Code: "invalid_grant", Description: err.Error()}
+		}
+		return nil, err
+	}
+
+	if code.ClientID != client.ClientID || code.RedirectURI != req.RedirectURI {
+		return nil, &domain.OAuthError{Code: "invalid_grant", Description: "authorization code was issued to another client or redirect_uri"}
+	}
+	if !verifyCodeChallenge(req.CodeVerifier, code.CodeChallenge) {
+		return nil, &domain.OAuthError{Code: "invalid_grant", Description: "code_verifier does not match the code_challenge"}
+	}
+
+	user, err := u.userRepo.FindByID(code.UserID)
+	if err != nil || user.IsDisabled() {
+		return nil, &domain.OAuthError{Code: "invalid_grant", Description: "user is no longer active"}
+	}
+
+	accessToken, err := u.tokenService.GenerateToken(domain.TokenRequest{
+		UserID:   user.ID,
+		Scope:    code.Scope,
+		ClientID: client.ClientID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	claims, err := u.tokenService.ValidateToken(accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &domain.TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(time.Until(claims.ExpiresAt).Seconds()),
+		Scope:       code.Scope,
+	}
+
+	if containsScope(strings.Fields(code.Scope), domain.ScopeOpenID) {
+		resp.IDToken, err = u.idTokenService.GenerateIDToken(domain.IDTokenRequest{
+			User:     user,
+			ClientID: client.ClientID,
+			Scope:    code.Scope,
+			Nonce:    code.Nonce,
+			AuthTime: code.CreatedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
+
+func (u *oidcUseCase) UserInfo(ctx context.Context, token string) (*domain.UserInfo, error) {
+	user, claims, err := u.authUseCase.ValidateTokenClaims(ctx, token)
+	if err != nil {
+		if errors.Is(err, domain.ErrRevocationUnavailable) {
+			return nil, err
+		}
+		return nil, domain.ErrInvalidToken
+	}
+
+	scopes := strings.Fields(claims.Scope)
+	if !containsScope(scopes, domain.ScopeOpenID) {
+		return nil, domain.ErrForbidden
+	}
+
+	info := &domain.UserInfo{Sub: strconv.FormatUint(user.ID, 10)}
+	if containsScope(scopes, domain.ScopeProfile) {
+		info.PreferredUsername = user.Username
+		info.Name = user.DisplayName
+		info.Picture = user.AvatarURL
+		info.Locale = user.Locale
+		info.UpdatedAt = user.UpdatedAt.Unix()
+	}
+	if containsScope(scopes, domain.ScopeEmail) {
+		info.Email = user.Email
+	}
+
+	return info, nil
+}
+
+// verifyCodeChallenge checks a PKCE verifier against its S256 challenge
+func verifyCodeChallenge(verifier, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+func validRedirectURI(uri string) bool {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Host == "" || parsed.Fragment != "" {
+		return false
+	}
+
+	switch parsed.Scheme {
+	case "https":
+		return true
+	case "http":
+		host := parsed.Hostname()
+		return host == "localhost" || host == "127.0.0.1"
+	}
+
+	return false
+}
+
+func containsScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestVerifyCodeChallenge(t *testing.T) {
+	s256 := func(verifier string) string {
+		sum := sha256.Sum256([]byte(verifier))
+		return base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+	// RFC 7636 appendix B
+	const (
+		rfcVerifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+		rfcChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	)
+	rfcSum := sha256.Sum256([]byte(rfcVerifier))
+	shortest := strings.Repeat("a", 43)
+	longest := strings.Repeat("a", 128)
+
+	tests := []struct {
+		name      string
+		verifier  string
+		challenge string
+		want      bool
+	}{
+		{"rfc example", rfcVerifier, rfcChallenge, true},
+		{"shortest verifier", shortest, s256(shortest), true},
+		{"longest verifier", longest, s256(longest), true},
+		{"verifier too short", shortest[1:], s256(shortest[1:]), false},
+		{"verifier too long", longest + "a", s256(longest + "a"), false},
+		{"wrong verifier", shortest, rfcChallenge, false},
+		{"padded challenge", rfcVerifier, rfcChallenge + "=", false},
+		{"standard base64 challenge", rfcVerifier, base64.StdEncoding.EncodeToString(rfcSum[:]), false},
+		{"plain challenge", rfcVerifier, rfcVerifier, false},
+		{"empty challenge", rfcVerifier, "", false},
+		{"empty verifier", "", s256(""), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyCodeChallenge(tt.verifier, tt.challenge); got != tt.want {
+				t.Errorf("verifyCodeChallenge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
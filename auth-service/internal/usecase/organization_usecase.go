@@ -0,0 +1,292 @@
+package usecase
+
+import (
+	"auth-service/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const invitationTTL = 7 * 24 * time.Hour
+
+type organizationUseCase struct {
+	authUseCase  domain.AuthUseCase
+	orgRepo      domain.OrganizationRepository
+	tokenService domain.TokenService
+	mailer       domain.Mailer
+	audit        domain.AuditLogger
+	revocations  domain.RevocationNotifier
+	appBaseURL   string // used to build invitation links
+}
+
+func NewOrganizationUseCase(authUseCase domain.AuthUseCase, orgRepo domain.OrganizationRepository, tokenService domain.TokenService, mailer domain.Mailer, audit domain.AuditLogger, revocations domain.RevocationNotifier, appBaseURL string) domain.OrganizationUseCase {
+	return &organizationUseCase{
+		authUseCase:  authUseCase,
+		orgRepo:      orgRepo,
+		tokenService: tokenService,
+		mailer:       mailer,
+		audit:        audit,
+		revocations:  revocations,
+		appBaseURL:   appBaseURL,
+	}
+}
+
+func (u *organizationUseCase) CreateOrganization(ctx context.Context, token, name string) (*domain.Organization, string, error) {
+	user, err := authenticateOwner(ctx, u.authUseCase, token)
+	if err != nil {
+		return nil, "", err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > 100 {
+		return nil, "", &domain.ValidationError{Field: "name", Message: "must be between 1 and 100 characters"}
+	}
+
+	org := &domain.Organization{Name: name, CreatedBy: user.ID}
+	if err := u.orgRepo.Create(org, user.ID); err != nil {
+		return nil, "", err
+	}
+	u.audit.Record(ctx, domain.AuditEvent{
+		Type:     domain.AuditOrgCreated,
+		UserID:   user.ID,
+		Metadata: map[string]string{"organization_id": strconv.FormatUint(org.ID, 10)},
+	})
+
+	newToken, err := u.tokenService.GenerateToken(domain.TokenRequest{
+		UserID:         user.ID,
+		Role:           user.Role,
+		OrganizationID: org.ID,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return org, newToken, nil
+}
+
+func (u *organizationUseCase) ListOrganizations(ctx context.Context, token string) ([]domain.Membership, error) {
+	user, err := authenticate(ctx, u.authUseCase, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.orgRepo.ListMemberships(user.ID)
+}
+
+// SwitchOrganization keeps the actor and expiry of an impersonation token
+func (u *organizationUseCase) SwitchOrganization(ctx context.Context, token string, orgID uint64) (string, error) {
+	user, claims, err := authenticateSession(ctx, u.authUseCase, token)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := u.orgRepo.FindMembership(orgID, user.ID); err != nil {
+		return "", err
+	}
+
+	req := domain.TokenRequest{
+		UserID:         user.ID,
+		Role:           user.Role,
+		OrganizationID: orgID,
+	}
+	if claims.IsImpersonation() {
+		req.ActorID = claims.ActorID
+		req.ExpiresIn = time.Until(claims.ExpiresAt)
+	}
+
+	return u.tokenService.GenerateToken(req)
+}
+
+func (u *organizationUseCase) ListMembers(ctx context.Context, token string, orgID uint64) ([]domain.Membership, error) {
+	user, err := authenticate(ctx, u.authUseCase, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := u.orgRepo.FindMembership(orgID, user.ID); err != nil {
+		return nil, err
+	}
+
+	return u.orgRepo.ListMembers(orgID)
+}
+
+func (u *organizationUseCase) InviteMember(ctx context.Context, token string, orgID uint64, email, role string) (*domain.Invitation, error) {
+	user, err := authenticate(ctx, u.authUseCase, token)
+	if err != nil {
+		return nil, err
+	}
+
+	membership, err := u.orgRepo.FindMembership(orgID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !membership.CanManageMembers() {
+		return nil, domain.ErrForbidden
+	}
+
+	// ownership is not handed out by invitation
+	if role == "" {
+		role = domain.OrgRoleMember
+	}
+	if role != domain.OrgRoleMember && role != domain.OrgRoleAdmin {
+		return nil, domain.ErrInvalidRole
+	}
+
+	address, err := mail.ParseAddress(email)
+	if err != nil {
+		return nil, &domain.ValidationError{Field: "email", Message: "must be a valid email address"}
+	}
+
+	invitationToken, tokenHash, err := newVerificationToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := &domain.Invitation{
+		OrganizationID: orgID,
+		Email:          address.Address,
+		Role:           role,
+		TokenHash:      tokenHash,
+		InvitedBy:      user.ID,
+		ExpiresAt:      time.Now().Add(invitationTTL),
+	}
+	if err := u.orgRepo.CreateInvitation(invitation); err != nil {
+		return nil, err
+	}
+	u.audit.Record(ctx, domain.AuditEvent{
+		Type:   domain.AuditOrgMemberInvited,
+		UserID: user.ID,
+		Metadata: map[string]string{
+			"organization_id": strconv.FormatUint(orgID, 10),
+			"invitation_id":   strconv.FormatUint(invitation.ID, 10),
+			"role":            role,
+		},
+	})
+
+	link := fmt.Sprintf("%s/orgs/invitations/accept?token=%s", u.appBaseURL, invitationToken)
+	err = u.mailer.Send(domain.Mail{
+		To:      invitation.Email,
+		Subject: "You have been invited to " + membership.Organization.Name,
+		Body: fmt.Sprintf("Hi,\n\n%s invited you to join %s. Sign in with this email address and open this link to accept:\n%s\n\nThe link expires in 7 days.",
+			user.Username, membership.Organization.Name, link),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+func (u *organizationUseCase) AcceptInvitation(ctx context.Context, token, invitationToken string) (*domain.Membership, error) {
+	user, err := authenticate(ctx, u.authUseCase, token)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation, err := u.orgRepo.FindInvitationByHash(hashVerificationToken(invitationToken))
+	if err != nil {
+		return nil, err
+	}
+
+	// a leaked link is useless without access to the invited address
+	if !strings.EqualFold(invitation.Email, user.Email) {
+		return nil, domain.ErrInvitationNotFound
+	}
+
+	if _, err := u.orgRepo.FindMembership(invitation.OrganizationID, user.ID); err == nil {
+		return nil, domain.ErrAlreadyMember
+	}
+
+	membership := &domain.Membership{
+		OrganizationID: invitation.OrganizationID,
+		UserID:         user.ID,
+		Role:           invitation.Role,
+	}
+	if err := u.orgRepo.AcceptInvitation(invitation, membership); err != nil {
+		return nil, err
+	}
+	u.audit.Record(ctx, domain.AuditEvent{
+		Type:     domain.AuditOrgMemberJoined,
+		UserID:   user.ID,
+		ActorID:  invitation.InvitedBy,
+		Metadata: map[string]string{"organization_id": strconv.FormatUint(invitation.OrganizationID, 10), "role": invitation.Role},
+	})
+
+	return membership, nil
+}
+
+func (u *organizationUseCase) RemoveMember(ctx context.Context, token string, orgID, userID uint64) error {
+	user, err := authenticate(ctx, u.authUseCase, token)
+	if err != nil {
+		return err
+	}
+
+	caller, err := u.orgRepo.FindMembership(orgID, user.ID)
+	if err != nil {
+		return err
+	}
+	if userID != user.ID && !caller.CanManageMembers() {
+		return domain.ErrForbidden
+	}
+
+	target, err := u.orgRepo.FindMembership(orgID, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrOrganizationNotFound) {
+			return domain.ErrUserNotFound
+		}
+		return err
+	}
+	// the owner can't leave or be removed, the organization would be orphaned
+	if target.Role == domain.OrgRoleOwner {
+		return domain.ErrForbidden
+	}
+	// admins can only be removed by the owner
+	if target.Role == domain.OrgRoleAdmin && userID != user.ID && caller.Role != domain.OrgRoleOwner {
+		return domain.ErrForbidden
+	}
+
+	if err := u.orgRepo.RemoveMember(orgID, userID); err != nil {
+		return err
+	}
+	u.audit.Record(ctx, domain.AuditEvent{
+		Type:     domain.AuditOrgMemberRemoved,
+		UserID:   userID,
+		ActorID:  user.ID,
+		Metadata: map[string]string{"organization_id": strconv.FormatUint(orgID, 10)},
+	})
+	// cached validations still carry the organization
+	u.revocations.Publish(domain.RevocationEvent{UserID: userID})
+
+	return nil
+}
+
+func (u *organizationUseCase) ActiveMembership(ctx context.Context, userID, orgID uint64) (*domain.Membership, error) {
+	if orgID == 0 {
+		return nil, domain.ErrOrganizationNotFound
+	}
+
+	return u.orgRepo.FindMembership(orgID, userID)
+}
+
+// defaultOrganization picks the organization a new session starts in, the
+// oldest membership, and creates a personal one for users without any
+func defaultOrganization(orgRepo domain.OrganizationRepository, user *domain.User) (uint64, error) {
+	memberships, err := orgRepo.ListMemberships(user.ID)
+	if err != nil {
+		return 0, err
+	}
+	if len(memberships) > 0 {
+		return memberships[0].OrganizationID, nil
+	}
+
+	org := &domain.Organization{Name: user.Username + "'s workspace", CreatedBy: user.ID}
+	if err := orgRepo.Create(org, user.ID); err != nil {
+		return 0, err
+	}
+
+	return org.ID, nil
+}
@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"auth-service/internal/domain"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+type privacyUseCase struct {
+	authUseCase     domain.AuthUseCase
+	userRepo        domain.UserRepository
+	orgRepo         domain.OrganizationRepository
+	apiKeyRepo      domain.APIKeyRepository
+	auditRepo       domain.AuditRepository
+	userEventRepo   domain.UserEventRepository
+	passwordHasher  domain.PasswordHasher
+	tokenService    domain.TokenService
+	productExporter domain.ProductExporter
+	audit           domain.AuditLogger
+	revocations     domain.RevocationNotifier
+	// erased accounts are kept anonymized this long before they are purged
+	gracePeriod time.Duration
+}
+
+func NewPrivacyUseCase(authUseCase domain.AuthUseCase, userRepo domain.UserRepository, orgRepo domain.OrganizationRepository, apiKeyRepo domain.APIKeyRepository, auditRepo domain.AuditRepository, userEventRepo domain.UserEventRepository, passwordHasher domain.PasswordHasher, tokenService domain.TokenService, productExporter domain.ProductExporter, audit domain.AuditLogger, revocations domain.RevocationNotifier, gracePeriod time.Duration) domain.PrivacyUseCase {
+	return &privacyUseCase{
+		authUseCase:     authUseCase,
+		userRepo:        userRepo,
+		orgRepo:         orgRepo,
+		apiKeyRepo:      apiKeyRepo,
+		auditRepo:       auditRepo,
+		userEventRepo:   userEventRepo,
+		passwordHasher:  passwordHasher,
+		tokenService:    tokenService,
+		productExporter: productExporter,
+		audit:           audit,
+		revocations:     revocations,
+		gracePeriod:     gracePeriod,
+	}
+}
+
+func (u *privacyUseCase) ExportMyData(ctx context.Context, token string) ([]byte, error) {
+	user, err := authenticateOwner(ctx, u.authUseCase, token)
+	if err != nil {
+		return nil, err
+	}
+
+	export := domain.DataExport{
+		ExportedAt: time.Now().UTC(),
+		Account:    user,
+	}
+
+	if export.Organizations, err = u.orgRepo.ListMemberships(user.ID); err != nil {
+		return nil, err
+	}
+	if export.APIKeys, err = u.apiKeyRepo.ListByUser(user.ID); err != nil {
+		return nil, err
+	}
+
+	// events about the user and events the user caused for others
+	seen := make(map[uint64]bool)
+	collect := func(event *domain.AuditEvent) error {
+		if !seen[event.ID] {
+			seen[event.ID] = true
+			export.AuditEvents = append(export.AuditEvents, *event)
+		}
+		return nil
+	}
+	if err := u.auditRepo.Each(domain.AuditFilter{UserID: user.ID}, collect); err != nil {
+		return nil, err
+	}
+	if err := u.auditRepo.Each(domain.AuditFilter{ActorID: user.ID}, collect); err != nil {
+		return nil, err
+	}
+
+	if export.Products, err = u.productExporter.ExportProducts(ctx, token); err != nil {
+		return nil, fmt.Errorf("export products: %w", err)
+	}
+
+	archive, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	u.audit.Record(ctx, domain.AuditEvent{Type: domain.AuditDataExported, UserID: user.ID})
+
+	return archive, nil
+}
+
+func (u *privacyUseCase) EraseMyAccount(ctx context.Context, token, password string) (time.Time, error) {
+	user, err := authenticateOwner(ctx, u.authUseCase, token)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if match, err := u.passwordHasher.Verify(password, user.Password); err != nil || !match {
+		return time.Time{}, domain.ErrIncorrectPassword
+	}
+
+	// an owned organization would be left without anyone able to manage it
+	memberships, err := u.orgRepo.ListMemberships(user.ID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, membership := range memberships {
+		if membership.Role != domain.OrgRoleOwner {
+			continue
+		}
+		members, err := u.orgRepo.ListMembers(membership.OrganizationID)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if len(members) > 1 {
+			return time.Time{}, domain.ErrOwnsSharedOrganization
+		}
+	}
+
+	now := time.Now()
+	user.Username = fmt.Sprintf("erased-%d", user.ID)
+	user.Email = fmt.Sprintf("erased-%d@erased.invalid", user.ID)
+	user.Password = ""
+	user.DisplayName = ""
+	user.AvatarURL = ""
+	user.Locale = ""
+	user.PendingEmail = ""
+	user.EmailChangeTokenHash = ""
+	user.EmailChangeExpiresAt = nil
+	user.ErasedAt = &now
+
+	if err := u.userRepo.Erase(user); err != nil {
+		return time.Time{}, err
+	}
+	u.audit.Record(ctx, domain.AuditEvent{Type: domain.AuditAccountErased, UserID: user.ID})
+	u.revocations.Publish(domain.RevocationEvent{UserID: user.ID})
+
+	if err := u.tokenService.BlacklistToken(token); err != nil {
+		log.Printf("Failed to revoke token of erased user %d: %v", user.ID, err)
+	}
+
+	return now.Add(u.gracePeriod), nil
+}
+
+func (u *privacyUseCase) PurgeErased(ctx context.Context) (int, error) {
+	// audit events are kept, without anything that identifies the person
+	ids, err := u.userRepo.PurgeErased(time.Now().Add(-u.gracePeriod))
+	if err != nil {
+		return 0, err
+	}
+
+	return len(ids), nil
+}
+
+func (u *privacyUseCase) UserEvents(ctx context.Context, afterID uint64, limit int) ([]domain.UserEvent, error) {
+	return u.userEventRepo.ListAfter(afterID, limit)
+}
@@ -0,0 +1,271 @@
+package usecase
+
+import (
+	"auth-service/internal/domain"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+const emailChangeTTL = 24 * time.Hour
+
+func (a *authUseCase) GetProfile(ctx context.Context, token string) (*domain.User, error) {
+	return authenticate(ctx, a, token)
+}
+
+func (a *authUseCase) UpdateProfile(ctx context.Context, token string, update domain.ProfileUpdate) (*domain.User, error) {
+	user, err := authenticate(ctx, a, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.DisplayName != nil {
+		displayName := strings.TrimSpace(*update.DisplayName)
+		if len([]rune(displayName)) > 100 {
+			return nil, &domain.ValidationError{Field: "display_name", Message: "must be at most 100 characters"}
+		}
+		user.DisplayName = displayName
+	}
+
+	if update.AvatarURL != nil {
+		avatarURL := strings.TrimSpace(*update.AvatarURL)
+		if avatarURL != "" {
+			parsed, err := url.Parse(avatarURL)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return nil, &domain.ValidationError{Field: "avatar_url", Message: "must be an absolute http or https URL"}
+			}
+			if len(avatarURL) > 500 {
+				return nil, &domain.ValidationError{Field: "avatar_url", Message: "must be at most 500 characters"}
+			}
+		}
+		user.AvatarURL = avatarURL
+	}
+
+	if update.Locale != nil {
+		locale := strings.TrimSpace(*update.Locale)
+		if locale != "" {
+			tag, err := language.Parse(locale)
+			if err != nil {
+				return nil, &domain.ValidationError{Field: "locale", Message: "must be a BCP 47 language tag"}
+			}
+			locale = tag.String()
+		}
+		user.Locale = locale
+	}
+
+	if err := a.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (a *authUseCase) ChangePassword(ctx context.Context, token, currentPassword, newPassword string) error {
+	user, err := authenticateOwner(ctx, a, token)
+	if err != nil {
+		return err
+	}
+
+	if match, err := a.passwordHasher.Verify(currentPassword, user.Password); err != nil || !match {
+		return domain.ErrIncorrectPassword
+	}
+
+	if violations := a.passwordPolicy.Validate(newPassword, user.Username, user.Email); len(violations) > 0 {
+		return &domain.PasswordPolicyError{Violations: violations}
+	}
+
+	hashedPassword, err := a.passwordHasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+
+	user.Password = hashedPassword
+	if err := a.userRepo.Update(user); err != nil {
+		return err
+	}
+	a.audit.Record(ctx, domain.AuditEvent{Type: domain.AuditPasswordChange, UserID: user.ID})
+
+	return nil
+}
+
+// ChangeEmail stores the new address as pending and sends a verification
+// link to it, the email is only replaced once the link is confirmed
+func (a *authUseCase) ChangeEmail(ctx context.Context, token, newEmail, password string) error {
+	user, err := authenticateOwner(ctx, a, token)
+	if err != nil {
+		return err
+	}
+
+	if match, err := a.passwordHasher.Verify(password, user.Password); err != nil || !match {
+		return domain.ErrIncorrectPassword
+	}
+
+	address, err := mail.ParseAddress(newEmail)
+	if err != nil || address.Address != strings.TrimSpace(newEmail) {
+		return &domain.ValidationError{Field: "new_email", Message: "must be a valid email address"}
+	}
+	if strings.EqualFold(address.Address, user.Email) {
+		return &domain.ValidationError{Field: "new_email", Message: "must be different from the current email"}
+	}
+
+	if _, err := a.userRepo.FindByEmail(address.Address); err == nil {
+		return domain.ErrEmailTaken
+	}
+
+	verificationToken, tokenHash, err := newVerificationToken()
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(emailChangeTTL)
+	user.PendingEmail = address.Address
+	user.EmailChangeTokenHash = tokenHash
+	user.EmailChangeExpiresAt = &expiresAt
+
+	if err := a.userRepo.Update(user); err != nil {
+		return err
+	}
+	a.audit.Record(ctx, domain.AuditEvent{
+		Type:     domain.AuditEmailChangeRequest,
+		UserID:   user.ID,
+		Metadata: map[string]string{"new_email_index": a.identityIndex(address.Address)},
+	})
+
+	link := fmt.Sprintf("%s/auth/email/confirm?token=%s", a.appBaseURL, verificationToken)
+	return a.mailer.Send(domain.Mail{
+		To:      address.Address,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf("Hi %s,\n\nConfirm your new email address by opening this link:\n%s\n\nThe link expires in 24 hours. If you did not request this change, ignore this email.",
+			user.Username, link),
+	})
+}
+
+func (a *authUseCase) ConfirmEmailChange(ctx context.Context, verificationToken string) (*domain.User, error) {
+	user, err := a.userRepo.FindByEmailChangeToken(hashVerificationToken(verificationToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, domain.ErrInvalidVerification
+		}
+		return nil, err
+	}
+
+	if user.EmailChangeExpiresAt == nil || time.Now().After(*user.EmailChangeExpiresAt) || user.PendingEmail == "" {
+		return nil, domain.ErrInvalidVerification
+	}
+
+	// the address may have been taken while the link was pending
+	if existing, err := a.userRepo.FindByEmail(user.PendingEmail); err == nil && existing.ID != user.ID {
+		return nil, domain.ErrEmailTaken
+	}
+
+	oldEmail := user.Email
+	user.Email = user.PendingEmail
+	user.PendingEmail = ""
+	user.EmailChangeTokenHash = ""
+	user.EmailChangeExpiresAt = nil
+
+	if err := a.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	a.audit.Record(ctx, domain.AuditEvent{
+		Type:     domain.AuditEmailChange,
+		UserID:   user.ID,
+		Metadata: map[string]string{"old_email_index": a.identityIndex(oldEmail), "new_email_index": a.identityIndex(user.Email)},
+	})
+
+	return user, nil
+}
+
+func (a *authUseCase) DeleteAccount(ctx context.Context, token, password string) error {
+	user, err := authenticateOwner(ctx, a, token)
+	if err != nil {
+		return err
+	}
+
+	if match, err := a.passwordHasher.Verify(password, user.Password); err != nil || !match {
+		return domain.ErrIncorrectPassword
+	}
+
+	if err := a.userRepo.Delete(user.ID); err != nil {
+		return err
+	}
+	a.audit.Record(ctx, domain.AuditEvent{Type: domain.AuditAccountDeleted, UserID: user.ID})
+	a.revocations.Publish(domain.RevocationEvent{UserID: user.ID})
+
+	if err := a.tokenService.BlacklistToken(token); err != nil {
+		log.Printf("Failed to revoke token of deleted user %d: %v", user.ID, err)
+	}
+
+	return nil
+}
+
+// authenticate resolves the token owner, hiding token parsing details
+func authenticate(ctx context.Context, authUseCase domain.AuthUseCase, token string) (*domain.User, error) {
+	user, err := authUseCase.ValidateToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, domain.ErrRevocationUnavailable) || errors.Is(err, domain.ErrAccountDisabled) {
+			return nil, err
+		}
+		return nil, domain.ErrInvalidToken
+	}
+
+	return user, nil
+}
+
+// authenticateSession is authenticate for calls that also need the claims,
+// e.g. the active organization
+func authenticateSession(ctx context.Context, authUseCase domain.AuthUseCase, token string) (*domain.User, *domain.TokenClaims, error) {
+	user, claims, err := authUseCase.ValidateTokenClaims(ctx, token)
+	if err != nil {
+		if errors.Is(err, domain.ErrRevocationUnavailable) || errors.Is(err, domain.ErrAccountDisabled) {
+			return nil, nil, err
+		}
+		return nil, nil, domain.ErrInvalidToken
+	}
+
+	// tokens issued to OAuth clients are not first-party sessions
+	if claims.ClientID != "" {
+		return nil, nil, domain.ErrInvalidToken
+	}
+
+	return user, claims, nil
+}
+
+// authenticateOwner is authenticate for sensitive actions, an admin
+// impersonating the user can't take them
+func authenticateOwner(ctx context.Context, authUseCase domain.AuthUseCase, token string) (*domain.User, error) {
+	user, claims, err := authenticateSession(ctx, authUseCase, token)
+	if err != nil {
+		return nil, err
+	}
+	if claims.IsImpersonation() {
+		return nil, domain.ErrImpersonationForbidden
+	}
+
+	return user, nil
+}
+
+func newVerificationToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	token := hex.EncodeToString(b)
+	return token, hashVerificationToken(token), nil
+}
+
+func hashVerificationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
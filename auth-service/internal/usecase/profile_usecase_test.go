@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"auth-service/internal/domain"
+	"auth-service/internal/service"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type fakeTokenService struct {
+	domain.TokenService
+	claims *domain.TokenClaims
+}
+
+func (s *fakeTokenService) ValidateToken(token string) (*domain.TokenClaims, error) {
+	return s.claims, nil
+}
+
+func (s *fakeTokenService) IsTokenBlacklisted(claims *domain.TokenClaims) (bool, error) {
+	return false, nil
+}
+
+type fakeUserRepository struct {
+	domain.UserRepository
+	user    *domain.User
+	updates int
+}
+
+func (r *fakeUserRepository) FindByID(id uint64) (*domain.User, error) {
+	if id != r.user.ID {
+		return nil, domain.ErrUserNotFound
+	}
+	copied := *r.user
+	return &copied, nil
+}
+
+func (r *fakeUserRepository) Update(user *domain.User) error {
+	r.updates++
+	r.user = user
+	return nil
+}
+
+// fakeHasher stores passwords with a prefix, hashing is not under test
+type fakeHasher struct{}
+
+func (fakeHasher) Hash(password string) (string, error) { return "hashed:" + password, nil }
+
+func (fakeHasher) Verify(password, encodedHash string) (bool, error) {
+	return encodedHash == "hashed:"+password, nil
+}
+
+func (fakeHasher) NeedsRehash(encodedHash string) bool { return false }
+
+type fakeAuditLogger struct{ events []domain.AuditEvent }
+
+func (l *fakeAuditLogger) Record(ctx context.Context, event domain.AuditEvent) {
+	l.events = append(l.events, event)
+}
+
+func TestChangePasswordPolicy(t *testing.T) {
+	config := service.DefaultPasswordPolicyConfig()
+	config.RequireDigit = true
+	policy := service.NewPasswordPolicy(config, map[string]struct{}{"password": {}}, nil)
+
+	tests := []struct {
+		name           string
+		current        string
+		newPassword    string
+		wantErr        error
+		wantViolations []string
+	}{
+		{"valid", "old-secret-1", "new-secret-2", nil, nil},
+		{"wrong current password", "wrong", "new-secret-2", domain.ErrIncorrectPassword, nil},
+		{"too short", "old-secret-1", "short1", nil, []string{service.ViolationTooShort}},
+		{"missing digit", "old-secret-1", "new-secret", nil, []string{service.ViolationMissingDigit}},
+		{"similar to the username", "old-secret-1", "alice-secret-2", nil, []string{service.ViolationSimilarUsername}},
+		{"dictionary word", "old-secret-1", "password123", nil, []string{service.ViolationDictionaryWord}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUserRepository{user: &domain.User{ID: 1, Username: "alice", Email: "alice@example.com", Password: "hashed:old-secret-1"}}
+			audit := &fakeAuditLogger{}
+			tokens := &fakeTokenService{claims: &domain.TokenClaims{UserID: 1}}
+			auth := NewAuthUseCase(users, nil, nil, tokens, fakeHasher{}, policy, nil, audit, nil, nil, domain.RegistrationOpen, "")
+
+			err := auth.ChangePassword(context.Background(), "token", tt.current, tt.newPassword)
+
+			var policyErr *domain.PasswordPolicyError
+			switch {
+			case tt.wantViolations != nil:
+				if !errors.As(err, &policyErr) {
+					t.Fatalf("ChangePassword() error = %v, want a PasswordPolicyError", err)
+				}
+				var got []string
+				for _, violation := range policyErr.Violations {
+					got = append(got, violation.Code)
+				}
+				if !reflect.DeepEqual(got, tt.wantViolations) {
+					t.Errorf("violations = %v, want %v", got, tt.wantViolations)
+				}
+			case !errors.Is(err, tt.wantErr):
+				t.Fatalf("ChangePassword() error = %v, want %v", err, tt.wantErr)
+			}
+
+			changed := tt.wantErr == nil && tt.wantViolations == nil
+			if changed != (users.updates == 1) {
+				t.Errorf("password stored = %v, want %v", users.updates == 1, changed)
+			}
+			if changed && users.user.Password != "hashed:"+tt.newPassword {
+				t.Errorf("stored hash = %q, want the new password", users.user.Password)
+			}
+			if changed != (len(audit.events) == 1) {
+				t.Errorf("audit events = %v, want one only on success", audit.events)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"auth-service/internal/domain"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+const (
+	inviteCodePrefix      = "inv_"
+	defaultInviteLifetime = 7 // days
+	maxInviteLifetime     = 90
+	maxInviteUses         = 1000
+)
+
+type registrationInviteUseCase struct {
+	authUseCase domain.AuthUseCase
+	inviteRepo  domain.RegistrationInviteRepository
+	audit       domain.AuditLogger
+}
+
+func NewRegistrationInviteUseCase(authUseCase domain.AuthUseCase, inviteRepo domain.RegistrationInviteRepository, audit domain.AuditLogger) domain.RegistrationInviteUseCase {
+	return &registrationInviteUseCase{
+		authUseCase: authUseCase,
+		inviteRepo:  inviteRepo,
+		audit:       audit,
+	}
+}
+
+func (u *registrationInviteUseCase) CreateInvite(ctx context.Context, token, role string, maxUses, expiresInDays int) (*domain.RegistrationInvite, string, error) {
+	admin, err := requireAdmin(ctx, u.authUseCase, token)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if role == "" {
+		role = domain.RoleUser
+	}
+	if role != domain.RoleUser && role != domain.RoleAdmin {
+		return nil, "", domain.ErrInvalidRole
+	}
+
+	if maxUses == 0 {
+		maxUses = 1
+	}
+	if maxUses < 1 || maxUses > maxInviteUses {
+		return nil, "", &domain.ValidationError{Field: "max_uses", Message: "must be between 1 and " + strconv.Itoa(maxInviteUses)}
+	}
+
+	if expiresInDays == 0 {
+		expiresInDays = defaultInviteLifetime
+	}
+	if expiresInDays < 1 || expiresInDays > maxInviteLifetime {
+		return nil, "", &domain.ValidationError{Field: "expires_in_days", Message: "must be between 1 and " + strconv.Itoa(maxInviteLifetime)}
+	}
+
+	code, err := newInviteCode()
+	if err != nil {
+		return nil, "", err
+	}
+
+	invite := &domain.RegistrationInvite{
+		Prefix:    code[:len(inviteCodePrefix)+8],
+		CodeHash:  hashVerificationToken(code),
+		Role:      role,
+		MaxUses:   maxUses,
+		ExpiresAt: time.Now().Add(time.Duration(expiresInDays) * 24 * time.Hour),
+		CreatedBy: admin.ID,
+	}
+	if err := u.inviteRepo.Create(invite); err != nil {
+		return nil, "", err
+	}
+	u.audit.Record(ctx, domain.AuditEvent{
+		Type:    domain.AuditInviteCreated,
+		ActorID: admin.ID,
+		Metadata: map[string]string{
+			"invite_id": strconv.FormatUint(invite.ID, 10),
+			"role":      role,
+			"max_uses":  strconv.Itoa(maxUses),
+		},
+	})
+
+	return invite, code, nil
+}
+
+func (u *registrationInviteUseCase) ListInvites(ctx context.Context, token string) ([]domain.RegistrationInvite, error) {
+	if _, err := requireAdmin(ctx, u.authUseCase, token); err != nil {
+		return nil, err
+	}
+
+	return u.inviteRepo.List()
+}
+
+func (u *registrationInviteUseCase) RevokeInvite(ctx context.Context, token string, id uint64) error {
+	admin, err := requireAdmin(ctx, u.authUseCase, token)
+	if err != nil {
+		return err
+	}
+
+	if err := u.inviteRepo.Revoke(id); err != nil {
+		return err
+	}
+	u.audit.Record(ctx, domain.AuditEvent{
+		Type:     domain.AuditInviteRevoked,
+		ActorID:  admin.ID,
+		Metadata: map[string]string{"invite_id": strconv.FormatUint(id, 10)},
+	})
+
+	return nil
+}
+
+func newInviteCode() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return inviteCodePrefix + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,119 @@
+// devcerts generates a local CA and a certificate per service for running
+// the services with mTLS in development, don't use these in production
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"flag"
+	"log"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func main() {
+	outDir := flag.String("out", "certs", "output directory")
+	services := flag.String("services", "auth-service,product-service,api-gateway", "comma separated service names")
+	days := flag.Int("days", 365, "certificate validity in days")
+	flag.Parse()
+
+	if err := os.MkdirAll(*outDir, 0o700); err != nil {
+		log.Fatalf("Failed to create %s: %v", *outDir, err)
+	}
+
+	validFor := time.Duration(*days) * 24 * time.Hour
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		log.Fatalf("Failed to generate CA key: %v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          newSerial(),
+		Subject:               pkix.Name{CommonName: "microservice-demo dev CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(validFor),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		log.Fatalf("Failed to create CA certificate: %v", err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		log.Fatalf("Failed to parse CA certificate: %v", err)
+	}
+	writeCert(filepath.Join(*outDir, "ca.pem"), caDER)
+	writeKey(filepath.Join(*outDir, "ca-key.pem"), caKey)
+
+	for _, name := range strings.Split(*services, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			log.Fatalf("Failed to generate key for %s: %v", name, err)
+		}
+
+		// every service is both a server and a client of another service
+		template := &x509.Certificate{
+			SerialNumber: newSerial(),
+			Subject:      pkix.Name{CommonName: name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(validFor),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+			DNSNames:     []string{name, "localhost"},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+			URIs:         []*url.URL{{Scheme: "spiffe", Host: "microservice-demo", Path: "/" + name}},
+		}
+
+		der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+		if err != nil {
+			log.Fatalf("Failed to create certificate for %s: %v", name, err)
+		}
+		writeCert(filepath.Join(*outDir, name+".pem"), der)
+		writeKey(filepath.Join(*outDir, name+"-key.pem"), key)
+	}
+
+	log.Printf("Certificates written to %s", *outDir)
+}
+
+func newSerial() *big.Int {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		log.Fatalf("Failed to generate serial number: %v", err)
+	}
+	return serial
+}
+
+func writeCert(path string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		log.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func writeKey(path string, key *ecdsa.PrivateKey) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		log.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		log.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
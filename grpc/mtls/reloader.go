@@ -0,0 +1,140 @@
+// Package mtls sets up mutual TLS between the gateway and the backend
+// services, certificates are reloaded when their files change
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// how often the certificate files are checked for changes
+const reloadInterval = 10 * time.Second
+
+// Config points to PEM files, the CA is used to verify the other side
+type Config struct {
+	CertFile string
+	KeyFile  string
+	CAFile   string
+}
+
+// Enabled reports whether all files are configured, mTLS is optional and
+// the services fall back to plaintext otherwise
+func (c Config) Enabled() bool {
+	return c.CertFile != "" && c.KeyFile != "" && c.CAFile != ""
+}
+
+// Reloader keeps the current certificate and CA pool in memory and swaps
+// them when the files on disk are replaced (e.g. by cert-manager)
+type Reloader struct {
+	config Config
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	caPool  *x509.CertPool
+	modTime time.Time
+}
+
+func NewReloader(config Config) (*Reloader, error) {
+	if !config.Enabled() {
+		return nil, errors.New("mtls: cert, key and CA files are required")
+	}
+
+	r := &Reloader{config: config}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	go r.watch()
+
+	return r, nil
+}
+
+func (r *Reloader) Certificate() *tls.Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert
+}
+
+func (r *Reloader) CAPool() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.caPool
+}
+
+func (r *Reloader) load() error {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return fmt.Errorf("mtls: failed to load key pair: %v", err)
+	}
+
+	caPEM, err := os.ReadFile(r.config.CAFile)
+	if err != nil {
+		return fmt.Errorf("mtls: failed to read CA: %v", err)
+	}
+	caPool := x509.NewCertPool()
+	if !caPool.AppendCertsFromPEM(caPEM) {
+		return errors.New("mtls: no certificates found in CA file")
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.caPool = caPool
+	r.modTime = modTime
+	r.mu.Unlock()
+
+	return nil
+}
+
+func (r *Reloader) watch() {
+	ticker := time.NewTicker(reloadInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		modTime, err := r.latestModTime()
+		if err != nil {
+			log.Printf("Warning: mtls: %v", err)
+			continue
+		}
+
+		r.mu.RLock()
+		changed := modTime.After(r.modTime)
+		r.mu.RUnlock()
+		if !changed {
+			continue
+		}
+
+		// keep serving the old certificate if the new files are broken,
+		// e.g. when only the cert was replaced so far
+		if err := r.load(); err != nil {
+			log.Printf("Warning: mtls: reload failed, keeping current certificate: %v", err)
+			continue
+		}
+		log.Println("mtls: certificates reloaded")
+	}
+}
+
+func (r *Reloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, path := range []string{r.config.CertFile, r.config.KeyFile, r.config.CAFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to stat %s: %v", path, err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest, nil
+}
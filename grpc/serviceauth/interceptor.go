@@ -0,0 +1,163 @@
+package serviceauth
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// MetadataKey carries the service token, authorization stays free for the
+// end user token
+const MetadataKey = "x-service-authorization"
+
+// scopes granted to service accounts
+const (
+	ScopeAuthRPC      = "auth.rpc"
+	ScopeProductRead  = "product.read"
+	ScopeProductWrite = "product.write"
+)
+
+// Mode decides what happens to calls without a valid service token
+type Mode string
+
+const (
+	// ModeRequired rejects them
+	ModeRequired Mode = "required"
+	// ModePermissive logs and accepts them, for rolling out service accounts
+	ModePermissive Mode = "permissive"
+)
+
+// UnaryClientInterceptor attaches a service token to every outgoing call
+func UnaryClientInterceptor(source *TokenSource) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, err := withServiceToken(ctx, source)
+		if err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func StreamClientInterceptor(source *TokenSource) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, err := withServiceToken(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+func withServiceToken(ctx context.Context, source *TokenSource) (context.Context, error) {
+	token, err := source.Token(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, MetadataKey, "Bearer "+token), nil
+}
+
+// Policy maps full method names (/package.Service/Method) to the scope the
+// caller needs, "*" is used for methods that are not listed. Methods without
+// an entry and without a default are denied
+type Policy map[string]string
+
+func (p Policy) scopeFor(method string) (string, bool) {
+	if scope, ok := p[method]; ok {
+		return scope, true
+	}
+	scope, ok := p["*"]
+	return scope, ok
+}
+
+// ServerAuth checks service tokens on incoming calls
+type ServerAuth struct {
+	verifier *Verifier
+	policy   Policy
+	mode     Mode
+}
+
+func NewServerAuth(verifier *Verifier, policy Policy, mode Mode) *ServerAuth {
+	return &ServerAuth{verifier: verifier, policy: policy, mode: mode}
+}
+
+func (a *ServerAuth) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (a *ServerAuth) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authorize(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &callerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (a *ServerAuth) authorize(ctx context.Context, method string) (context.Context, error) {
+	caller, err := a.check(ctx, method)
+	if err != nil {
+		if a.mode == ModePermissive {
+			log.Printf("Warning: service auth failed for %s (permissive mode): %v", method, err)
+			return ctx, nil
+		}
+		return nil, err
+	}
+
+	return context.WithValue(ctx, callerKey{}, caller), nil
+}
+
+func (a *ServerAuth) check(ctx context.Context, method string) (*Caller, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(MetadataKey)
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "missing service token")
+	}
+
+	token, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "malformed service token")
+	}
+
+	caller, err := a.verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid service token: "+err.Error())
+	}
+
+	scope, ok := a.policy.scopeFor(method)
+	if !ok || (scope != "" && !caller.HasScope(scope)) {
+		return nil, status.Errorf(codes.PermissionDenied, "service %q is not allowed to call %s", caller.ClientID, method)
+	}
+
+	return caller, nil
+}
+
+type callerKey struct{}
+
+// CallerFromContext returns the authenticated calling service, nil when the
+// call was accepted in permissive mode without a valid token
+func CallerFromContext(ctx context.Context) *Caller {
+	caller, _ := ctx.Value(callerKey{}).(*Caller)
+	return caller
+}
+
+type callerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *callerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,87 @@
+// Package serviceauth authenticates calls between our own services with
+// short-lived tokens from the auth-service client credentials grant
+package serviceauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokens are refreshed this long before they expire
+const refreshMargin = 30 * time.Second
+
+// TokenSource fetches service tokens with the OAuth2 client credentials
+// grant and caches them until they are about to expire
+type TokenSource struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scope        string
+	httpClient   *http.Client
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func NewTokenSource(tokenURL, clientID, clientSecret string, scopes ...string) *TokenSource {
+	return &TokenSource{
+		tokenURL:     tokenURL,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		scope:        strings.Join(scopes, " "),
+		httpClient:   &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Token returns a cached token or fetches a new one
+func (s *TokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Now().Add(refreshMargin).Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if s.scope != "" {
+		form.Set("scope", s.scope)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(s.clientID, s.clientSecret)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch service token: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int64  `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode service token response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || body.AccessToken == "" {
+		return "", fmt.Errorf("failed to fetch service token: %s %s", body.Error, body.ErrorDescription)
+	}
+
+	s.token = body.AccessToken
+	s.expiresAt = time.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+
+	return s.token, nil
+}
@@ -0,0 +1,188 @@
+package serviceauth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TokenUse marks service tokens so user ID tokens signed with the same key
+// can't be used to call services
+const TokenUse = "service"
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// Caller is the verified identity of the calling service
+type Caller struct {
+	ClientID string
+	Scopes   []string
+}
+
+func (c *Caller) HasScope(scope string) bool {
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Claims of a service token, issued by auth-service
+type Claims struct {
+	Scope    string `json:"scope"`
+	TokenUse string `json:"token_use"`
+	jwt.RegisteredClaims
+}
+
+// KeySource resolves the public key a token was signed with
+type KeySource interface {
+	Key(ctx context.Context, kid string) (*rsa.PublicKey, error)
+}
+
+// StaticKeys is a KeySource for the issuer itself, which has its keys in memory
+type StaticKeys map[string]*rsa.PublicKey
+
+func (k StaticKeys) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	if key, ok := k[kid]; ok {
+		return key, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+// Verifier checks service tokens
+type Verifier struct {
+	issuer string
+	keys   KeySource
+}
+
+func NewVerifier(issuer string, keys KeySource) *Verifier {
+	return &Verifier{issuer: issuer, keys: keys}
+}
+
+func (v *Verifier) Verify(ctx context.Context, token string) (*Caller, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	}, jwt.WithValidMethods([]string{"RS256"}), jwt.WithIssuer(v.issuer), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenUse != TokenUse {
+		return nil, errors.New("not a service token")
+	}
+
+	return &Caller{
+		ClientID: claims.Subject,
+		Scopes:   strings.Fields(claims.Scope),
+	}, nil
+}
+
+// jwksRefreshInterval limits how often unknown kids trigger a refetch
+const jwksRefreshInterval = time.Minute
+
+// JWKSKeySource loads keys from the auth-service JWKS endpoint and refetches
+// them when a token is signed with a key it hasn't seen (key rotation)
+type JWKSKeySource struct {
+	url        string
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	keys        map[string]*rsa.PublicKey
+	lastFetched time.Time
+}
+
+func NewJWKSKeySource(url string) *JWKSKeySource {
+	return &JWKSKeySource{
+		url:        url,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		keys:       make(map[string]*rsa.PublicKey),
+	}
+}
+
+func (s *JWKSKeySource) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+	if time.Since(s.lastFetched) < jwksRefreshInterval {
+		return nil, ErrUnknownKey
+	}
+
+	if err := s.fetch(ctx); err != nil {
+		return nil, err
+	}
+
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+func (s *JWKSKeySource) fetch(ctx context.Context) error {
+	s.lastFetched = time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch JWKS: status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("failed to decode JWKS: %v", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			continue
+		}
+
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	s.keys = keys
+
+	return nil
+}
@@ -0,0 +1,221 @@
+// Package tokencache caches token validation results of auth-service. The
+// cache is only used while the revocation stream of auth-service is
+// connected, so a logout or a disabled account is never answered from a
+// stale entry
+package tokencache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"expvar"
+	authpb "grpc/pb/auth"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	watchRetryMin = time.Second
+	watchRetryMax = 30 * time.Second
+)
+
+// Metrics are updated by the cache when set, nil ones are skipped
+type Metrics struct {
+	Hits        *expvar.Int
+	Misses      *expvar.Int
+	Revocations *expvar.Int
+	Entries     *expvar.Int
+	Watching    *expvar.Int
+}
+
+type entry[T any] struct {
+	value     T
+	expiresAt time.Time
+}
+
+// Cache keeps validation results of type T keyed by token hash, userID
+// tells which user a result belongs to so revoking all tokens of a user
+// drops it
+type Cache[T any] struct {
+	ttl        time.Duration
+	maxEntries int
+	userID     func(T) uint64
+	metrics    Metrics
+
+	mu       sync.Mutex
+	entries  map[string]entry[T]
+	watching bool
+	// bumped on every revocation, a Validate call that started before one
+	// must not be cached
+	generation uint64
+}
+
+// New with a ttl of 0 disables caching
+func New[T any](ttl time.Duration, maxEntries int, userID func(T) uint64, metrics Metrics) *Cache[T] {
+	return &Cache[T]{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		userID:     userID,
+		metrics:    metrics,
+		entries:    make(map[string]entry[T]),
+	}
+}
+
+// Get returns the cached result, on a miss the generation must be passed to
+// Set with the fresh result
+func (c *Cache[T]) Get(token string) (T, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero T
+	if !c.watching {
+		return zero, c.generation, false
+	}
+
+	cached, ok := c.entries[HashToken(token)]
+	if !ok || !time.Now().Before(cached.expiresAt) {
+		add(c.metrics.Misses, 1)
+		return zero, c.generation, false
+	}
+
+	add(c.metrics.Hits, 1)
+	return cached.value, c.generation, true
+}
+
+// Set stores a valid result for the ttl, or until notAfter (e.g. the token
+// expiry) when that is earlier. It is dropped when a revocation happened
+// since Get returned generation
+func (c *Cache[T]) Set(token string, value T, generation uint64, notAfter time.Time) {
+	expiresAt := time.Now().Add(c.ttl)
+	if !notAfter.IsZero() && notAfter.Before(expiresAt) {
+		expiresAt = notAfter
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.watching || generation != c.generation {
+		return
+	}
+
+	if len(c.entries) >= c.maxEntries {
+		c.evict()
+	}
+	c.entries[HashToken(token)] = entry[T]{value: value, expiresAt: expiresAt}
+	set(c.metrics.Entries, int64(len(c.entries)))
+}
+
+// evict drops expired entries, or an arbitrary one when none has expired
+func (c *Cache[T]) evict() {
+	now := time.Now()
+	for key, cached := range c.entries {
+		if !now.Before(cached.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+
+	if len(c.entries) >= c.maxEntries {
+		for key := range c.entries {
+			delete(c.entries, key)
+			break
+		}
+	}
+}
+
+// revoke drops the entries of a revoked token or of all tokens of a user
+func (c *Cache[T]) revoke(event *authpb.RevocationEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+
+	for key, cached := range c.entries {
+		if key == event.TokenHash || (event.UserId != 0 && c.userID(cached.value) == event.UserId) {
+			delete(c.entries, key)
+			add(c.metrics.Revocations, 1)
+		}
+	}
+	set(c.metrics.Entries, int64(len(c.entries)))
+}
+
+func (c *Cache[T]) setWatching(watching bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.watching = watching
+	c.generation++
+	if !watching {
+		// events may have been missed
+		c.entries = make(map[string]entry[T])
+		set(c.metrics.Entries, 0)
+		set(c.metrics.Watching, 0)
+	} else {
+		set(c.metrics.Watching, 1)
+	}
+}
+
+// Watch follows the revocation stream of auth-service and reconnects with
+// backoff, it never returns unless caching is disabled
+func (c *Cache[T]) Watch(client authpb.AuthServiceClient) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	retry := watchRetryMin
+	for {
+		connected, err := c.watchOnce(client)
+		c.setWatching(false)
+		log.Printf("Warning: revocation stream closed, token cache disabled: %v", err)
+
+		// backoff only grows while auth-service can't be reached
+		if connected {
+			retry = watchRetryMin
+		}
+		time.Sleep(retry)
+		retry = min(retry*2, watchRetryMax)
+	}
+}
+
+func (c *Cache[T]) watchOnce(client authpb.AuthServiceClient) (bool, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.WatchRevocations(ctx, &authpb.WatchRevocationsRequest{})
+	if err != nil {
+		return false, err
+	}
+	// auth-service sends headers once it is subscribed
+	if _, err := stream.Header(); err != nil {
+		return false, err
+	}
+
+	c.setWatching(true)
+	log.Println("Revocation stream connected, token cache enabled")
+
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			return true, err
+		}
+		c.revoke(event)
+	}
+}
+
+// HashToken is how auth-service identifies a token in revocation events
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func add(metric *expvar.Int, delta int64) {
+	if metric != nil {
+		metric.Add(delta)
+	}
+}
+
+func set(metric *expvar.Int, value int64) {
+	if metric != nil {
+		metric.Set(value)
+	}
+}
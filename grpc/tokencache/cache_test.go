@@ -0,0 +1,105 @@
+package tokencache
+
+import (
+	authpb "grpc/pb/auth"
+	"testing"
+	"time"
+)
+
+type user struct{ id uint64 }
+
+func newWatchingCache() *Cache[user] {
+	cache := New(time.Minute, 10, func(u user) uint64 { return u.id }, Metrics{})
+	cache.setWatching(true)
+	return cache
+}
+
+func TestCacheRevocations(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   *authpb.RevocationEvent
+		wantHit map[string]bool
+	}{
+		{"token", &authpb.RevocationEvent{TokenHash: HashToken("a1")}, map[string]bool{"a1": false, "a2": true, "b1": true}},
+		{"user", &authpb.RevocationEvent{UserId: 1}, map[string]bool{"a1": false, "a2": false, "b1": true}},
+		{"unknown token", &authpb.RevocationEvent{TokenHash: HashToken("c1")}, map[string]bool{"a1": true, "a2": true, "b1": true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newWatchingCache()
+			for token, id := range map[string]uint64{"a1": 1, "a2": 1, "b1": 2} {
+				_, generation, _ := cache.Get(token)
+				cache.Set(token, user{id: id}, generation, time.Time{})
+			}
+
+			cache.revoke(tt.event)
+
+			for token, want := range tt.wantHit {
+				if _, _, hit := cache.Get(token); hit != want {
+					t.Errorf("Get(%q) hit = %v, want %v", token, hit, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCacheSet(t *testing.T) {
+	tests := []struct {
+		name     string
+		watching bool
+		// a revocation between Get and Set
+		revoked  bool
+		notAfter time.Time
+		wantHit  bool
+	}{
+		{"stored", true, false, time.Time{}, true},
+		{"before the token expires", true, false, time.Now().Add(time.Hour), true},
+		{"token expired", true, false, time.Now().Add(-time.Second), false},
+		{"revocation since Get", true, true, time.Time{}, false},
+		{"stream not connected", false, false, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := New(time.Minute, 10, func(u user) uint64 { return u.id }, Metrics{})
+			cache.setWatching(tt.watching)
+
+			_, generation, _ := cache.Get("token")
+			if tt.revoked {
+				cache.revoke(&authpb.RevocationEvent{UserId: 2})
+			}
+			cache.Set("token", user{id: 1}, generation, tt.notAfter)
+
+			if _, _, hit := cache.Get("token"); hit != tt.wantHit {
+				t.Errorf("Get() hit = %v, want %v", hit, tt.wantHit)
+			}
+		})
+	}
+}
+
+func TestCacheDisconnectDropsEntries(t *testing.T) {
+	cache := newWatchingCache()
+	_, generation, _ := cache.Get("token")
+	cache.Set("token", user{id: 1}, generation, time.Time{})
+
+	// events may be missed while the stream is down
+	cache.setWatching(false)
+	cache.setWatching(true)
+
+	if _, _, hit := cache.Get("token"); hit {
+		t.Error("Get() hit after a reconnect, want a miss")
+	}
+}
+
+func TestCacheEvictsWhenFull(t *testing.T) {
+	cache := New(time.Minute, 2, func(u user) uint64 { return u.id }, Metrics{})
+	cache.setWatching(true)
+
+	for _, token := range []string{"a", "b", "c"} {
+		_, generation, _ := cache.Get(token)
+		cache.Set(token, user{id: 1}, generation, time.Time{})
+	}
+
+	if len(cache.entries) != 2 {
+		t.Errorf("len(entries) = %d, want 2", len(cache.entries))
+	}
+}
@@ -1,18 +1,31 @@
 package main
 
 import (
+	"expvar"
 	"fmt"
+	"grpc/mtls"
+	"grpc/serviceauth"
 	"log"
+	nethttp "net/http"
 	"os"
 	"os/signal"
 	"product-service/internal/delivery/grpc"
 	"product-service/internal/delivery/http"
 	"product-service/internal/domain"
 	"product-service/internal/repository"
+	"product-service/internal/service"
 	"product-service/internal/usecase"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
+
+	authpb "grpc/pb/auth"
 
 	"github.com/gin-gonic/gin"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -29,21 +42,155 @@ func main() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	if err = db.AutoMigrate(&domain.Product{}); err != nil {
+	if err = db.AutoMigrate(&domain.Product{}, &domain.ProductVariant{}, &domain.Category{}, &domain.EventCursor{},
+		&domain.ProductPrice{}, &domain.ExchangeRate{}, &domain.RoundingRule{}, &domain.PriceChange{}, &domain.ScheduledPriceChange{},
+		&domain.StockReservation{}); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 
+	// prices without a currency, including the ones stored before prices had
+	// one, are in DEFAULT_CURRENCY
+	defaultCurrency := strings.ToUpper(os.Getenv("DEFAULT_CURRENCY"))
+	if defaultCurrency == "" {
+		defaultCurrency = "USD"
+	}
+	if _, ok := domain.CurrencyExponent(defaultCurrency); !ok {
+		log.Fatalf("DEFAULT_CURRENCY %q is not an ISO 4217 currency code", defaultCurrency)
+	}
+	if err = repository.MigrateLegacyPrices(db, defaultCurrency); err != nil {
+		log.Fatalf("Failed to migrate prices: %v", err)
+	}
+
 	// init repository
 	productRepo := repository.NewProductRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
+	variantRepo := repository.NewVariantRepository(db)
+	eventCursorRepo := repository.NewEventCursorRepository(db)
+	currencyRepo := repository.NewCurrencyRepository(db)
+	priceHistoryRepo := repository.NewPriceHistoryRepository(db)
+
+	// products created before the price history existed start with their
+	// current price
+	if err = priceHistoryRepo.Backfill(); err != nil {
+		log.Fatalf("Failed to backfill the price history: %v", err)
+	}
 
 	// init usecase
-	productUseCase := usecase.NewProductUseCase(productRepo)
+	productUseCase := usecase.NewProductUseCase(productRepo, categoryRepo, currencyRepo, defaultCurrency)
+	categoryUseCase := usecase.NewCategoryUseCase(categoryRepo)
+	variantUseCase := usecase.NewVariantUseCase(variantRepo, productRepo)
+	currencyUseCase := usecase.NewCurrencyUseCase(currencyRepo)
+	priceHistoryUseCase := usecase.NewPriceHistoryUseCase(priceHistoryRepo, productRepo)
+
+	// service tokens are issued and signed by auth-service
+	tokenIssuer := os.Getenv("SERVICE_TOKEN_ISSUER")
+	if tokenIssuer == "" {
+		tokenIssuer = "http://localhost:8080"
+	}
+	jwksURL := os.Getenv("AUTH_JWKS_URL")
+	if jwksURL == "" {
+		jwksURL = tokenIssuer + "/oauth/jwks"
+	}
+	// user access tokens are signed with the same keys
+	jwksKeys := serviceauth.NewJWKSKeySource(jwksURL)
+	serviceVerifier := serviceauth.NewVerifier(tokenIssuer, jwksKeys)
+
+	// "permissive" only logs calls without a valid service token
+	serviceAuthMode := serviceauth.ModeRequired
+	if serviceauth.Mode(os.Getenv("SERVICE_AUTH_MODE")) == serviceauth.ModePermissive {
+		log.Println("Warning: SERVICE_AUTH_MODE=permissive, unauthenticated gRPC calls are accepted")
+		serviceAuthMode = serviceauth.ModePermissive
+	}
+
+	// optional mTLS on the gRPC server and on the auth-service client
+	var grpcCreds credentials.TransportCredentials
+	authCreds := insecure.NewCredentials()
+	tlsConfig := mtls.Config{
+		CertFile: os.Getenv("GRPC_TLS_CERT_FILE"),
+		KeyFile:  os.Getenv("GRPC_TLS_KEY_FILE"),
+		CAFile:   os.Getenv("GRPC_TLS_CA_FILE"),
+	}
+	if tlsConfig.Enabled() {
+		reloader, err := mtls.NewReloader(tlsConfig)
+		if err != nil {
+			log.Fatalf("Failed to load gRPC TLS certificates: %v", err)
+		}
+		// e.g. GRPC_TLS_ALLOWED_PEERS=api-gateway
+		var allowedPeers []string
+		if peers := os.Getenv("GRPC_TLS_ALLOWED_PEERS"); peers != "" {
+			allowedPeers = strings.Split(peers, ",")
+		}
+		grpcCreds = mtls.ServerCredentials(reloader, allowedPeers)
+		authCreds = mtls.ClientCredentials(reloader, "auth-service")
+	}
+
+	// connect to auth-service to validate user tokens
+	authAddr := os.Getenv("AUTH_SERVICE_ADDR")
+	if authAddr == "" {
+		authAddr = "localhost:50051"
+	}
+	authOpts := []grpclib.DialOption{grpclib.WithTransportCredentials(authCreds)}
+	if clientID := os.Getenv("SERVICE_CLIENT_ID"); clientID != "" {
+		tokenURL := os.Getenv("SERVICE_TOKEN_URL")
+		if tokenURL == "" {
+			tokenURL = tokenIssuer + "/oauth/token"
+		}
+
+		tokens := serviceauth.NewTokenSource(tokenURL, clientID, os.Getenv("SERVICE_CLIENT_SECRET"))
+		authOpts = append(authOpts,
+			grpclib.WithUnaryInterceptor(serviceauth.UnaryClientInterceptor(tokens)),
+			grpclib.WithStreamInterceptor(serviceauth.StreamClientInterceptor(tokens)),
+		)
+	} else {
+		log.Println("Warning: SERVICE_CLIENT_ID not set, calls to auth-service are not authenticated")
+	}
+	authConn, err := grpclib.Dial(authAddr, authOpts...)
+	if err != nil {
+		log.Fatalf("Failed to connect to auth-service: %v", err)
+	}
+	defer authConn.Close()
+
+	// user tokens are checked locally with the JWKS before asking auth-service
+	tokenCacheTTL := 30 * time.Second
+	if ttl, err := strconv.Atoi(os.Getenv("TOKEN_CACHE_TTL_SECONDS")); err == nil && ttl >= 0 {
+		tokenCacheTTL = time.Duration(ttl) * time.Second
+	}
+	// "local" accepts tokens with a valid signature while auth-service is
+	// down, "closed" (default) rejects them
+	tokenFallback := service.FallbackMode(os.Getenv("TOKEN_VALIDATION_FALLBACK"))
+	if tokenFallback != service.FallbackLocal {
+		tokenFallback = service.FallbackClosed
+	} else {
+		log.Println("Warning: TOKEN_VALIDATION_FALLBACK=local, revoked tokens are accepted while auth-service is down")
+	}
+	authClient := authpb.NewAuthServiceClient(authConn)
+	tokenValidator := service.NewTokenValidator(tokenIssuer, jwksKeys, authClient, tokenCacheTTL, tokenFallback)
+
+	// remove references to users erased in auth-service
+	go service.NewUserEventConsumer(authClient, eventCursorRepo, productUseCase).Run()
+
+	// apply scheduled price changes, they are at most this late
+	priceSchedulerInterval := 15 * time.Second
+	if interval, err := strconv.Atoi(os.Getenv("PRICE_SCHEDULER_INTERVAL_SECONDS")); err == nil && interval > 0 {
+		priceSchedulerInterval = time.Duration(interval) * time.Second
+	}
+	go service.NewPriceScheduler(priceHistoryUseCase, priceSchedulerInterval).Run()
+
+	reservationExpiryInterval := 30 * time.Second
+	if interval, err := strconv.Atoi(os.Getenv("RESERVATION_EXPIRY_INTERVAL_SECONDS")); err == nil && interval > 0 {
+		reservationExpiryInterval = time.Duration(interval) * time.Second
+	}
+	go service.NewReservationExpirer(variantUseCase, reservationExpiryInterval).Run()
 
 	// init HTTP handler
-	productHandler := http.NewProductHandler(productUseCase)
+	productHandler := http.NewProductHandler(productUseCase, tokenValidator)
+	categoryHandler := http.NewCategoryHandler(categoryUseCase, tokenValidator)
+	variantHandler := http.NewVariantHandler(variantUseCase, tokenValidator)
+	currencyHandler := http.NewCurrencyHandler(currencyUseCase, tokenValidator)
+	priceHistoryHandler := http.NewPriceHistoryHandler(priceHistoryUseCase, tokenValidator)
 
 	// init gRPC handler
-	grpcHandler := grpc.NewGRPCProductHandler(productUseCase)
+	grpcHandler := grpc.NewGRPCProductHandler(productUseCase, categoryUseCase, variantUseCase, currencyUseCase, priceHistoryUseCase, tokenValidator)
 
 	// init gin router
 	router := gin.Default()
@@ -51,6 +198,10 @@ func main() {
 
 	// register routes
 	productHandler.RegisterRoutes(router)
+	categoryHandler.RegisterRoutes(router)
+	variantHandler.RegisterRoutes(router)
+	currencyHandler.RegisterRoutes(router)
+	priceHistoryHandler.RegisterRoutes(router)
 
 	// channel signal shutdown
 	sigChan := make(chan os.Signal, 1)
@@ -72,6 +223,13 @@ func main() {
 		}
 	}()
 
+	// /debug/vars stays off the public port
+	metricsAddr := os.Getenv("METRICS_ADDR")
+	if metricsAddr == "" {
+		metricsAddr = "localhost:9092"
+	}
+	go serveMetrics(metricsAddr)
+
 	// start gRPC server
 	grpcPort := os.Getenv("GRPC_PORT")
 	if grpcPort == "" {
@@ -80,7 +238,7 @@ func main() {
 
 	go func() {
 		log.Printf("Starting gRPC server on port %s", grpcPort)
-		if err := grpcHandler.Serve(":" + grpcPort); err != nil {
+		if err := grpcHandler.Serve(":"+grpcPort, serviceVerifier, serviceAuthMode, grpcCreds); err != nil {
 			errChan <- fmt.Errorf("Failed to start gRPC server: %v", err)
 		}
 	}()
@@ -101,6 +259,17 @@ func main() {
 	}
 }
 
+// serveMetrics serves expvar on an internal address
+func serveMetrics(addr string) {
+	mux := nethttp.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	log.Printf("Metrics are served on %s/debug/vars", addr)
+	if err := nethttp.ListenAndServe(addr, mux); err != nil {
+		log.Printf("Warning: metrics server stopped: %v", err)
+	}
+}
+
 func CorsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
@@ -0,0 +1,127 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"product-service/internal/domain"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// authRules lists which methods need a user token, methods that are not
+// listed are protected. Products belong to a tenant, so reads need a token too
+var authRules = map[string]domain.AuthRule{
+	"/product.ProductService/GetProduct":                 {Scope: domain.ScopeProductsRead},
+	"/product.ProductService/ListProducts":               {Scope: domain.ScopeProductsRead},
+	"/product.ProductService/CreateProduct":              {Scope: domain.ScopeProductsWrite},
+	"/product.ProductService/UpdateProduct":              {Scope: domain.ScopeProductsWrite},
+	"/product.ProductService/DeleteProduct":              {Scope: domain.ScopeProductsWrite},
+	"/product.ProductService/ExportMyProducts":           {Scope: domain.ScopeProductsRead},
+	"/product.ProductService/SetProductCategory":         {Scope: domain.ScopeProductsWrite},
+	"/product.ProductService/GetCategory":                {Scope: domain.ScopeProductsRead},
+	"/product.ProductService/ListCategories":             {Scope: domain.ScopeProductsRead},
+	"/product.ProductService/CreateCategory":             {Scope: domain.ScopeProductsWrite},
+	"/product.ProductService/UpdateCategory":             {Scope: domain.ScopeProductsWrite},
+	"/product.ProductService/MoveCategory":               {Scope: domain.ScopeProductsWrite},
+	"/product.ProductService/DeleteCategory":             {Scope: domain.ScopeProductsWrite},
+	"/product.ProductService/CreateVariant":              {Scope: domain.ScopeProductsWrite},
+	"/product.ProductService/UpdateVariant":              {Scope: domain.ScopeProductsWrite},
+	"/product.ProductService/DeleteVariant":              {Scope: domain.ScopeProductsWrite},
+	"/product.ProductService/ReserveStock":               {Scope: domain.ScopeProductsWrite},
+	"/product.ProductService/ReleaseStock":               {Scope: domain.ScopeProductsWrite},
+	"/product.ProductService/SetProductPrice":            {Scope: domain.ScopeProductsWrite},
+	"/product.ProductService/DeleteProductPrice":         {Scope: domain.ScopeProductsWrite},
+	"/product.ProductService/SetExchangeRate":            {Admin: true},
+	"/product.ProductService/DeleteExchangeRate":         {Admin: true},
+	"/product.ProductService/ListExchangeRates":          {Admin: true},
+	"/product.ProductService/ImportExchangeRates":        {Admin: true},
+	"/product.ProductService/SetRoundingRule":            {Admin: true},
+	"/product.ProductService/ListRoundingRules":          {Admin: true},
+	"/product.ProductService/GetPriceHistory":            {Scope: domain.ScopeProductsRead},
+	"/product.ProductService/SchedulePriceChange":        {Scope: domain.ScopeProductsWrite},
+	"/product.ProductService/ListScheduledPriceChanges":  {Scope: domain.ScopeProductsRead},
+	"/product.ProductService/CancelScheduledPriceChange": {Scope: domain.ScopeProductsWrite},
+}
+
+// AuthInterceptor resolves the end user from the authorization metadata, the
+// calling service itself is checked by serviceauth
+type AuthInterceptor struct {
+	validator domain.TokenValidator
+	rules     map[string]domain.AuthRule
+}
+
+func NewAuthInterceptor(validator domain.TokenValidator, rules map[string]domain.AuthRule) *AuthInterceptor {
+	return &AuthInterceptor{validator: validator, rules: rules}
+}
+
+func (i *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := i.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (i *AuthInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := i.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (i *AuthInterceptor) authenticate(ctx context.Context, method string) (context.Context, error) {
+	rule := i.rules[method]
+
+	token := tokenFromMetadata(ctx)
+	if token == "" && rule.Public {
+		return ctx, nil
+	}
+
+	user, err := i.validator.Validate(ctx, token)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrMissingToken), errors.Is(err, domain.ErrInvalidToken):
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		default:
+			return nil, status.Error(codes.Unavailable, "failed to validate token")
+		}
+	}
+
+	if rule.Scope != "" && !user.HasScope(rule.Scope) {
+		return nil, status.Errorf(codes.PermissionDenied, "api key is missing the %s scope", rule.Scope)
+	}
+	if rule.Admin && !user.IsAdminSession() {
+		return nil, status.Error(codes.PermissionDenied, domain.ErrAdminRequired.Error())
+	}
+
+	return domain.ContextWithAuthUser(ctx, user), nil
+}
+
+func tokenFromMetadata(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return ""
+	}
+
+	token, _ := strings.CutPrefix(values[0], "Bearer ")
+	return token
+}
+
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,113 @@
+package grpc
+
+import (
+	"context"
+	pb "grpc/pb/product"
+	"product-service/internal/domain"
+	"time"
+)
+
+func (h *GRPCProductHandler) CreateCategory(ctx context.Context, req *pb.CreateCategoryRequest) (*pb.Category, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	category, err := h.categoryUseCase.Create(tenantID, req.Name, req.ParentId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return convertToProtoCategory(category), nil
+}
+
+func (h *GRPCProductHandler) GetCategory(ctx context.Context, req *pb.GetCategoryRequest) (*pb.Category, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	category, err := h.categoryUseCase.GetByID(tenantID, req.Id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return convertToProtoCategory(category), nil
+}
+
+func (h *GRPCProductHandler) ListCategories(ctx context.Context, req *pb.ListCategoriesRequest) (*pb.ListCategoriesResponse, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := h.categoryUseCase.List(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	protoCategories := make([]*pb.Category, len(categories))
+	for i, category := range categories {
+		protoCategories[i] = convertToProtoCategory(&category)
+	}
+
+	return &pb.ListCategoriesResponse{Categories: protoCategories}, nil
+}
+
+func (h *GRPCProductHandler) UpdateCategory(ctx context.Context, req *pb.UpdateCategoryRequest) (*pb.Category, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	category, err := h.categoryUseCase.Rename(tenantID, req.Id, req.Name)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return convertToProtoCategory(category), nil
+}
+
+func (h *GRPCProductHandler) MoveCategory(ctx context.Context, req *pb.MoveCategoryRequest) (*pb.Category, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	category, err := h.categoryUseCase.Move(tenantID, req.Id, req.ParentId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return convertToProtoCategory(category), nil
+}
+
+func (h *GRPCProductHandler) DeleteCategory(ctx context.Context, req *pb.DeleteCategoryRequest) (*pb.DeleteCategoryResponse, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.categoryUseCase.Delete(tenantID, req.Id); err != nil {
+		return &pb.DeleteCategoryResponse{Success: false}, toStatus(err)
+	}
+
+	return &pb.DeleteCategoryResponse{Success: true}, nil
+}
+
+func convertToProtoCategory(category *domain.Category) *pb.Category {
+	var parentID uint64
+	if category.ParentID != nil {
+		parentID = *category.ParentID
+	}
+
+	return &pb.Category{
+		Id:        category.ID,
+		Name:      category.Name,
+		ParentId:  parentID,
+		Path:      category.Path,
+		Depth:     int32(category.Depth()),
+		CreatedAt: category.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: category.UpdatedAt.Format(time.RFC3339),
+	}
+}
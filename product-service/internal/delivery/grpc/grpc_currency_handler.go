@@ -0,0 +1,122 @@
+package grpc
+
+import (
+	"context"
+	pb "grpc/pb/product"
+	"product-service/internal/domain"
+	"time"
+)
+
+func (h *GRPCProductHandler) SetProductPrice(ctx context.Context, req *pb.SetProductPriceRequest) (*pb.Product, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var price domain.Money
+	if req.Price != nil {
+		price = domain.Money{AmountMinor: req.Price.AmountMinor, Currency: req.Price.CurrencyCode}
+	}
+	product, err := h.productUseCase.SetPrice(tenantID, req.ProductId, price)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return convertToProtoProduct(product), nil
+}
+
+func (h *GRPCProductHandler) DeleteProductPrice(ctx context.Context, req *pb.DeleteProductPriceRequest) (*pb.Product, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := h.productUseCase.DeletePrice(tenantID, req.ProductId, req.CurrencyCode)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return convertToProtoProduct(product), nil
+}
+
+// the methods below are admin only, see authRules
+
+func (h *GRPCProductHandler) SetExchangeRate(ctx context.Context, req *pb.SetExchangeRateRequest) (*pb.ExchangeRate, error) {
+	rate, err := h.currencyUseCase.SetExchangeRate(req.FromCurrency, req.ToCurrency, req.Rate)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return convertToProtoExchangeRate(rate), nil
+}
+
+func (h *GRPCProductHandler) DeleteExchangeRate(ctx context.Context, req *pb.DeleteExchangeRateRequest) (*pb.DeleteExchangeRateResponse, error) {
+	if err := h.currencyUseCase.DeleteExchangeRate(req.FromCurrency, req.ToCurrency); err != nil {
+		return &pb.DeleteExchangeRateResponse{Success: false}, toStatus(err)
+	}
+
+	return &pb.DeleteExchangeRateResponse{Success: true}, nil
+}
+
+func (h *GRPCProductHandler) ListExchangeRates(ctx context.Context, req *pb.ListExchangeRatesRequest) (*pb.ListExchangeRatesResponse, error) {
+	rates, err := h.currencyUseCase.ListExchangeRates()
+	if err != nil {
+		return nil, err
+	}
+
+	protoRates := make([]*pb.ExchangeRate, len(rates))
+	for i, rate := range rates {
+		protoRates[i] = convertToProtoExchangeRate(&rate)
+	}
+
+	return &pb.ListExchangeRatesResponse{Rates: protoRates}, nil
+}
+
+func (h *GRPCProductHandler) ImportExchangeRates(ctx context.Context, req *pb.ImportExchangeRatesRequest) (*pb.ImportExchangeRatesResponse, error) {
+	imported, err := h.currencyUseCase.ImportExchangeRates(req.Csv)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.ImportExchangeRatesResponse{Imported: int32(imported)}, nil
+}
+
+func (h *GRPCProductHandler) SetRoundingRule(ctx context.Context, req *pb.SetRoundingRuleRequest) (*pb.RoundingRule, error) {
+	rule, err := h.currencyUseCase.SetRoundingRule(req.CurrencyCode, req.IncrementMinor, req.Mode)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return convertToProtoRoundingRule(rule), nil
+}
+
+func (h *GRPCProductHandler) ListRoundingRules(ctx context.Context, req *pb.ListRoundingRulesRequest) (*pb.ListRoundingRulesResponse, error) {
+	rules, err := h.currencyUseCase.ListRoundingRules()
+	if err != nil {
+		return nil, err
+	}
+
+	protoRules := make([]*pb.RoundingRule, len(rules))
+	for i, rule := range rules {
+		protoRules[i] = convertToProtoRoundingRule(&rule)
+	}
+
+	return &pb.ListRoundingRulesResponse{Rules: protoRules}, nil
+}
+
+func convertToProtoExchangeRate(rate *domain.ExchangeRate) *pb.ExchangeRate {
+	return &pb.ExchangeRate{
+		FromCurrency: rate.From,
+		ToCurrency:   rate.To,
+		Rate:         rate.Rate,
+		UpdatedAt:    rate.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func convertToProtoRoundingRule(rule *domain.RoundingRule) *pb.RoundingRule {
+	return &pb.RoundingRule{
+		CurrencyCode:   rule.Currency,
+		IncrementMinor: rule.Increment,
+		Mode:           rule.Mode,
+	}
+}
@@ -2,49 +2,86 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	pb "grpc/pb/product"
+	"grpc/serviceauth"
 	"product-service/internal/domain"
 	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
 )
 
 type GRPCProductHandler struct {
 	pb.UnimplementedProductServiceServer
-	productUseCase domain.ProductUseCase
+	productUseCase  domain.ProductUseCase
+	categoryUseCase domain.CategoryUseCase
+	variantUseCase  domain.VariantUseCase
+	currencyUseCase domain.CurrencyUseCase
+	priceUseCase    domain.PriceHistoryUseCase
+	tokenValidator  domain.TokenValidator
 }
 
-func NewGRPCProductHandler(productUseCase domain.ProductUseCase) *GRPCProductHandler {
-	return &GRPCProductHandler{productUseCase: productUseCase}
+func NewGRPCProductHandler(productUseCase domain.ProductUseCase, categoryUseCase domain.CategoryUseCase, variantUseCase domain.VariantUseCase, currencyUseCase domain.CurrencyUseCase, priceUseCase domain.PriceHistoryUseCase, tokenValidator domain.TokenValidator) *GRPCProductHandler {
+	return &GRPCProductHandler{
+		productUseCase:  productUseCase,
+		categoryUseCase: categoryUseCase,
+		variantUseCase:  variantUseCase,
+		currencyUseCase: currencyUseCase,
+		priceUseCase:    priceUseCase,
+		tokenValidator:  tokenValidator,
+	}
 }
 
 func (h *GRPCProductHandler) CreateProduct(ctx context.Context, req *pb.CreateProductRequest) (*pb.Product, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	product, err := h.productUseCase.Create(
+		tenantID,
+		domain.AuthUserFromContext(ctx).ID,
+		req.CategoryId,
 		req.Name,
 		req.Description,
-		req.Price,
+		priceInput(req.UnitPrice, req.Price),
 		req.Stock,
 	)
 	if err != nil {
-		return nil, err
+		return nil, toStatus(err)
 	}
 
 	return convertToProtoProduct(product), nil
 }
 
 func (h *GRPCProductHandler) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.Product, error) {
-	product, err := h.productUseCase.GetByID(req.Id)
+	tenantID, err := tenantFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	product, err := h.productUseCase.GetByID(tenantID, req.Id, req.CurrencyCode)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
 	return convertToProtoProduct(product), nil
 }
 
 func (h *GRPCProductHandler) ListProducts(ctx context.Context, req *pb.ListProductsRequest) (*pb.ListProductsResponse, error) {
-	products, total, err := h.productUseCase.List(req.Page, req.PerPage, req.Search)
+	tenantID, err := tenantFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	products, total, err := h.productUseCase.List(tenantID, req.Page, req.PerPage, req.Search, req.CategoryId, req.CurrencyCode)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
 	protoProducts := make([]*pb.Product, len(products))
 	for i, product := range products {
 		protoProducts[i] = convertToProtoProduct(&product)
@@ -64,45 +101,182 @@ func (h *GRPCProductHandler) ListProducts(ctx context.Context, req *pb.ListProdu
 }
 
 func (h *GRPCProductHandler) UpdateProduct(ctx context.Context, req *pb.UpdateProductRequest) (*pb.Product, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	product, err := h.productUseCase.Update(
+		tenantID,
 		req.Id,
+		domain.AuthUserFromContext(ctx).ID,
 		req.Name,
 		req.Description,
-		req.Price,
+		priceInput(req.UnitPrice, req.Price),
 		req.Stock,
 	)
 	if err != nil {
-		return nil, err
+		return nil, toStatus(err)
 	}
 
 	return convertToProtoProduct(product), nil
 }
 
 func (h *GRPCProductHandler) DeleteProduct(ctx context.Context, req *pb.DeleteProductRequest) (*pb.DeleteProductResponse, error) {
-	err := h.productUseCase.Delete(req.Id)
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.productUseCase.Delete(tenantID, req.Id)
 	if err != nil {
-		return &pb.DeleteProductResponse{Success: false}, err
+		return &pb.DeleteProductResponse{Success: false}, toStatus(err)
 	}
 
 	return &pb.DeleteProductResponse{Success: true}, nil
 }
 
+func (h *GRPCProductHandler) SetProductCategory(ctx context.Context, req *pb.SetProductCategoryRequest) (*pb.Product, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := h.productUseCase.SetCategory(tenantID, req.Id, req.CategoryId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return convertToProtoProduct(product), nil
+}
+
+// ExportMyProducts returns the caller's products from every organization,
+// it is used by auth-service for personal data exports
+func (h *GRPCProductHandler) ExportMyProducts(ctx context.Context, req *pb.ExportMyProductsRequest) (*pb.ExportMyProductsResponse, error) {
+	user := domain.AuthUserFromContext(ctx)
+	if user == nil {
+		return nil, status.Error(codes.Unauthenticated, domain.ErrMissingToken.Error())
+	}
+
+	products, err := h.productUseCase.ExportByCreator(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	protoProducts := make([]*pb.Product, len(products))
+	for i, product := range products {
+		protoProducts[i] = convertToProtoProduct(&product)
+	}
+
+	return &pb.ExportMyProductsResponse{Products: protoProducts}, nil
+}
+
 // serve starts the gRPC server
-func (h *GRPCProductHandler) Serve(address string) error {
-	server := NewGRPCProductServer(address)
+func (h *GRPCProductHandler) Serve(address string, verifier *serviceauth.Verifier, mode serviceauth.Mode, creds credentials.TransportCredentials) error {
+	server := NewGRPCProductServer(address, verifier, mode, creds, h.tokenValidator)
 	server.RegisterServices(h)
 	return server.Start()
 }
 
+// tenantFromContext returns the caller's active organization, products are
+// scoped to it
+func tenantFromContext(ctx context.Context) (uint64, error) {
+	user := domain.AuthUserFromContext(ctx)
+	if user == nil || user.OrganizationID == 0 {
+		return 0, status.Error(codes.PermissionDenied, domain.ErrNoTenant.Error())
+	}
+
+	return user.OrganizationID, nil
+}
+
+// toStatus maps a product, category or variant from another tenant to
+// NotFound as well
+func toStatus(err error) error {
+	var validationErr *domain.ValidationError
+	if errors.As(err, &validationErr) {
+		st, detailErr := status.New(codes.InvalidArgument, validationErr.Error()).WithDetails(&errdetails.PreconditionFailure{
+			Violations: []*errdetails.PreconditionFailure_Violation{{
+				Type:        "invalid",
+				Subject:     validationErr.Field,
+				Description: validationErr.Message,
+			}},
+		})
+		if detailErr != nil {
+			return status.Error(codes.InvalidArgument, validationErr.Error())
+		}
+		return st.Err()
+	}
+
+	switch {
+	case errors.Is(err, domain.ErrProductNotFound), errors.Is(err, domain.ErrCategoryNotFound), errors.Is(err, domain.ErrVariantNotFound),
+		errors.Is(err, domain.ErrRateNotFound), errors.Is(err, domain.ErrScheduledChangeNotFound), errors.Is(err, domain.ErrReservationNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrCategoryNameInvalid), errors.Is(err, domain.ErrVariantRequired), errors.Is(err, domain.ErrInvalidQuantity),
+		errors.Is(err, domain.ErrInvalidPrice), errors.Is(err, domain.ErrUnknownCurrency), errors.Is(err, domain.ErrInvalidRate),
+		errors.Is(err, domain.ErrInvalidRoundingRule), errors.Is(err, domain.ErrProductCurrency), errors.Is(err, domain.ErrScheduleInPast),
+		errors.Is(err, domain.ErrScheduleCurrency), errors.Is(err, domain.ErrReleaseTooLarge):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrCategoryNotEmpty), errors.Is(err, domain.ErrCategoryCycle), errors.Is(err, domain.ErrInsufficientStock),
+		errors.Is(err, domain.ErrCurrencyMismatch), errors.Is(err, domain.ErrNoExchangeRate), errors.Is(err, domain.ErrScheduledChangeDone),
+		errors.Is(err, domain.ErrReservationClosed):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrSKUTaken), errors.Is(err, domain.ErrVariantExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	}
+	return err
+}
+
+// priceInput prefers unit_price, the deprecated price is used when it is unset
+func priceInput(unitPrice *pb.Money, price float64) domain.PriceInput {
+	if unitPrice != nil {
+		return domain.PriceInput{Money: &domain.Money{AmountMinor: unitPrice.AmountMinor, Currency: unitPrice.CurrencyCode}}
+	}
+	return domain.PriceInput{Amount: price}
+}
+
+func convertToProtoMoney(money domain.Money) *pb.Money {
+	return &pb.Money{CurrencyCode: money.Currency, AmountMinor: money.AmountMinor}
+}
+
+func convertToProtoMoneyPtr(money *domain.Money) *pb.Money {
+	if money == nil {
+		return nil
+	}
+	return convertToProtoMoney(*money)
+}
+
 // helper func to conver domain Product to proto Product
 func convertToProtoProduct(product *domain.Product) *pb.Product {
+	var categoryID uint64
+	if product.CategoryID != nil {
+		categoryID = *product.CategoryID
+	}
+
+	variants := make([]*pb.ProductVariant, len(product.Variants))
+	for i, variant := range product.Variants {
+		variants[i] = convertToProtoVariant(&variant, product.Price)
+	}
+
+	prices := make([]*pb.Money, len(product.Prices))
+	for i, price := range product.Prices {
+		prices[i] = &pb.Money{CurrencyCode: price.Currency, AmountMinor: price.AmountMinor}
+	}
+
 	return &pb.Product{
-		Id:          product.ID,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		Stock:       product.Stock,
-		CreatedAt:   product.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   product.UpdatedAt.Format(time.RFC3339),
+		Id:             product.ID,
+		Name:           product.Name,
+		Description:    product.Description,
+		Price:          product.Price.Float(),
+		UnitPrice:      convertToProtoMoney(product.Price),
+		Stock:          product.Stock,
+		OrganizationId: product.TenantID,
+		CreatedBy:      product.CreatedBy,
+		CategoryId:     categoryID,
+		Variants:       variants,
+		Prices:         prices,
+		RequestedPrice: convertToProtoMoneyPtr(product.RequestedPrice),
+		PriceConverted: product.PriceConverted,
+		CreatedAt:      product.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      product.UpdatedAt.Format(time.RFC3339),
 	}
 }
@@ -0,0 +1,136 @@
+package grpc
+
+import (
+	"context"
+	pb "grpc/pb/product"
+	"product-service/internal/domain"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (h *GRPCProductHandler) GetPriceHistory(ctx context.Context, req *pb.GetPriceHistoryRequest) (*pb.GetPriceHistoryResponse, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	page, perPage := max(req.Page, 1), req.PerPage
+	if perPage < 1 {
+		perPage = 10
+	}
+	perPage = min(perPage, 100)
+
+	changes, total, err := h.priceUseCase.GetHistory(tenantID, req.ProductId, page, perPage)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	protoChanges := make([]*pb.PriceChange, len(changes))
+	for i, change := range changes {
+		protoChanges[i] = convertToProtoPriceChange(&change)
+	}
+
+	return &pb.GetPriceHistoryResponse{
+		Changes: protoChanges,
+		Meta: &pb.Meta{
+			Total:      int32(total),
+			Page:       page,
+			PerPage:    perPage,
+			TotalPages: int32((total + int64(perPage) - 1) / int64(perPage)),
+		},
+	}, nil
+}
+
+func (h *GRPCProductHandler) SchedulePriceChange(ctx context.Context, req *pb.SchedulePriceChangeRequest) (*pb.ScheduledPriceChange, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	effectiveAt, err := time.Parse(time.RFC3339, req.EffectiveAt)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "effective_at must be an RFC 3339 timestamp")
+	}
+	var price domain.Money
+	if req.UnitPrice != nil {
+		price = domain.Money{AmountMinor: req.UnitPrice.AmountMinor, Currency: req.UnitPrice.CurrencyCode}
+	}
+
+	change, err := h.priceUseCase.Schedule(tenantID, req.ProductId, domain.AuthUserFromContext(ctx).ID, price, effectiveAt)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return convertToProtoScheduledChange(change), nil
+}
+
+func (h *GRPCProductHandler) ListScheduledPriceChanges(ctx context.Context, req *pb.ListScheduledPriceChangesRequest) (*pb.ListScheduledPriceChangesResponse, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := h.priceUseCase.ListScheduled(tenantID, req.ProductId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	protoChanges := make([]*pb.ScheduledPriceChange, len(changes))
+	for i, change := range changes {
+		protoChanges[i] = convertToProtoScheduledChange(&change)
+	}
+
+	return &pb.ListScheduledPriceChangesResponse{Changes: protoChanges}, nil
+}
+
+func (h *GRPCProductHandler) CancelScheduledPriceChange(ctx context.Context, req *pb.CancelScheduledPriceChangeRequest) (*pb.ScheduledPriceChange, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	change, err := h.priceUseCase.Cancel(tenantID, req.ProductId, req.Id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return convertToProtoScheduledChange(change), nil
+}
+
+func convertToProtoPriceChange(change *domain.PriceChange) *pb.PriceChange {
+	protoChange := &pb.PriceChange{
+		Id:            change.ID,
+		Price:         convertToProtoMoney(change.Price),
+		EffectiveFrom: change.EffectiveFrom.Format(time.RFC3339),
+		ChangedBy:     change.ChangedBy,
+		Source:        change.Source,
+	}
+	if change.EffectiveTo != nil {
+		protoChange.EffectiveTo = change.EffectiveTo.Format(time.RFC3339)
+	}
+	if change.ScheduledChangeID != nil {
+		protoChange.ScheduledChangeId = *change.ScheduledChangeID
+	}
+
+	return protoChange
+}
+
+func convertToProtoScheduledChange(change *domain.ScheduledPriceChange) *pb.ScheduledPriceChange {
+	protoChange := &pb.ScheduledPriceChange{
+		Id:          change.ID,
+		ProductId:   change.ProductID,
+		Price:       convertToProtoMoney(change.Price),
+		EffectiveAt: change.EffectiveAt.Format(time.RFC3339),
+		Status:      change.Status,
+		Error:       change.Error,
+		CreatedBy:   change.CreatedBy,
+		CreatedAt:   change.CreatedAt.Format(time.RFC3339),
+	}
+	if change.AppliedAt != nil {
+		protoChange.AppliedAt = change.AppliedAt.Format(time.RFC3339)
+	}
+
+	return protoChange
+}
@@ -3,10 +3,13 @@ package grpc
 import (
 	"fmt"
 	pb "grpc/pb/product"
+	"grpc/serviceauth"
 	"log"
 	"net"
+	"product-service/internal/domain"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 type Server struct {
@@ -14,9 +17,54 @@ type Server struct {
 	server  *grpc.Server
 }
 
-func NewGRPCProductServer(address string) *Server {
-	// create a new gRPC server
-	server := grpc.NewServer()
+// servicePolicy lists the scope a calling service needs per method
+var servicePolicy = serviceauth.Policy{
+	"/product.ProductService/GetProduct":                 serviceauth.ScopeProductRead,
+	"/product.ProductService/ListProducts":               serviceauth.ScopeProductRead,
+	"/product.ProductService/CreateProduct":              serviceauth.ScopeProductWrite,
+	"/product.ProductService/UpdateProduct":              serviceauth.ScopeProductWrite,
+	"/product.ProductService/DeleteProduct":              serviceauth.ScopeProductWrite,
+	"/product.ProductService/ExportMyProducts":           serviceauth.ScopeProductRead,
+	"/product.ProductService/SetProductCategory":         serviceauth.ScopeProductWrite,
+	"/product.ProductService/GetCategory":                serviceauth.ScopeProductRead,
+	"/product.ProductService/ListCategories":             serviceauth.ScopeProductRead,
+	"/product.ProductService/CreateCategory":             serviceauth.ScopeProductWrite,
+	"/product.ProductService/UpdateCategory":             serviceauth.ScopeProductWrite,
+	"/product.ProductService/MoveCategory":               serviceauth.ScopeProductWrite,
+	"/product.ProductService/DeleteCategory":             serviceauth.ScopeProductWrite,
+	"/product.ProductService/CreateVariant":              serviceauth.ScopeProductWrite,
+	"/product.ProductService/UpdateVariant":              serviceauth.ScopeProductWrite,
+	"/product.ProductService/DeleteVariant":              serviceauth.ScopeProductWrite,
+	"/product.ProductService/ReserveStock":               serviceauth.ScopeProductWrite,
+	"/product.ProductService/ReleaseStock":               serviceauth.ScopeProductWrite,
+	"/product.ProductService/SetProductPrice":            serviceauth.ScopeProductWrite,
+	"/product.ProductService/DeleteProductPrice":         serviceauth.ScopeProductWrite,
+	"/product.ProductService/SetExchangeRate":            serviceauth.ScopeProductWrite,
+	"/product.ProductService/DeleteExchangeRate":         serviceauth.ScopeProductWrite,
+	"/product.ProductService/ListExchangeRates":          serviceauth.ScopeProductRead,
+	"/product.ProductService/ImportExchangeRates":        serviceauth.ScopeProductWrite,
+	"/product.ProductService/SetRoundingRule":            serviceauth.ScopeProductWrite,
+	"/product.ProductService/ListRoundingRules":          serviceauth.ScopeProductRead,
+	"/product.ProductService/GetPriceHistory":            serviceauth.ScopeProductRead,
+	"/product.ProductService/SchedulePriceChange":        serviceauth.ScopeProductWrite,
+	"/product.ProductService/ListScheduledPriceChanges":  serviceauth.ScopeProductRead,
+	"/product.ProductService/CancelScheduledPriceChange": serviceauth.ScopeProductWrite,
+}
+
+// NewGRPCProductServer uses plaintext when creds is nil, mtls.ServerCredentials otherwise
+func NewGRPCProductServer(address string, verifier *serviceauth.Verifier, mode serviceauth.Mode, creds credentials.TransportCredentials, tokenValidator domain.TokenValidator) *Server {
+	// create a new gRPC server, callers must present a service token and,
+	// for protected methods, the end user's token
+	serviceAuth := serviceauth.NewServerAuth(verifier, servicePolicy, mode)
+	userAuth := NewAuthInterceptor(tokenValidator, authRules)
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(serviceAuth.UnaryInterceptor(), userAuth.Unary()),
+		grpc.ChainStreamInterceptor(serviceAuth.StreamInterceptor(), userAuth.Stream()),
+	}
+	if creds != nil {
+		opts = append(opts, grpc.Creds(creds))
+	}
+	server := grpc.NewServer(opts...)
 
 	return &Server{
 		address: address,
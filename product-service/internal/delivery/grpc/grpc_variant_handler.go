@@ -0,0 +1,128 @@
+package grpc
+
+import (
+	"context"
+	pb "grpc/pb/product"
+	"product-service/internal/domain"
+	"time"
+)
+
+func (h *GRPCProductHandler) CreateVariant(ctx context.Context, req *pb.CreateVariantRequest) (*pb.ProductVariant, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	variant, err := h.variantUseCase.Create(tenantID, req.ProductId, req.Sku, req.Attributes, priceInput(req.UnitPrice, req.GetPrice()), req.Stock)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return h.convertVariant(tenantID, variant)
+}
+
+func (h *GRPCProductHandler) UpdateVariant(ctx context.Context, req *pb.UpdateVariantRequest) (*pb.ProductVariant, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	variant, err := h.variantUseCase.Update(tenantID, req.ProductId, req.Id, domain.VariantUpdate{
+		SKU:        req.Sku,
+		Attributes: req.Attributes,
+		Price:      priceInput(req.UnitPrice, req.GetPrice()),
+		ClearPrice: req.ClearPrice,
+		Stock:      req.Stock,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return h.convertVariant(tenantID, variant)
+}
+
+func (h *GRPCProductHandler) DeleteVariant(ctx context.Context, req *pb.DeleteVariantRequest) (*pb.DeleteVariantResponse, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.variantUseCase.Delete(tenantID, req.ProductId, req.Id); err != nil {
+		return &pb.DeleteVariantResponse{Success: false}, toStatus(err)
+	}
+
+	return &pb.DeleteVariantResponse{Success: true}, nil
+}
+
+func (h *GRPCProductHandler) ReserveStock(ctx context.Context, req *pb.StockRequest) (*pb.StockResponse, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := time.Duration(req.TtlSeconds) * time.Second
+	reservation, stock, err := h.variantUseCase.ReserveStock(tenantID, req.ProductId, req.VariantId, req.Quantity, ttl)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.StockResponse{Stock: stock, Reservation: convertToProtoReservation(reservation)}, nil
+}
+
+func (h *GRPCProductHandler) ReleaseStock(ctx context.Context, req *pb.StockRequest) (*pb.StockResponse, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reservation, stock, err := h.variantUseCase.ReleaseStock(tenantID, req.ProductId, req.ReservationId, req.Quantity)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.StockResponse{Stock: stock, Reservation: convertToProtoReservation(reservation)}, nil
+}
+
+// convertVariant needs the product price for variants without their own
+func (h *GRPCProductHandler) convertVariant(tenantID uint64, variant *domain.ProductVariant) (*pb.ProductVariant, error) {
+	product, err := h.productUseCase.GetByID(tenantID, variant.ProductID, "")
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return convertToProtoVariant(variant, product.Price), nil
+}
+
+func convertToProtoVariant(variant *domain.ProductVariant, productPrice domain.Money) *pb.ProductVariant {
+	price := variant.EffectivePrice(productPrice)
+
+	return &pb.ProductVariant{
+		Id:             variant.ID,
+		Sku:            variant.SKU,
+		Attributes:     variant.Attributes,
+		Price:          price.Float(),
+		UnitPrice:      convertToProtoMoney(price),
+		PriceOverride:  variant.PriceAmountMinor != nil,
+		RequestedPrice: convertToProtoMoneyPtr(variant.RequestedPrice),
+		PriceConverted: variant.PriceConverted,
+		Stock:          variant.Stock,
+		CreatedAt:      variant.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      variant.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func convertToProtoReservation(reservation *domain.StockReservation) *pb.StockReservation {
+	protoReservation := &pb.StockReservation{
+		Id:        reservation.ID,
+		ProductId: reservation.ProductID,
+		Quantity:  reservation.Quantity,
+		Status:    reservation.Status,
+		ExpiresAt: reservation.ExpiresAt.Format(time.RFC3339),
+		CreatedAt: reservation.CreatedAt.Format(time.RFC3339),
+	}
+	if reservation.VariantID != nil {
+		protoReservation.VariantId = *reservation.VariantID
+	}
+
+	return protoReservation
+}
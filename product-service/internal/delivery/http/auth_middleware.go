@@ -0,0 +1,93 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"product-service/internal/domain"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// authRules is keyed by "METHOD /route", routes that are not listed are
+// protected. Products belong to a tenant, so reads need a token too
+var authRules = map[string]domain.AuthRule{
+	"GET /products":                                         {Scope: domain.ScopeProductsRead},
+	"GET /products/:id":                                     {Scope: domain.ScopeProductsRead},
+	"POST /products":                                        {Scope: domain.ScopeProductsWrite},
+	"PUT /products/:id":                                     {Scope: domain.ScopeProductsWrite},
+	"DELETE /products/:id":                                  {Scope: domain.ScopeProductsWrite},
+	"PUT /products/:id/category":                            {Scope: domain.ScopeProductsWrite},
+	"GET /categories":                                       {Scope: domain.ScopeProductsRead},
+	"GET /categories/:id":                                   {Scope: domain.ScopeProductsRead},
+	"POST /categories":                                      {Scope: domain.ScopeProductsWrite},
+	"PUT /categories/:id":                                   {Scope: domain.ScopeProductsWrite},
+	"PUT /categories/:id/parent":                            {Scope: domain.ScopeProductsWrite},
+	"DELETE /categories/:id":                                {Scope: domain.ScopeProductsWrite},
+	"POST /products/:id/variants":                           {Scope: domain.ScopeProductsWrite},
+	"PUT /products/:id/variants/:variant_id":                {Scope: domain.ScopeProductsWrite},
+	"DELETE /products/:id/variants/:variant_id":             {Scope: domain.ScopeProductsWrite},
+	"POST /products/:id/stock/reserve":                      {Scope: domain.ScopeProductsWrite},
+	"POST /products/:id/stock/release":                      {Scope: domain.ScopeProductsWrite},
+	"PUT /products/:id/prices":                              {Scope: domain.ScopeProductsWrite},
+	"DELETE /products/:id/prices/:currency":                 {Scope: domain.ScopeProductsWrite},
+	"GET /exchange-rates":                                   {Admin: true},
+	"PUT /exchange-rates":                                   {Admin: true},
+	"POST /exchange-rates/import":                           {Admin: true},
+	"DELETE /exchange-rates/:from/:to":                      {Admin: true},
+	"GET /rounding-rules":                                   {Admin: true},
+	"PUT /rounding-rules/:currency":                         {Admin: true},
+	"GET /products/:id/price-history":                       {Scope: domain.ScopeProductsRead},
+	"POST /products/:id/scheduled-prices":                   {Scope: domain.ScopeProductsWrite},
+	"GET /products/:id/scheduled-prices":                    {Scope: domain.ScopeProductsRead},
+	"POST /products/:id/scheduled-prices/:change_id/cancel": {Scope: domain.ScopeProductsWrite},
+}
+
+// AuthMiddleware validates the bearer token and stores the user in the
+// request context, see domain.AuthUserFromContext
+func AuthMiddleware(validator domain.TokenValidator, rules map[string]domain.AuthRule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rule := rules[c.Request.Method+" "+c.FullPath()]
+
+		token := extractToken(c)
+		if token == "" && rule.Public {
+			c.Next()
+			return
+		}
+
+		user, err := validator.Validate(c.Request.Context(), token)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrMissingToken), errors.Is(err, domain.ErrInvalidToken):
+				c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "failed to validate token"})
+			}
+			c.Abort()
+			return
+		}
+
+		if rule.Scope != "" && !user.HasScope(rule.Scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "api key is missing the " + rule.Scope + " scope"})
+			c.Abort()
+			return
+		}
+		if rule.Admin && !user.IsAdminSession() {
+			c.JSON(http.StatusForbidden, gin.H{"error": domain.ErrAdminRequired.Error()})
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", user.ID)
+		c.Request = c.Request.WithContext(domain.ContextWithAuthUser(c.Request.Context(), user))
+		c.Next()
+	}
+}
+
+func extractToken(c *gin.Context) string {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok {
+		return c.GetHeader("X-API-Key")
+	}
+	return token
+}
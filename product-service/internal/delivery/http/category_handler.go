@@ -0,0 +1,176 @@
+package http
+
+import (
+	"net/http"
+	"product-service/internal/domain"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryHandler struct {
+	categoryUseCase domain.CategoryUseCase
+	tokenValidator  domain.TokenValidator
+}
+
+func NewCategoryHandler(categoryUseCase domain.CategoryUseCase, tokenValidator domain.TokenValidator) *CategoryHandler {
+	return &CategoryHandler{categoryUseCase: categoryUseCase, tokenValidator: tokenValidator}
+}
+
+type createCategoryRequest struct {
+	Name     string `json:"name" binding:"required"`
+	ParentID uint64 `json:"parent_id"`
+}
+
+type renameCategoryRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type moveCategoryRequest struct {
+	ParentID uint64 `json:"parent_id"` // 0 makes it a root category
+}
+
+func (h *CategoryHandler) Create(c *gin.Context) {
+	tenantID, ok := tenantID(c)
+	if !ok {
+		return
+	}
+
+	var req createCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := h.categoryUseCase.Create(tenantID, req.Name, req.ParentID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+func (h *CategoryHandler) GetByID(c *gin.Context) {
+	tenantID, ok := tenantID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	category, err := h.categoryUseCase.GetByID(tenantID, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+func (h *CategoryHandler) List(c *gin.Context) {
+	tenantID, ok := tenantID(c)
+	if !ok {
+		return
+	}
+
+	categories, err := h.categoryUseCase.List(tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": categories})
+}
+
+func (h *CategoryHandler) Rename(c *gin.Context) {
+	tenantID, ok := tenantID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req renameCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := h.categoryUseCase.Rename(tenantID, id, req.Name)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+func (h *CategoryHandler) Move(c *gin.Context) {
+	tenantID, ok := tenantID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req moveCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := h.categoryUseCase.Move(tenantID, id, req.ParentID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+func (h *CategoryHandler) Delete(c *gin.Context) {
+	tenantID, ok := tenantID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.categoryUseCase.Delete(tenantID, id); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "category deleted successfully"})
+}
+
+// routes category handler
+func (h *CategoryHandler) RegisterRoutes(router *gin.Engine) {
+	categories := router.Group("/categories")
+	categories.Use(AuthMiddleware(h.tokenValidator, authRules))
+	{
+		categories.POST("", h.Create)
+		categories.GET("", h.List)
+		categories.GET("/:id", h.GetByID)
+		categories.PUT("/:id", h.Rename)
+		categories.PUT("/:id/parent", h.Move)
+		categories.DELETE("/:id", h.Delete)
+	}
+}
@@ -0,0 +1,139 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"product-service/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportSize limits exchange rate files, 1000 lines fit easily
+const maxImportSize = 1 << 20
+
+// CurrencyHandler manages the exchange rates and rounding rules, they are
+// shared by all organizations so only admins can use it
+type CurrencyHandler struct {
+	currencyUseCase domain.CurrencyUseCase
+	tokenValidator  domain.TokenValidator
+}
+
+func NewCurrencyHandler(currencyUseCase domain.CurrencyUseCase, tokenValidator domain.TokenValidator) *CurrencyHandler {
+	return &CurrencyHandler{currencyUseCase: currencyUseCase, tokenValidator: tokenValidator}
+}
+
+type setExchangeRateRequest struct {
+	FromCurrency string `json:"from_currency" binding:"required"`
+	ToCurrency   string `json:"to_currency" binding:"required"`
+	Rate         string `json:"rate" binding:"required"`
+}
+
+type setRoundingRuleRequest struct {
+	IncrementMinor int64  `json:"increment_minor"`
+	Mode           string `json:"mode"`
+}
+
+func (h *CurrencyHandler) ListRates(c *gin.Context) {
+	rates, err := h.currencyUseCase.ListExchangeRates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": rates})
+}
+
+func (h *CurrencyHandler) SetRate(c *gin.Context) {
+	var req setExchangeRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rate, err := h.currencyUseCase.SetExchangeRate(req.FromCurrency, req.ToCurrency, req.Rate)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rate)
+}
+
+// ImportRates takes a multipart "file" or the CSV as the request body
+func (h *CurrencyHandler) ImportRates(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	var reader io.Reader = c.Request.Body
+	if file, _, err := c.Request.FormFile("file"); err == nil {
+		defer file.Close()
+		reader = file
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is too large"})
+		return
+	}
+
+	imported, err := h.currencyUseCase.ImportExchangeRates(data)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"imported": imported})
+}
+
+func (h *CurrencyHandler) DeleteRate(c *gin.Context) {
+	if err := h.currencyUseCase.DeleteExchangeRate(c.Param("from"), c.Param("to")); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "exchange rate deleted successfully"})
+}
+
+func (h *CurrencyHandler) ListRoundingRules(c *gin.Context) {
+	rules, err := h.currencyUseCase.ListRoundingRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": rules})
+}
+
+func (h *CurrencyHandler) SetRoundingRule(c *gin.Context) {
+	var req setRoundingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.currencyUseCase.SetRoundingRule(c.Param("currency"), req.IncrementMinor, req.Mode)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// routes currency handler
+func (h *CurrencyHandler) RegisterRoutes(router *gin.Engine) {
+	rates := router.Group("/exchange-rates")
+	rates.Use(AuthMiddleware(h.tokenValidator, authRules))
+	{
+		rates.GET("", h.ListRates)
+		rates.PUT("", h.SetRate)
+		rates.POST("/import", h.ImportRates)
+		rates.DELETE("/:from/:to", h.DeleteRate)
+	}
+
+	rules := router.Group("/rounding-rules")
+	rules.Use(AuthMiddleware(h.tokenValidator, authRules))
+	{
+		rules.GET("", h.ListRoundingRules)
+		rules.PUT("/:currency", h.SetRoundingRule)
+	}
+}
@@ -0,0 +1,141 @@
+package http
+
+import (
+	"net/http"
+	"product-service/internal/domain"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PriceHistoryHandler struct {
+	priceUseCase   domain.PriceHistoryUseCase
+	tokenValidator domain.TokenValidator
+}
+
+func NewPriceHistoryHandler(priceUseCase domain.PriceHistoryUseCase, tokenValidator domain.TokenValidator) *PriceHistoryHandler {
+	return &PriceHistoryHandler{priceUseCase: priceUseCase, tokenValidator: tokenValidator}
+}
+
+type schedulePriceRequest struct {
+	UnitPrice   domain.Money `json:"unit_price"` // currency_code defaults to the product currency
+	EffectiveAt time.Time    `json:"effective_at" binding:"required"`
+}
+
+func (h *PriceHistoryHandler) GetHistory(c *gin.Context) {
+	tenantID, ok := tenantID(c)
+	if !ok {
+		return
+	}
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	changes, total, err := h.priceUseCase.GetHistory(tenantID, productID, int32(page), int32(limit))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": changes,
+		"meta": gin.H{
+			"total": total,
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+func (h *PriceHistoryHandler) Schedule(c *gin.Context) {
+	tenantID, ok := tenantID(c)
+	if !ok {
+		return
+	}
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req schedulePriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	change, err := h.priceUseCase.Schedule(tenantID, productID, domain.AuthUserFromContext(c.Request.Context()).ID, req.UnitPrice, req.EffectiveAt)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, change)
+}
+
+func (h *PriceHistoryHandler) ListScheduled(c *gin.Context) {
+	tenantID, ok := tenantID(c)
+	if !ok {
+		return
+	}
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	changes, err := h.priceUseCase.ListScheduled(tenantID, productID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": changes})
+}
+
+func (h *PriceHistoryHandler) Cancel(c *gin.Context) {
+	tenantID, ok := tenantID(c)
+	if !ok {
+		return
+	}
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("change_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid change_id"})
+		return
+	}
+
+	change, err := h.priceUseCase.Cancel(tenantID, productID, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
+
+// routes price history handler
+func (h *PriceHistoryHandler) RegisterRoutes(router *gin.Engine) {
+	products := router.Group("/products/:id")
+	products.Use(AuthMiddleware(h.tokenValidator, authRules))
+	{
+		products.GET("/price-history", h.GetHistory)
+		products.POST("/scheduled-prices", h.Schedule)
+		products.GET("/scheduled-prices", h.ListScheduled)
+		products.POST("/scheduled-prices/:change_id/cancel", h.Cancel)
+	}
+}
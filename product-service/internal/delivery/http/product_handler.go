@@ -1,41 +1,46 @@
 package http
 
 import (
-	"context"
 	"errors"
 	"net/http"
 	"product-service/internal/domain"
 	"strconv"
 
-	authpb "grpc/pb/auth"
-
 	"github.com/gin-gonic/gin"
 )
 
 type ProductHandler struct {
 	productUseCase domain.ProductUseCase
-	authClient     authpb.AuthServiceClient
+	tokenValidator domain.TokenValidator
 }
 
-func NewProductHandler(productUseCase domain.ProductUseCase) *ProductHandler {
-	return &ProductHandler{productUseCase: productUseCase}
+func NewProductHandler(productUseCase domain.ProductUseCase, tokenValidator domain.TokenValidator) *ProductHandler {
+	return &ProductHandler{productUseCase: productUseCase, tokenValidator: tokenValidator}
 }
 
 type createProductRequest struct {
-	Name        string  `json:"name" binding:"required"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" binding:"required,gt=0"`
-	Stock       int32   `json:"stock" binding:"required,gte=0"`
+	Name        string        `json:"name" binding:"required"`
+	Description string        `json:"description"`
+	Price       float64       `json:"price" binding:"omitempty,gt=0"` // deprecated, use unit_price
+	UnitPrice   *domain.Money `json:"unit_price"`
+	Stock       int32         `json:"stock" binding:"required,gte=0"`
+	CategoryID  uint64        `json:"category_id"`
 }
 
 type updateProductRequest struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" binding:"omitempty,gt=0"`
-	Stock       int32   `json:"stock" binding:"omitempty,gte=0"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Price       float64       `json:"price" binding:"omitempty,gt=0"` // deprecated, use unit_price
+	UnitPrice   *domain.Money `json:"unit_price"`
+	Stock       int32         `json:"stock" binding:"omitempty,gte=0"`
 }
 
 func (h *ProductHandler) Create(c *gin.Context) {
+	tenantID, ok := tenantID(c)
+	if !ok {
+		return
+	}
+
 	var req createProductRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -43,13 +48,16 @@ func (h *ProductHandler) Create(c *gin.Context) {
 	}
 
 	product, err := h.productUseCase.Create(
+		tenantID,
+		domain.AuthUserFromContext(c.Request.Context()).ID,
+		req.CategoryID,
 		req.Name,
 		req.Description,
-		req.Price,
+		domain.PriceInput{Money: req.UnitPrice, Amount: req.Price},
 		req.Stock,
 	)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -57,15 +65,20 @@ func (h *ProductHandler) Create(c *gin.Context) {
 }
 
 func (h *ProductHandler) GetByID(c *gin.Context) {
+	tenantID, ok := tenantID(c)
+	if !ok {
+		return
+	}
+
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		return
 	}
 
-	product, err := h.productUseCase.GetByID(id)
+	product, err := h.productUseCase.GetByID(tenantID, id, c.Query("currency"))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -73,6 +86,11 @@ func (h *ProductHandler) GetByID(c *gin.Context) {
 }
 
 func (h *ProductHandler) Update(c *gin.Context) {
+	tenantID, ok := tenantID(c)
+	if !ok {
+		return
+	}
+
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
@@ -86,14 +104,16 @@ func (h *ProductHandler) Update(c *gin.Context) {
 	}
 
 	product, err := h.productUseCase.Update(
+		tenantID,
 		id,
+		domain.AuthUserFromContext(c.Request.Context()).ID,
 		req.Name,
 		req.Description,
-		req.Price,
+		domain.PriceInput{Money: req.UnitPrice, Amount: req.Price},
 		req.Stock,
 	)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -101,14 +121,23 @@ func (h *ProductHandler) Update(c *gin.Context) {
 }
 
 func (h *ProductHandler) Delete(c *gin.Context) {
+	tenantID, ok := tenantID(c)
+	if !ok {
+		return
+	}
+
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		return
 	}
 
-	err = h.productUseCase.Delete(id)
+	err = h.productUseCase.Delete(tenantID, id)
 	if err != nil {
+		if errors.Is(err, domain.ErrProductNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -117,13 +146,20 @@ func (h *ProductHandler) Delete(c *gin.Context) {
 }
 
 func (h *ProductHandler) List(c *gin.Context) {
+	tenantID, ok := tenantID(c)
+	if !ok {
+		return
+	}
+
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	search := c.DefaultQuery("search", "")
+	categoryID, _ := strconv.ParseUint(c.DefaultQuery("category_id", "0"), 10, 64)
+	currency := c.Query("currency")
 
-	products, total, err := h.productUseCase.List(int32(page), int32(limit), search)
+	products, total, err := h.productUseCase.List(tenantID, int32(page), int32(limit), search, categoryID, currency)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -138,25 +174,130 @@ func (h *ProductHandler) List(c *gin.Context) {
 	})
 }
 
+type setCategoryRequest struct {
+	CategoryID uint64 `json:"category_id"` // 0 removes the category
+}
+
+func (h *ProductHandler) SetCategory(c *gin.Context) {
+	tenantID, ok := tenantID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req setCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	product, err := h.productUseCase.SetCategory(tenantID, id, req.CategoryID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+func (h *ProductHandler) SetPrice(c *gin.Context) {
+	tenantID, ok := tenantID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req domain.Money
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	product, err := h.productUseCase.SetPrice(tenantID, id, req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+func (h *ProductHandler) DeletePrice(c *gin.Context) {
+	tenantID, ok := tenantID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	product, err := h.productUseCase.DeletePrice(tenantID, id, c.Param("currency"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// errorStatus maps product, category, variant, currency and price history errors, anything else is a 500
+func errorStatus(err error) int {
+	var validationErr *domain.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrProductNotFound), errors.Is(err, domain.ErrCategoryNotFound), errors.Is(err, domain.ErrVariantNotFound),
+		errors.Is(err, domain.ErrRateNotFound), errors.Is(err, domain.ErrScheduledChangeNotFound), errors.Is(err, domain.ErrReservationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrCategoryNameInvalid), errors.Is(err, domain.ErrCategoryNotEmpty), errors.Is(err, domain.ErrCategoryCycle),
+		errors.Is(err, domain.ErrVariantRequired), errors.Is(err, domain.ErrInvalidQuantity), errors.Is(err, domain.ErrInsufficientStock),
+		errors.Is(err, domain.ErrInvalidPrice), errors.Is(err, domain.ErrUnknownCurrency), errors.Is(err, domain.ErrCurrencyMismatch),
+		errors.Is(err, domain.ErrNoExchangeRate), errors.Is(err, domain.ErrInvalidRate), errors.Is(err, domain.ErrInvalidRoundingRule),
+		errors.Is(err, domain.ErrProductCurrency), errors.Is(err, domain.ErrScheduleInPast), errors.Is(err, domain.ErrScheduleCurrency),
+		errors.Is(err, domain.ErrScheduledChangeDone), errors.Is(err, domain.ErrReservationClosed), errors.Is(err, domain.ErrReleaseTooLarge):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrSKUTaken), errors.Is(err, domain.ErrVariantExists):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// tenantID is the caller's active organization, products are scoped to it
+func tenantID(c *gin.Context) (uint64, bool) {
+	user := domain.AuthUserFromContext(c.Request.Context())
+	if user == nil || user.OrganizationID == 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": domain.ErrNoTenant.Error()})
+		return 0, false
+	}
+
+	return user.OrganizationID, true
+}
+
 // routes product handler
 func (h *ProductHandler) RegisterRoutes(router *gin.Engine) {
 	products := router.Group("/products")
+	products.Use(AuthMiddleware(h.tokenValidator, authRules))
 	{
 		products.POST("", h.Create)
 		products.GET("", h.List)
 		products.GET("/:id", h.GetByID)
 		products.PUT("/:id", h.Update)
 		products.DELETE("/:id", h.Delete)
+		products.PUT("/:id/category", h.SetCategory)
+		products.PUT("/:id/prices", h.SetPrice)
+		products.DELETE("/:id/prices/:currency", h.DeletePrice)
 	}
 }
-
-func (h *ProductHandler) validateToken(token string) error {
-	resp, err := h.authClient.Validate(context.Background(), &authpb.ValidateRequest{
-		Token: token,
-	})
-	if err != nil || !resp.Valid {
-		return errors.New("invalid token")
-	}
-
-	return nil
-}
@@ -0,0 +1,209 @@
+package http
+
+import (
+	"net/http"
+	"product-service/internal/domain"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VariantHandler struct {
+	variantUseCase domain.VariantUseCase
+	tokenValidator domain.TokenValidator
+}
+
+func NewVariantHandler(variantUseCase domain.VariantUseCase, tokenValidator domain.TokenValidator) *VariantHandler {
+	return &VariantHandler{variantUseCase: variantUseCase, tokenValidator: tokenValidator}
+}
+
+type createVariantRequest struct {
+	SKU        string            `json:"sku" binding:"required"`
+	Attributes map[string]string `json:"attributes" binding:"required"`
+	Price      float64           `json:"price"`      // deprecated, use unit_price
+	UnitPrice  *domain.Money     `json:"unit_price"` // omitted uses the product price
+	Stock      int32             `json:"stock" binding:"gte=0"`
+}
+
+type updateVariantRequest struct {
+	SKU        *string           `json:"sku"`
+	Attributes map[string]string `json:"attributes"` // replaces all attributes
+	Price      float64           `json:"price"`      // deprecated, use unit_price
+	UnitPrice  *domain.Money     `json:"unit_price"`
+	ClearPrice bool              `json:"clear_price"`
+	Stock      *int32            `json:"stock"`
+}
+
+type reserveStockRequest struct {
+	VariantID  uint64 `json:"variant_id"`
+	Quantity   int32  `json:"quantity" binding:"required,gt=0"`
+	TTLSeconds int32  `json:"ttl_seconds" binding:"gte=0"` // 0 is 15 minutes
+}
+
+type releaseStockRequest struct {
+	ReservationID uint64 `json:"reservation_id" binding:"required"`
+	Quantity      int32  `json:"quantity" binding:"gte=0"` // 0 releases all of it
+}
+
+func (h *VariantHandler) Create(c *gin.Context) {
+	tenantID, ok := tenantID(c)
+	if !ok {
+		return
+	}
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req createVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	variant, err := h.variantUseCase.Create(tenantID, productID, req.SKU, req.Attributes, domain.PriceInput{Money: req.UnitPrice, Amount: req.Price}, req.Stock)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, variant)
+}
+
+func (h *VariantHandler) Update(c *gin.Context) {
+	tenantID, ok := tenantID(c)
+	if !ok {
+		return
+	}
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("variant_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid variant_id"})
+		return
+	}
+
+	var req updateVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	variant, err := h.variantUseCase.Update(tenantID, productID, id, domain.VariantUpdate{
+		SKU:        req.SKU,
+		Attributes: req.Attributes,
+		Price:      domain.PriceInput{Money: req.UnitPrice, Amount: req.Price},
+		ClearPrice: req.ClearPrice,
+		Stock:      req.Stock,
+	})
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, variant)
+}
+
+func (h *VariantHandler) Delete(c *gin.Context) {
+	tenantID, ok := tenantID(c)
+	if !ok {
+		return
+	}
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("variant_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid variant_id"})
+		return
+	}
+
+	if err := h.variantUseCase.Delete(tenantID, productID, id); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "variant deleted successfully"})
+}
+
+func (h *VariantHandler) ReserveStock(c *gin.Context) {
+	tenantID, productID, ok := stockTarget(c)
+	if !ok {
+		return
+	}
+
+	var req reserveStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ttl := time.Duration(req.TTLSeconds) * time.Second
+	reservation, stock, err := h.variantUseCase.ReserveStock(tenantID, productID, req.VariantID, req.Quantity, ttl)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stock": stock, "reservation": reservation})
+}
+
+func (h *VariantHandler) ReleaseStock(c *gin.Context) {
+	tenantID, productID, ok := stockTarget(c)
+	if !ok {
+		return
+	}
+
+	var req releaseStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reservation, stock, err := h.variantUseCase.ReleaseStock(tenantID, productID, req.ReservationID, req.Quantity)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stock": stock, "reservation": reservation})
+}
+
+// stockTarget reads the tenant and the product of a stock request
+func stockTarget(c *gin.Context) (uint64, uint64, bool) {
+	tenantID, ok := tenantID(c)
+	if !ok {
+		return 0, 0, false
+	}
+
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return 0, 0, false
+	}
+
+	return tenantID, productID, true
+}
+
+// routes variant handler, variants live below their product
+func (h *VariantHandler) RegisterRoutes(router *gin.Engine) {
+	products := router.Group("/products/:id")
+	products.Use(AuthMiddleware(h.tokenValidator, authRules))
+	{
+		products.POST("/variants", h.Create)
+		products.PUT("/variants/:variant_id", h.Update)
+		products.DELETE("/variants/:variant_id", h.Delete)
+		products.POST("/stock/reserve", h.ReserveStock)
+		products.POST("/stock/release", h.ReleaseStock)
+	}
+}
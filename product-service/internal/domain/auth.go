@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+// API key scopes, user sessions are not limited by scopes
+const (
+	ScopeProductsRead  = "products:read"
+	ScopeProductsWrite = "products:write"
+)
+
+// auth-service role of platform admins
+const RoleAdmin = "admin"
+
+var (
+	ErrMissingToken  = errors.New("missing token")
+	ErrInvalidToken  = errors.New("invalid token")
+	ErrAdminRequired = errors.New("admin session required")
+)
+
+// AuthUser is the end user behind a request, resolved from the bearer token
+type AuthUser struct {
+	ID   uint64
+	Role string
+	// "jwt" or "api_key"
+	AuthType string
+	// scopes granted to an API key, empty for user sessions
+	Scopes []string
+	// active organization, used as the product tenant, 0 when none
+	OrganizationID   uint64
+	OrganizationRole string
+}
+
+// IsAdminSession is an admin signed in with a session, admin API keys are
+// limited like any other key
+func (u *AuthUser) IsAdminSession() bool {
+	return u.Role == RoleAdmin && u.AuthType != "api_key"
+}
+
+// HasScope always allows user sessions, API keys need the scope
+func (u *AuthUser) HasScope(scope string) bool {
+	if u.AuthType != "api_key" {
+		return true
+	}
+	for _, s := range u.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthRule configures a gRPC method or HTTP route. Public ones can be called
+// without a token, Scope is what an API key needs and Admin ones need an
+// admin session
+type AuthRule struct {
+	Public bool
+	Scope  string
+	Admin  bool
+}
+
+// TokenValidator resolves a user token (JWT or API key) to the user
+type TokenValidator interface {
+	Validate(ctx context.Context, token string) (*AuthUser, error)
+}
+
+type authUserKey struct{}
+
+func ContextWithAuthUser(ctx context.Context, user *AuthUser) context.Context {
+	return context.WithValue(ctx, authUserKey{}, user)
+}
+
+// AuthUserFromContext returns nil for public endpoints called without a token
+func AuthUserFromContext(ctx context.Context) *AuthUser {
+	user, _ := ctx.Value(authUserKey{}).(*AuthUser)
+	return user
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCategoryNotFound    = errors.New("category not found")
+	ErrCategoryNameInvalid = errors.New("category name must be between 1 and 100 characters")
+	ErrCategoryNotEmpty    = errors.New("category still has subcategories or products")
+	ErrCategoryCycle       = errors.New("category can't be moved below itself")
+)
+
+// Category is a node in a tenant's category tree. Path holds the ids from the
+// root down to the category itself, e.g. "/1/4/9/", so a subtree is every
+// category whose path starts with the path of its root
+type Category struct {
+	ID        uint64    `gorm:"primaryKey" json:"id"`
+	TenantID  uint64    `gorm:"index:idx_categories_tenant_path;not null" json:"tenant_id"`
+	ParentID  *uint64   `gorm:"index" json:"parent_id"`
+	Name      string    `gorm:"size:100;not null" json:"name"`
+	Path      string    `gorm:"type:text;index:idx_categories_tenant_path;not null;default:''" json:"path"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Depth is 0 for root categories
+func (c *Category) Depth() int {
+	return strings.Count(c.Path, "/") - 2
+}
+
+// CategoryRepository scopes every call to a tenant. Create, Move and Delete
+// run in a transaction so the paths of a tree stay consistent
+type CategoryRepository interface {
+	Create(category *Category) error
+	FindByID(tenantID, id uint64) (*Category, error)
+	// List is ordered by path, every parent comes before its children
+	List(tenantID uint64) ([]Category, error)
+	Rename(tenantID, id uint64, name string) (*Category, error)
+	// Move places a category and its subtree below parentID, 0 makes it a root
+	Move(tenantID, id, parentID uint64) (*Category, error)
+	// Delete refuses categories with subcategories or products
+	Delete(tenantID, id uint64) error
+}
+
+type CategoryUseCase interface {
+	Create(tenantID uint64, name string, parentID uint64) (*Category, error)
+	GetByID(tenantID, id uint64) (*Category, error)
+	List(tenantID uint64) ([]Category, error)
+	Rename(tenantID, id uint64, name string) (*Category, error)
+	Move(tenantID, id, parentID uint64) (*Category, error)
+	Delete(tenantID, id uint64) error
+}
@@ -0,0 +1,184 @@
+package domain
+
+import (
+	"errors"
+	"math/big"
+	"time"
+)
+
+var (
+	ErrNoExchangeRate      = errors.New("no exchange rate for this currency pair")
+	ErrRateNotFound        = errors.New("exchange rate not found")
+	ErrInvalidRate         = errors.New("rate must be a positive decimal with at most 12 decimal places")
+	ErrInvalidRoundingRule = errors.New("rounding increment must be at least 1 and mode half_up, up or down")
+	ErrProductCurrency     = errors.New("the price in the product currency is set with unit_price")
+)
+
+// rounding modes for converted prices
+const (
+	RoundHalfUp = "half_up"
+	RoundUp     = "up"
+	RoundDown   = "down"
+)
+
+// ExchangeRate converts From into To, one unit of From is Rate units of To.
+// The inverse pair is used when only one direction is stored
+type ExchangeRate struct {
+	From      string    `gorm:"column:from_currency;primaryKey;size:3" json:"from_currency"`
+	To        string    `gorm:"column:to_currency;primaryKey;size:3" json:"to_currency"`
+	Rate      string    `gorm:"type:numeric(30,12);not null" json:"rate"` // exact decimal
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RoundingRule rounds converted prices of a currency to a multiple of
+// Increment minor units, e.g. 10000 rounds IDR to whole hundreds of rupiah
+type RoundingRule struct {
+	Currency  string `gorm:"primaryKey;size:3" json:"currency_code"`
+	Increment int64  `gorm:"not null;default:1" json:"increment_minor"`
+	Mode      string `gorm:"size:10;not null;default:half_up" json:"mode"`
+}
+
+// DefaultRoundingRule rounds to the nearest minor unit
+func DefaultRoundingRule(currency string) RoundingRule {
+	return RoundingRule{Currency: currency, Increment: 1, Mode: RoundHalfUp}
+}
+
+// Round returns amount, in minor units, as a multiple of the increment
+func (r RoundingRule) Round(amount *big.Rat) int64 {
+	steps := new(big.Rat).Quo(amount, new(big.Rat).SetInt64(r.Increment))
+
+	// floor of a non-negative fraction
+	quotient, remainder := new(big.Int).QuoRem(steps.Num(), steps.Denom(), new(big.Int))
+	if remainder.Sign() != 0 {
+		switch r.Mode {
+		case RoundUp:
+			quotient.Add(quotient, big.NewInt(1))
+		case RoundHalfUp:
+			// remainder/denom >= 1/2
+			if new(big.Int).Mul(remainder, big.NewInt(2)).Cmp(steps.Denom()) >= 0 {
+				quotient.Add(quotient, big.NewInt(1))
+			}
+		}
+	}
+
+	return quotient.Int64() * r.Increment
+}
+
+// ProductPrice is an explicit price in a currency other than the product's
+// own, it wins over a converted price
+type ProductPrice struct {
+	ProductID   uint64    `gorm:"primaryKey" json:"-"`
+	Currency    string    `gorm:"primaryKey;size:3" json:"currency_code"`
+	TenantID    uint64    `gorm:"index;not null" json:"-"`
+	AmountMinor int64     `gorm:"not null" json:"amount_minor"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CurrencyConverter converts prices into one currency with the rates that
+// were current when it was created
+type CurrencyConverter struct {
+	Currency string
+	// one unit of the source currency in Currency
+	rates map[string]*big.Rat
+	rule  RoundingRule
+}
+
+func NewCurrencyConverter(currency string, rates []ExchangeRate, rule RoundingRule) *CurrencyConverter {
+	converter := &CurrencyConverter{Currency: currency, rates: make(map[string]*big.Rat), rule: rule}
+	for _, rate := range rates {
+		value, ok := new(big.Rat).SetString(rate.Rate)
+		if !ok || value.Sign() <= 0 {
+			continue
+		}
+		switch {
+		case rate.To == currency:
+			converter.rates[rate.From] = value
+		case rate.From == currency:
+			// a stored direct rate wins over the inverse
+			if _, exists := converter.rates[rate.To]; !exists {
+				converter.rates[rate.To] = new(big.Rat).Inv(value)
+			}
+		}
+	}
+
+	return converter
+}
+
+// Convert rounds with the currency's rounding rule
+func (c *CurrencyConverter) Convert(money Money) (Money, error) {
+	if money.Currency == c.Currency {
+		return money, nil
+	}
+	rate, ok := c.rates[money.Currency]
+	if !ok {
+		return Money{}, ErrNoExchangeRate
+	}
+	fromExponent, _ := CurrencyExponent(money.Currency)
+	toExponent, _ := CurrencyExponent(c.Currency)
+
+	amount := new(big.Rat).SetInt64(money.AmountMinor)
+	amount.Mul(amount, rate)
+	amount.Mul(amount, new(big.Rat).SetFrac(pow10(toExponent), pow10(fromExponent)))
+
+	return Money{AmountMinor: c.rule.Round(amount), Currency: c.Currency}, nil
+}
+
+// ProductPrice prefers the product's own and explicit prices, converted
+// reports whether the price came from an exchange rate
+func (c *CurrencyConverter) ProductPrice(product *Product) (price Money, converted bool, err error) {
+	if product.Price.Currency == c.Currency {
+		return product.Price, false, nil
+	}
+	for _, explicit := range product.Prices {
+		if explicit.Currency == c.Currency {
+			return Money{AmountMinor: explicit.AmountMinor, Currency: c.Currency}, false, nil
+		}
+	}
+
+	price, err = c.Convert(product.Price)
+	return price, true, err
+}
+
+// VariantPrice converts a variant's own price, variants without one cost
+// the product price
+func (c *CurrencyConverter) VariantPrice(product *Product, variant *ProductVariant) (price Money, converted bool, err error) {
+	if variant.PriceAmountMinor == nil {
+		return c.ProductPrice(product)
+	}
+
+	own := variant.EffectivePrice(product.Price)
+	price, err = c.Convert(own)
+	return price, own.Currency != c.Currency, err
+}
+
+func pow10(exponent int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+}
+
+// CurrencyRepository holds the exchange rates and rounding rules, they are
+// shared by all tenants
+type CurrencyRepository interface {
+	SetRate(rate *ExchangeRate) error
+	// ImportRates stores all rates or none
+	ImportRates(rates []ExchangeRate) error
+	DeleteRate(from, to string) error
+	ListRates() ([]ExchangeRate, error)
+	// RatesFor returns the rates into or out of currency
+	RatesFor(currency string) ([]ExchangeRate, error)
+	SetRoundingRule(rule *RoundingRule) error
+	// RoundingRule returns DefaultRoundingRule when none is stored
+	RoundingRule(currency string) (RoundingRule, error)
+	ListRoundingRules() ([]RoundingRule, error)
+}
+
+// CurrencyUseCase maintains the exchange rates and rounding rules, callers
+// must be admins
+type CurrencyUseCase interface {
+	SetExchangeRate(from, to, rate string) (*ExchangeRate, error)
+	DeleteExchangeRate(from, to string) error
+	ListExchangeRates() ([]ExchangeRate, error)
+	// ImportExchangeRates reads "from,to,rate" lines and returns how many it stored
+	ImportExchangeRates(data []byte) (int, error)
+	SetRoundingRule(currency string, increment int64, mode string) (*RoundingRule, error)
+	ListRoundingRules() ([]RoundingRule, error)
+}
@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestRoundingRuleRound(t *testing.T) {
+	tests := []struct {
+		name      string
+		increment int64
+		mode      string
+		amount    string
+		want      int64
+	}{
+		{"half up below half", 1, RoundHalfUp, "6244/10", 624},
+		{"half up at half", 1, RoundHalfUp, "1249/2", 625},
+		{"half up above half", 1, RoundHalfUp, "12491/20", 625},
+		{"up", 1, RoundUp, "6241/10", 625},
+		{"up exact", 1, RoundUp, "624", 624},
+		{"down", 1, RoundDown, "6249/10", 624},
+		{"half up increment below half", 100, RoundHalfUp, "12349", 12300},
+		{"half up increment at half", 100, RoundHalfUp, "12350", 12400},
+		{"up increment", 100, RoundUp, "12301", 12400},
+		{"up increment exact", 100, RoundUp, "12300", 12300},
+		{"down increment", 100, RoundDown, "12399", 12300},
+		{"fraction of a minor unit", 5, RoundHalfUp, "25/2", 15},
+		{"zero", 100, RoundUp, "0", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, ok := new(big.Rat).SetString(tt.amount)
+			if !ok {
+				t.Fatalf("invalid amount %q", tt.amount)
+			}
+
+			rule := RoundingRule{Currency: "USD", Increment: tt.increment, Mode: tt.mode}
+			if got := rule.Round(amount); got != tt.want {
+				t.Errorf("Round(%s) = %d, want %d", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurrencyConverterConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		currency string
+		rates    []ExchangeRate
+		rule     RoundingRule
+		money    Money
+		want     Money
+		wantErr  error
+	}{
+		{
+			name:     "same currency",
+			currency: "USD",
+			rule:     DefaultRoundingRule("USD"),
+			money:    Money{AmountMinor: 1250, Currency: "USD"},
+			want:     Money{AmountMinor: 1250, Currency: "USD"},
+		},
+		{
+			name:     "no rate",
+			currency: "IDR",
+			rates:    []ExchangeRate{{From: "EUR", To: "IDR", Rate: "17000"}},
+			rule:     DefaultRoundingRule("IDR"),
+			money:    Money{AmountMinor: 1250, Currency: "USD"},
+			wantErr:  ErrNoExchangeRate,
+		},
+		{
+			name:     "invalid rates are skipped",
+			currency: "IDR",
+			rates: []ExchangeRate{
+				{From: "USD", To: "IDR", Rate: "abc"},
+				{From: "USD", To: "IDR", Rate: "0"},
+				{From: "IDR", To: "USD", Rate: "-1"},
+			},
+			rule:    DefaultRoundingRule("IDR"),
+			money:   Money{AmountMinor: 1250, Currency: "USD"},
+			wantErr: ErrNoExchangeRate,
+		},
+		{
+			name:     "half up increment",
+			currency: "IDR",
+			rates:    []ExchangeRate{{From: "USD", To: "IDR", Rate: "15436"}},
+			rule:     RoundingRule{Currency: "IDR", Increment: 10000, Mode: RoundHalfUp},
+			money:    Money{AmountMinor: 1250, Currency: "USD"},
+			want:     Money{AmountMinor: 19300000, Currency: "IDR"},
+		},
+		{
+			name:     "up increment",
+			currency: "IDR",
+			rates:    []ExchangeRate{{From: "USD", To: "IDR", Rate: "15432.1"}},
+			rule:     RoundingRule{Currency: "IDR", Increment: 10000, Mode: RoundUp},
+			money:    Money{AmountMinor: 1250, Currency: "USD"},
+			want:     Money{AmountMinor: 19300000, Currency: "IDR"},
+		},
+		{
+			name:     "down increment",
+			currency: "IDR",
+			rates:    []ExchangeRate{{From: "USD", To: "IDR", Rate: "15436"}},
+			rule:     RoundingRule{Currency: "IDR", Increment: 10000, Mode: RoundDown},
+			money:    Money{AmountMinor: 1250, Currency: "USD"},
+			want:     Money{AmountMinor: 19290000, Currency: "IDR"},
+		},
+		{
+			name:     "into a zero digit currency",
+			currency: "JPY",
+			rates:    []ExchangeRate{{From: "USD", To: "JPY", Rate: "150.5"}},
+			rule:     DefaultRoundingRule("JPY"),
+			money:    Money{AmountMinor: 1999, Currency: "USD"},
+			want:     Money{AmountMinor: 3008, Currency: "JPY"},
+		},
+		{
+			name:     "into a zero digit currency rounding up",
+			currency: "JPY",
+			rates:    []ExchangeRate{{From: "USD", To: "JPY", Rate: "150.5"}},
+			rule:     RoundingRule{Currency: "JPY", Increment: 1, Mode: RoundUp},
+			money:    Money{AmountMinor: 1999, Currency: "USD"},
+			want:     Money{AmountMinor: 3009, Currency: "JPY"},
+		},
+		{
+			name:     "from a zero digit into a three digit currency",
+			currency: "KWD",
+			rates:    []ExchangeRate{{From: "JPY", To: "KWD", Rate: "0.002"}},
+			rule:     DefaultRoundingRule("KWD"),
+			money:    Money{AmountMinor: 1000, Currency: "JPY"},
+			want:     Money{AmountMinor: 2000, Currency: "KWD"},
+		},
+		{
+			name:     "inverse rate",
+			currency: "IDR",
+			rates:    []ExchangeRate{{From: "IDR", To: "USD", Rate: "0.0001"}},
+			rule:     DefaultRoundingRule("IDR"),
+			money:    Money{AmountMinor: 100, Currency: "USD"},
+			want:     Money{AmountMinor: 1000000, Currency: "IDR"},
+		},
+		{
+			name:     "direct rate wins over a later inverse",
+			currency: "IDR",
+			rates: []ExchangeRate{
+				{From: "USD", To: "IDR", Rate: "15000"},
+				{From: "IDR", To: "USD", Rate: "0.0001"},
+			},
+			rule:  DefaultRoundingRule("IDR"),
+			money: Money{AmountMinor: 100, Currency: "USD"},
+			want:  Money{AmountMinor: 1500000, Currency: "IDR"},
+		},
+		{
+			name:     "direct rate wins over an earlier inverse",
+			currency: "IDR",
+			rates: []ExchangeRate{
+				{From: "IDR", To: "USD", Rate: "0.0001"},
+				{From: "USD", To: "IDR", Rate: "15000"},
+			},
+			rule:  DefaultRoundingRule("IDR"),
+			money: Money{AmountMinor: 100, Currency: "USD"},
+			want:  Money{AmountMinor: 1500000, Currency: "IDR"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter := NewCurrencyConverter(tt.currency, tt.rates, tt.rule)
+
+			got, err := converter.Convert(tt.money)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Convert() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Convert() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+var (
+	ErrUnknownCurrency  = errors.New("unknown ISO 4217 currency code")
+	ErrInvalidPrice     = errors.New("price must be greater than 0")
+	ErrCurrencyMismatch = errors.New("variant prices must use the product currency")
+)
+
+// Money is an exact amount in the minor unit of an ISO 4217 currency,
+// 1250 USD is 12.50 and 1250 JPY is 1250 yen
+type Money struct {
+	AmountMinor int64  `gorm:"not null;default:0" json:"amount_minor"`
+	Currency    string `gorm:"size:3;not null;default:''" json:"currency_code"`
+}
+
+// Float is only for the deprecated floating point price fields
+func (m Money) Float() float64 {
+	exponent, _ := CurrencyExponent(m.Currency)
+	return float64(m.AmountMinor) / math.Pow10(exponent)
+}
+
+// MoneyFromFloat converts a deprecated floating point price, it is rounded
+// to the nearest minor unit
+func MoneyFromFloat(amount float64, currency string) (Money, error) {
+	currency = strings.ToUpper(currency)
+	exponent, ok := CurrencyExponent(currency)
+	if !ok {
+		return Money{}, ErrUnknownCurrency
+	}
+
+	return Money{AmountMinor: int64(math.Round(amount * math.Pow10(exponent))), Currency: currency}, nil
+}
+
+// PriceInput is a price sent by a client, either Money or the deprecated
+// floating point Amount, which is read in the product's currency
+type PriceInput struct {
+	Money  *Money
+	Amount float64
+}
+
+func (p PriceInput) IsSet() bool {
+	return p.Money != nil || p.Amount != 0
+}
+
+// CurrencyExponent returns the number of minor unit digits of an active
+// ISO 4217 currency
+func CurrencyExponent(currency string) (int, bool) {
+	if exponent, ok := currencyExponents[currency]; ok {
+		return exponent, true
+	}
+	if twoDigitCurrencies[currency] {
+		return 2, true
+	}
+	return 0, false
+}
+
+// currencies whose minor unit is not cents
+var currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0,
+	"XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+var twoDigitCurrencies = map[string]bool{
+	"AED": true, "AFN": true, "ALL": true, "AMD": true, "ANG": true, "AOA": true, "ARS": true, "AUD": true,
+	"AWG": true, "AZN": true, "BAM": true, "BBD": true, "BDT": true, "BGN": true, "BMD": true, "BND": true,
+	"BOB": true, "BRL": true, "BSD": true, "BTN": true, "BWP": true, "BYN": true, "BZD": true, "CAD": true,
+	"CDF": true, "CHF": true, "CNY": true, "COP": true, "CRC": true, "CUP": true, "CVE": true, "CZK": true,
+	"DKK": true, "DOP": true, "DZD": true, "EGP": true, "ERN": true, "ETB": true, "EUR": true, "FJD": true,
+	"FKP": true, "GBP": true, "GEL": true, "GHS": true, "GIP": true, "GMD": true, "GTQ": true, "GYD": true,
+	"HKD": true, "HNL": true, "HTG": true, "HUF": true, "IDR": true, "ILS": true, "INR": true, "IRR": true,
+	"JMD": true, "KES": true, "KGS": true, "KHR": true, "KPW": true, "KYD": true, "KZT": true, "LAK": true,
+	"LBP": true, "LKR": true, "LRD": true, "LSL": true, "MAD": true, "MDL": true, "MGA": true, "MKD": true,
+	"MMK": true, "MNT": true, "MOP": true, "MRU": true, "MUR": true, "MVR": true, "MWK": true, "MXN": true,
+	"MYR": true, "MZN": true, "NAD": true, "NGN": true, "NIO": true, "NOK": true, "NPR": true, "NZD": true,
+	"PAB": true, "PEN": true, "PGK": true, "PHP": true, "PKR": true, "PLN": true, "QAR": true, "RON": true,
+	"RSD": true, "RUB": true, "SAR": true, "SBD": true, "SCR": true, "SDG": true, "SEK": true, "SGD": true,
+	"SHP": true, "SLE": true, "SOS": true, "SRD": true, "SSP": true, "STN": true, "SVC": true, "SYP": true,
+	"SZL": true, "THB": true, "TJS": true, "TMT": true, "TOP": true, "TRY": true, "TTD": true, "TWD": true,
+	"TZS": true, "UAH": true, "USD": true, "UZS": true, "VES": true, "WST": true, "XCD": true, "YER": true,
+	"ZAR": true, "ZMW": true, "ZWG": true,
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCurrencyExponent(t *testing.T) {
+	tests := []struct {
+		currency string
+		want     int
+		wantOK   bool
+	}{
+		{"USD", 2, true},
+		{"IDR", 2, true},
+		{"JPY", 0, true},
+		{"KRW", 0, true},
+		{"KWD", 3, true},
+		{"BHD", 3, true},
+		{"CLF", 4, true},
+		{"usd", 0, false},
+		{"XXX", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.currency, func(t *testing.T) {
+			got, ok := CurrencyExponent(tt.currency)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("CurrencyExponent(%q) = %d, %v, want %d, %v", tt.currency, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestMoneyFromFloat(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   float64
+		currency string
+		want     Money
+		wantErr  error
+	}{
+		{"cents", 12.5, "USD", Money{AmountMinor: 1250, Currency: "USD"}, nil},
+		{"binary fraction below the cent", 19.99, "USD", Money{AmountMinor: 1999, Currency: "USD"}, nil},
+		{"sum of binary fractions", 0.1 + 0.2, "USD", Money{AmountMinor: 30, Currency: "USD"}, nil},
+		{"rounds to the nearest cent", 12.345678, "EUR", Money{AmountMinor: 1235, Currency: "EUR"}, nil},
+		{"zero digit currency", 1250, "JPY", Money{AmountMinor: 1250, Currency: "JPY"}, nil},
+		{"zero digit currency rounds", 1250.6, "JPY", Money{AmountMinor: 1251, Currency: "JPY"}, nil},
+		{"three digit currency", 1.234, "KWD", Money{AmountMinor: 1234, Currency: "KWD"}, nil},
+		{"lower case currency", 12.5, "usd", Money{AmountMinor: 1250, Currency: "USD"}, nil},
+		{"unknown currency", 12.5, "XXX", Money{}, ErrUnknownCurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MoneyFromFloat(tt.amount, tt.currency)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("MoneyFromFloat() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("MoneyFromFloat(%v, %q) = %+v, want %+v", tt.amount, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
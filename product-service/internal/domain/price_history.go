@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrScheduledChangeNotFound = errors.New("scheduled price change not found")
+	ErrScheduleInPast          = errors.New("effective_at must be in the future")
+	ErrScheduledChangeDone     = errors.New("only pending price changes can be cancelled")
+	ErrScheduleCurrency        = errors.New("scheduled prices must use the product currency")
+)
+
+// sources of a price change
+const (
+	PriceSourceCreate   = "create"
+	PriceSourceUpdate   = "update"
+	PriceSourceSchedule = "schedule"
+	// prices of products created before the history existed
+	PriceSourceBackfill = "backfill"
+)
+
+// states of a scheduled price change
+const (
+	ScheduleStatusPending   = "pending"
+	ScheduleStatusApplied   = "applied"
+	ScheduleStatusCancelled = "cancelled"
+	ScheduleStatusFailed    = "failed"
+)
+
+// PriceChange is one entry of the price history of a product, the price was
+// in effect from EffectiveFrom until EffectiveTo. The current price has no
+// EffectiveTo
+type PriceChange struct {
+	ID            uint64     `gorm:"primaryKey" json:"id"`
+	TenantID      uint64     `gorm:"not null" json:"-"`
+	ProductID     uint64     `gorm:"index:idx_price_changes_product;not null" json:"product_id"`
+	Price         Money      `gorm:"embedded;embeddedPrefix:price_" json:"unit_price"`
+	EffectiveFrom time.Time  `gorm:"index:idx_price_changes_product;not null" json:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to"`
+	ChangedBy     uint64     `gorm:"index;not null;default:0" json:"changed_by"` // 0 once the user was erased
+	Source        string     `gorm:"size:20;not null" json:"source"`
+	// set when Source is PriceSourceSchedule
+	ScheduledChangeID *uint64 `json:"scheduled_change_id,omitempty"`
+}
+
+// ScheduledPriceChange sets the product price at EffectiveAt. The scheduler
+// applies it once, the status changes in the same transaction as the price
+type ScheduledPriceChange struct {
+	ID          uint64     `gorm:"primaryKey" json:"id"`
+	TenantID    uint64     `gorm:"not null" json:"-"`
+	ProductID   uint64     `gorm:"index;not null" json:"product_id"`
+	Price       Money      `gorm:"embedded;embeddedPrefix:price_" json:"unit_price"`
+	EffectiveAt time.Time  `gorm:"index:idx_scheduled_price_changes_due,priority:2;not null" json:"effective_at"`
+	Status      string     `gorm:"size:20;index:idx_scheduled_price_changes_due,priority:1;not null" json:"status"`
+	Error       string     `gorm:"type:text" json:"error,omitempty"`
+	AppliedAt   *time.Time `json:"applied_at,omitempty"`
+	CreatedBy   uint64     `gorm:"index;not null;default:0" json:"created_by"` // 0 once the user was erased
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// PriceHistoryRepository is scoped to a tenant except for ApplyDue, which
+// runs for all of them
+type PriceHistoryRepository interface {
+	List(tenantID, productID uint64, page, limit int32) ([]PriceChange, int64, error)
+	Schedule(change *ScheduledPriceChange) error
+	ListScheduled(tenantID, productID uint64) ([]ScheduledPriceChange, error)
+	CancelScheduled(tenantID, productID, id uint64) (*ScheduledPriceChange, error)
+	// ApplyDue applies at most limit pending changes due at now, each in its
+	// own transaction, and returns how many it handled
+	ApplyDue(now time.Time, limit int) (int, error)
+	// Backfill records the current price of products without a history
+	Backfill() error
+}
+
+type PriceHistoryUseCase interface {
+	GetHistory(tenantID, productID uint64, page, limit int32) ([]PriceChange, int64, error)
+	// Schedule reads a price without currency in the product currency
+	Schedule(tenantID, productID, createdBy uint64, price Money, effectiveAt time.Time) (*ScheduledPriceChange, error)
+	ListScheduled(tenantID, productID uint64) ([]ScheduledPriceChange, error)
+	Cancel(tenantID, productID, id uint64) (*ScheduledPriceChange, error)
+	// ApplyDue applies the changes that are due and returns how many it
+	// handled, failed ones included. The scheduler calls it periodically
+	ApplyDue() (int, error)
+}
@@ -1,34 +1,94 @@
 package domain
 
 import (
+	"encoding/json"
+	"errors"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+var (
+	ErrProductNotFound = errors.New("product not found")
+	ErrNoTenant        = errors.New("no active organization")
+)
+
+// Product belongs to one tenant, the auth-service organization that was
+// active when it was created
 type Product struct {
-	ID          uint64         `gorm:"primaryKey" json:"id"`
-	Name        string         `gorm:"size:100;not null" json:"name"`
-	Description string         `gorm:"type:text" json:"description"`
-	Price       float64        `gorm:"not null" json:"price"`
-	Stock       int32          `gorm:"not null" json:"stock"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID          uint64           `gorm:"primaryKey" json:"id"`
+	TenantID    uint64           `gorm:"index;not null;default:0" json:"tenant_id"`
+	CreatedBy   uint64           `gorm:"index;not null;default:0" json:"created_by"` // 0 once the creator was erased
+	CategoryID  *uint64          `gorm:"index" json:"category_id"`
+	Name        string           `gorm:"size:100;not null" json:"name"`
+	Description string           `gorm:"type:text" json:"description"`
+	Price       Money            `gorm:"embedded;embeddedPrefix:price_" json:"unit_price"`
+	Stock       int32            `gorm:"not null" json:"stock"` // unused once the product has variants
+	Variants    []ProductVariant `gorm:"foreignKey:ProductID" json:"variants,omitempty"`
+	Prices      []ProductPrice   `gorm:"foreignKey:ProductID" json:"prices,omitempty"` // in other currencies
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt   `gorm:"index" json:"-"`
+
+	// set when a currency was requested, see CurrencyConverter
+	RequestedPrice *Money `gorm:"-" json:"requested_price,omitempty"`
+	PriceConverted bool   `gorm:"-" json:"price_converted,omitempty"`
+
+	// the floating point price column of the previous release, written
+	// together with Price so it can still be rolled back to
+	DeprecatedPrice float64 `gorm:"column:price;not null;default:0" json:"-"`
+}
+
+// MarshalJSON adds the deprecated floating point price for older clients
+func (p Product) MarshalJSON() ([]byte, error) {
+	type product Product
+	return json.Marshal(struct {
+		product
+		LegacyPrice float64 `json:"price"`
+	}{product(p), p.Price.Float()})
 }
 
+// ProductRepository scopes every call to a tenant, Create and Update use
+// product.TenantID and record price changes in the history. The creator
+// lookups are for personal data requests and span tenants
 type ProductRepository interface {
 	Create(product *Product) error
-	FindByID(id uint64) (*Product, error)
-	Update(product *Product) error
-	Delete(id uint64) error
-	List(page, limit int32, search string) ([]Product, int64, error)
+	// FindByID loads the variants too
+	FindByID(tenantID, id uint64) (*Product, error)
+	Update(product *Product, changedBy uint64) error
+	Delete(tenantID, id uint64) error
+	// List includes the subtree of categoryPath when it is not empty
+	List(tenantID uint64, page, limit int32, search, categoryPath string) ([]Product, int64, error)
+	// SetCategory assigns a category of the same tenant, nil removes it
+	SetCategory(tenantID, id uint64, categoryID *uint64) error
+	// SetPrice adds or replaces the explicit price in price.Currency
+	SetPrice(price *ProductPrice) error
+	DeletePrice(tenantID, id uint64, currency string) error
+	ListByCreator(userID uint64) ([]Product, error)
+	// ClearCreator removes the references to an erased user, also from the
+	// price history
+	ClearCreator(userID uint64) error
 }
 
 type ProductUseCase interface {
-	Create(name, description string, price float64, stock int32) (*Product, error)
-	GetByID(id uint64) (*Product, error)
-	Update(id uint64, name, description string, price float64, stock int32) (*Product, error)
-	Delete(id uint64) error
-	List(page, limit int32, search string) ([]Product, int64, error)
+	// categoryID 0 leaves the product uncategorized
+	Create(tenantID, createdBy, categoryID uint64, name, description string, price PriceInput, stock int32) (*Product, error)
+	// GetByID fills RequestedPrice when currency is not empty and fails
+	// without an exchange rate
+	GetByID(tenantID, id uint64, currency string) (*Product, error)
+	// an unset price is left as it is, a changed one is recorded with updatedBy
+	Update(tenantID, id, updatedBy uint64, name, description string, price PriceInput, stock int32) (*Product, error)
+	Delete(tenantID, id uint64) error
+	// List filters by categoryID and its subcategories when it is not 0. With
+	// a currency, products without an exchange rate have no RequestedPrice
+	List(tenantID uint64, page, limit int32, search string, categoryID uint64, currency string) ([]Product, int64, error)
+	// SetCategory with categoryID 0 removes the product from its category
+	SetCategory(tenantID, id, categoryID uint64) (*Product, error)
+	// SetPrice sets the explicit price in a currency other than the product's
+	SetPrice(tenantID, id uint64, price Money) (*Product, error)
+	DeletePrice(tenantID, id uint64, currency string) (*Product, error)
+	// ExportByCreator returns the products a user created in any tenant
+	ExportByCreator(userID uint64) ([]Product, error)
+	// ForgetUser anonymizes the products of an erased user
+	ForgetUser(userID uint64) error
 }
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrReservationNotFound = errors.New("stock reservation not found")
+	ErrReservationClosed   = errors.New("stock reservation was already released or has expired")
+	ErrReleaseTooLarge     = errors.New("quantity is more than the reservation holds")
+)
+
+// states of a stock reservation
+const (
+	ReservationStatusActive   = "active"
+	ReservationStatusReleased = "released"
+	ReservationStatusExpired  = "expired"
+)
+
+// StockReservation holds stock taken by ReserveStock. Releasing it gives the
+// stock back, what is still held at ExpiresAt is given back by the expiry job
+type StockReservation struct {
+	ID        uint64 `gorm:"primaryKey" json:"id"`
+	TenantID  uint64 `gorm:"not null" json:"-"`
+	ProductID uint64 `gorm:"index;not null" json:"product_id"`
+	// nil when the stock was taken from the product itself
+	VariantID *uint64 `json:"variant_id,omitempty"`
+	// what is still held, partial releases lower it
+	Quantity  int32     `gorm:"not null" json:"quantity"`
+	Status    string    `gorm:"size:20;index:idx_stock_reservations_expiry,priority:1;not null" json:"status"`
+	ExpiresAt time.Time `gorm:"index:idx_stock_reservations_expiry,priority:2;not null" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,17 @@
+package domain
+
+// user events published by auth-service
+const UserEventErased = "user_erased"
+
+// EventCursor remembers the last user event a consumer handled, so it
+// resumes from there after a restart
+type EventCursor struct {
+	Consumer    string `gorm:"primaryKey;size:64"`
+	LastEventID uint64 `gorm:"not null;default:0"`
+}
+
+type EventCursorRepository interface {
+	// Get returns 0 for a consumer that never stored a cursor
+	Get(consumer string) (uint64, error)
+	Save(consumer string, lastEventID uint64) error
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrVariantNotFound   = errors.New("variant not found")
+	ErrVariantInvalid    = errors.New("invalid variant")
+	ErrSKUTaken          = errors.New("sku already in use")
+	ErrVariantExists     = errors.New("product already has a variant with these attributes")
+	ErrVariantRequired   = errors.New("product has variants, a variant_id is required")
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrInvalidQuantity   = errors.New("quantity must be greater than 0")
+)
+
+// ValidationError reports an invalid field
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ProductVariant is a sellable version of a product, e.g. size=M color=red.
+// A product with variants keeps its stock on the variants
+type ProductVariant struct {
+	ID         uint64            `gorm:"primaryKey" json:"id"`
+	TenantID   uint64            `gorm:"uniqueIndex:idx_variants_tenant_sku;not null" json:"-"`
+	ProductID  uint64            `gorm:"uniqueIndex:idx_variants_product_attributes;not null" json:"product_id"`
+	SKU        string            `gorm:"column:sku;size:64;uniqueIndex:idx_variants_tenant_sku;not null" json:"sku"`
+	Attributes map[string]string `gorm:"serializer:json;type:jsonb;not null" json:"attributes"`
+	// AttributeKey is the attributes as sorted JSON, a product can't have
+	// two variants with the same attributes
+	AttributeKey string `gorm:"type:text;uniqueIndex:idx_variants_product_attributes;not null" json:"-"`
+	// in the product currency, nil uses the product price
+	PriceAmountMinor *int64    `json:"price_amount_minor"`
+	Stock            int32     `gorm:"not null" json:"stock"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+
+	RequestedPrice *Money `gorm:"-" json:"requested_price,omitempty"`
+	PriceConverted bool   `gorm:"-" json:"price_converted,omitempty"`
+
+	// see Product.DeprecatedPrice
+	DeprecatedPrice *float64 `gorm:"column:price" json:"-"`
+}
+
+// EffectivePrice is the variant's own price or else the product price
+func (v *ProductVariant) EffectivePrice(productPrice Money) Money {
+	if v.PriceAmountMinor != nil {
+		return Money{AmountMinor: *v.PriceAmountMinor, Currency: productPrice.Currency}
+	}
+	return productPrice
+}
+
+// VariantUpdate holds the fields to change, nil leaves a field as it is
+type VariantUpdate struct {
+	SKU        *string
+	Attributes map[string]string
+	Price      PriceInput // unset leaves the price as it is
+	ClearPrice bool       // use the product price again
+	Stock      *int32
+}
+
+// VariantRepository scopes every call to a tenant and the variant's product
+type VariantRepository interface {
+	Create(variant *ProductVariant) error
+	FindByID(tenantID, productID, id uint64) (*ProductVariant, error)
+	Update(variant *ProductVariant) error
+	Delete(tenantID, productID, id uint64) error
+	// ReserveStock takes reservation.Quantity from a variant, or from the
+	// product itself when VariantID is nil and it has no variants, stores the
+	// reservation and returns the stock left
+	ReserveStock(reservation *StockReservation) (int32, error)
+	// ReleaseStock gives quantity of an active reservation back, 0 gives back
+	// all it holds
+	ReleaseStock(tenantID, productID, reservationID uint64, quantity int32) (*StockReservation, int32, error)
+	// ExpireReservations gives back the stock of at most limit reservations
+	// that expired at now, each in its own transaction, and returns how many
+	// it handled
+	ExpireReservations(now time.Time, limit int) (int, error)
+}
+
+type VariantUseCase interface {
+	// an unset price uses the product price
+	Create(tenantID, productID uint64, sku string, attributes map[string]string, price PriceInput, stock int32) (*ProductVariant, error)
+	Update(tenantID, productID, id uint64, update VariantUpdate) (*ProductVariant, error)
+	Delete(tenantID, productID, id uint64) error
+	// ReserveStock holds the stock for ttl, 0 uses the default
+	ReserveStock(tenantID, productID, variantID uint64, quantity int32, ttl time.Duration) (*StockReservation, int32, error)
+	ReleaseStock(tenantID, productID, reservationID uint64, quantity int32) (*StockReservation, int32, error)
+	// ExpireReservations gives back the stock of expired reservations, the
+	// expiry job calls it periodically
+	ExpireReservations() (int, error)
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"errors"
+	"product-service/internal/domain"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// categories are scoped to a tenant like products. Writes that depend on a
+// category lock its row, shared for new children and product assignments,
+// exclusive for moves and deletes, so a path is never read while it changes
+type categoryRepository struct {
+	db *gorm.DB
+}
+
+func NewCategoryRepository(db *gorm.DB) domain.CategoryRepository {
+	return &categoryRepository{db: db}
+}
+
+func (r *categoryRepository) Create(category *domain.Category) error {
+	if category.TenantID == 0 {
+		return domain.ErrNoTenant
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		parentPath := "/"
+		if category.ParentID != nil {
+			parent, err := lockCategory(tx, category.TenantID, *category.ParentID, "SHARE")
+			if err != nil {
+				return err
+			}
+			parentPath = parent.Path
+		}
+
+		// the path contains the id, it is only known after the insert
+		if err := tx.Create(category).Error; err != nil {
+			return err
+		}
+		category.Path = parentPath + strconv.FormatUint(category.ID, 10) + "/"
+
+		return tx.Model(category).UpdateColumn("path", category.Path).Error
+	})
+}
+
+func (r *categoryRepository) FindByID(tenantID, id uint64) (*domain.Category, error) {
+	var category domain.Category
+	err := r.db.Where("tenant_id = ?", tenantID).First(&category, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrCategoryNotFound
+		}
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+func (r *categoryRepository) List(tenantID uint64) ([]domain.Category, error) {
+	var categories []domain.Category
+	err := r.db.Where("tenant_id = ?", tenantID).Order("path").Find(&categories).Error
+
+	return categories, err
+}
+
+func (r *categoryRepository) Rename(tenantID, id uint64, name string) (*domain.Category, error) {
+	result := r.db.Model(&domain.Category{}).
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		Update("name", name)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, domain.ErrCategoryNotFound
+	}
+
+	return r.FindByID(tenantID, id)
+}
+
+func (r *categoryRepository) Move(tenantID, id, parentID uint64) (*domain.Category, error) {
+	var moved *domain.Category
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		category, err := lockCategory(tx, tenantID, id, "UPDATE")
+		if err != nil {
+			return err
+		}
+
+		// new children of the subtree wait until the new paths are committed
+		if err := tx.Model(&domain.Category{}).
+			Where("tenant_id = ? AND path LIKE ?", tenantID, category.Path+"%").
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Pluck("id", &[]uint64{}).Error; err != nil {
+			return err
+		}
+
+		newPath := "/" + strconv.FormatUint(id, 10) + "/"
+		var newParent *uint64
+		if parentID != 0 {
+			parent, err := lockCategory(tx, tenantID, parentID, "SHARE")
+			if err != nil {
+				return err
+			}
+			if strings.HasPrefix(parent.Path, category.Path) {
+				return domain.ErrCategoryCycle
+			}
+			newPath = parent.Path + strconv.FormatUint(id, 10) + "/"
+			newParent = &parent.ID
+		}
+
+		if newPath != category.Path {
+			// rewrite the prefix of the whole subtree, the category included
+			err := tx.Model(&domain.Category{}).
+				Where("tenant_id = ? AND path LIKE ?", tenantID, category.Path+"%").
+				Update("path", gorm.Expr("CAST(? AS text) || substring(path from ?)", newPath, len(category.Path)+1)).Error
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(category).Update("parent_id", newParent).Error; err != nil {
+				return err
+			}
+		}
+
+		moved = category
+		return tx.First(moved, id).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return moved, nil
+}
+
+func (r *categoryRepository) Delete(tenantID, id uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockCategory(tx, tenantID, id, "UPDATE"); err != nil {
+			return err
+		}
+
+		var children int64
+		if err := tx.Model(&domain.Category{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+			return err
+		}
+		var products int64
+		if err := tx.Model(&domain.Product{}).Where("category_id = ?", id).Count(&products).Error; err != nil {
+			return err
+		}
+		if children > 0 || products > 0 {
+			return domain.ErrCategoryNotEmpty
+		}
+
+		// deleted products would otherwise point at a category that is gone
+		if err := tx.Unscoped().Model(&domain.Product{}).
+			Where("category_id = ?", id).
+			Update("category_id", nil).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&domain.Category{}, id).Error
+	})
+}
+
+// lockCategory reads a category of the tenant with a row lock, strength is
+// SHARE or UPDATE
+func lockCategory(tx *gorm.DB, tenantID, id uint64, strength string) (*domain.Category, error) {
+	var category domain.Category
+	err := tx.Clauses(clause.Locking{Strength: strength}).
+		Where("tenant_id = ?", tenantID).
+		First(&category, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrCategoryNotFound
+		}
+		return nil, err
+	}
+
+	return &category, nil
+}
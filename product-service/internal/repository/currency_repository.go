@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"errors"
+	"product-service/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type currencyRepository struct {
+	db *gorm.DB
+}
+
+func NewCurrencyRepository(db *gorm.DB) domain.CurrencyRepository {
+	return &currencyRepository{db: db}
+}
+
+// rateConflict replaces the rate of a pair that is already stored
+var rateConflict = clause.OnConflict{
+	Columns:   []clause.Column{{Name: "from_currency"}, {Name: "to_currency"}},
+	DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+}
+
+func (r *currencyRepository) SetRate(rate *domain.ExchangeRate) error {
+	return r.db.Clauses(rateConflict).Create(rate).Error
+}
+
+func (r *currencyRepository) ImportRates(rates []domain.ExchangeRate) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Clauses(rateConflict).Create(&rates).Error
+	})
+}
+
+func (r *currencyRepository) DeleteRate(from, to string) error {
+	result := r.db.Where("from_currency = ? AND to_currency = ?", from, to).Delete(&domain.ExchangeRate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrRateNotFound
+	}
+
+	return nil
+}
+
+func (r *currencyRepository) ListRates() ([]domain.ExchangeRate, error) {
+	var rates []domain.ExchangeRate
+	err := r.db.Order("from_currency, to_currency").Find(&rates).Error
+
+	return rates, err
+}
+
+func (r *currencyRepository) RatesFor(currency string) ([]domain.ExchangeRate, error) {
+	var rates []domain.ExchangeRate
+	err := r.db.Where("from_currency = ? OR to_currency = ?", currency, currency).Find(&rates).Error
+
+	return rates, err
+}
+
+func (r *currencyRepository) SetRoundingRule(rule *domain.RoundingRule) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "currency"}},
+		DoUpdates: clause.AssignmentColumns([]string{"increment", "mode"}),
+	}).Create(rule).Error
+}
+
+func (r *currencyRepository) RoundingRule(currency string) (domain.RoundingRule, error) {
+	var rule domain.RoundingRule
+	err := r.db.Where("currency = ?", currency).Take(&rule).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.DefaultRoundingRule(currency), nil
+	}
+
+	return rule, err
+}
+
+func (r *currencyRepository) ListRoundingRules() ([]domain.RoundingRule, error) {
+	var rules []domain.RoundingRule
+	err := r.db.Order("currency").Find(&rules).Error
+
+	return rules, err
+}
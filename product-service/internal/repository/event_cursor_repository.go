@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"errors"
+	"product-service/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type eventCursorRepository struct {
+	db *gorm.DB
+}
+
+func NewEventCursorRepository(db *gorm.DB) domain.EventCursorRepository {
+	return &eventCursorRepository{db: db}
+}
+
+func (r *eventCursorRepository) Get(consumer string) (uint64, error) {
+	var cursor domain.EventCursor
+	err := r.db.Where("consumer = ?", consumer).Take(&cursor).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+
+	return cursor.LastEventID, err
+}
+
+func (r *eventCursorRepository) Save(consumer string, lastEventID uint64) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "consumer"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_event_id"}),
+	}).Create(&domain.EventCursor{Consumer: consumer, LastEventID: lastEventID}).Error
+}
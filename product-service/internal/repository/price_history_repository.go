@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"errors"
+	"product-service/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type priceHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewPriceHistoryRepository(db *gorm.DB) domain.PriceHistoryRepository {
+	return &priceHistoryRepository{db: db}
+}
+
+func (r *priceHistoryRepository) List(tenantID, productID uint64, page, limit int32) ([]domain.PriceChange, int64, error) {
+	var changes []domain.PriceChange
+	var total int64
+
+	query := r.db.Model(&domain.PriceChange{}).Where("tenant_id = ? AND product_id = ?", tenantID, productID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err := query.Order("effective_from DESC, id DESC").Offset(int(offset)).Limit(int(limit)).Find(&changes).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return changes, total, nil
+}
+
+func (r *priceHistoryRepository) Schedule(change *domain.ScheduledPriceChange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockProduct(tx, change.TenantID, change.ProductID, "SHARE"); err != nil {
+			return err
+		}
+		return tx.Create(change).Error
+	})
+}
+
+func (r *priceHistoryRepository) ListScheduled(tenantID, productID uint64) ([]domain.ScheduledPriceChange, error) {
+	var changes []domain.ScheduledPriceChange
+	err := r.db.Where("tenant_id = ? AND product_id = ?", tenantID, productID).
+		Order("effective_at, id").
+		Find(&changes).Error
+
+	return changes, err
+}
+
+func (r *priceHistoryRepository) CancelScheduled(tenantID, productID, id uint64) (*domain.ScheduledPriceChange, error) {
+	var change domain.ScheduledPriceChange
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// waits for the scheduler when it is applying this change
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("tenant_id = ? AND product_id = ?", tenantID, productID).
+			First(&change, id).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrScheduledChangeNotFound
+			}
+			return err
+		}
+		if change.Status != domain.ScheduleStatusPending {
+			return domain.ErrScheduledChangeDone
+		}
+
+		change.Status = domain.ScheduleStatusCancelled
+		return tx.Model(&change).Select("status", "updated_at").Updates(&change).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &change, nil
+}
+
+func (r *priceHistoryRepository) ApplyDue(now time.Time, limit int) (int, error) {
+	handled := 0
+	for handled < limit {
+		found := false
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			// SKIP LOCKED lets several instances run the scheduler
+			var change domain.ScheduledPriceChange
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("status = ? AND effective_at <= ?", domain.ScheduleStatusPending, now).
+				Order("effective_at, id").
+				Take(&change).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			found = true
+			return applyScheduled(tx, &change, now)
+		})
+		if err != nil || !found {
+			return handled, err
+		}
+		handled++
+	}
+
+	return handled, nil
+}
+
+func (r *priceHistoryRepository) Backfill() error {
+	// the price was set at the latest when the product was last updated
+	return r.db.Exec(`INSERT INTO price_changes (tenant_id, product_id, price_amount_minor, price_currency, effective_from, changed_by, source)
+		SELECT p.tenant_id, p.id, p.price_amount_minor, p.price_currency, p.updated_at, 0, ?
+		FROM products p
+		WHERE p.deleted_at IS NULL AND NOT EXISTS (SELECT 1 FROM price_changes c WHERE c.product_id = p.id)`,
+		domain.PriceSourceBackfill).Error
+}
+
+// applyScheduled sets the product price of a locked pending change and marks
+// it, a change that can't be applied anymore is marked as failed
+func applyScheduled(tx *gorm.DB, change *domain.ScheduledPriceChange, now time.Time) error {
+	product, err := lockProduct(tx, change.TenantID, change.ProductID, "UPDATE")
+	if errors.Is(err, domain.ErrProductNotFound) {
+		product = nil
+	} else if err != nil {
+		return err
+	}
+
+	action := decideScheduled(change, product)
+	if action.status == "" {
+		return nil
+	}
+
+	if action.updatePrice {
+		err := tx.Model(product).Updates(map[string]interface{}{
+			"price_amount_minor": change.Price.AmountMinor,
+			"price":              change.Price.Float(),
+			"updated_at":         now,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		err = recordPriceChange(tx, &domain.PriceChange{
+			TenantID:          change.TenantID,
+			ProductID:         change.ProductID,
+			Price:             change.Price,
+			EffectiveFrom:     now,
+			ChangedBy:         change.CreatedBy,
+			Source:            domain.PriceSourceSchedule,
+			ScheduledChangeID: &change.ID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return finishScheduled(tx, change, action.status, action.reason, now)
+}
+
+// scheduledAction is what applyScheduled does with a change, an empty status
+// leaves it as it is
+type scheduledAction struct {
+	status      string
+	reason      string
+	updatePrice bool
+}
+
+// decideScheduled only acts on pending changes, so applying one twice does
+// nothing the second time. product is nil when it was deleted
+func decideScheduled(change *domain.ScheduledPriceChange, product *domain.Product) scheduledAction {
+	switch {
+	case change.Status != domain.ScheduleStatusPending:
+		return scheduledAction{}
+	case product == nil:
+		return scheduledAction{status: domain.ScheduleStatusFailed, reason: domain.ErrProductNotFound.Error()}
+	case product.Price.Currency != change.Price.Currency:
+		// the product currency changed after the change was scheduled
+		return scheduledAction{status: domain.ScheduleStatusFailed, reason: domain.ErrScheduleCurrency.Error()}
+	}
+
+	// an unchanged price doesn't add a history entry
+	return scheduledAction{status: domain.ScheduleStatusApplied, updatePrice: product.Price != change.Price}
+}
+
+func finishScheduled(tx *gorm.DB, change *domain.ScheduledPriceChange, status, reason string, now time.Time) error {
+	change.Status, change.Error = status, reason
+	if status == domain.ScheduleStatusApplied {
+		change.AppliedAt = &now
+	}
+	return tx.Model(change).Select("status", "error", "applied_at", "updated_at").Updates(change).Error
+}
+
+// recordPriceChange ends the current history entry of the product and adds
+// one for its new price, the product row has to be locked
+func recordPriceChange(tx *gorm.DB, change *domain.PriceChange) error {
+	err := tx.Model(&domain.PriceChange{}).
+		Where("product_id = ? AND effective_to IS NULL", change.ProductID).
+		Update("effective_to", change.EffectiveFrom).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(change).Error
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"product-service/internal/domain"
+	"testing"
+)
+
+func TestDecideScheduled(t *testing.T) {
+	usd := func(amount int64) domain.Money {
+		return domain.Money{AmountMinor: amount, Currency: "USD"}
+	}
+
+	tests := []struct {
+		name    string
+		status  string
+		price   domain.Money
+		product *domain.Product
+		want    scheduledAction
+	}{
+		{
+			name:    "pending",
+			status:  domain.ScheduleStatusPending,
+			price:   usd(1500),
+			product: &domain.Product{Price: usd(1250)},
+			want:    scheduledAction{status: domain.ScheduleStatusApplied, updatePrice: true},
+		},
+		{
+			name:    "pending with the current price",
+			status:  domain.ScheduleStatusPending,
+			price:   usd(1250),
+			product: &domain.Product{Price: usd(1250)},
+			want:    scheduledAction{status: domain.ScheduleStatusApplied},
+		},
+		{
+			name:   "product deleted",
+			status: domain.ScheduleStatusPending,
+			price:  usd(1500),
+			want:   scheduledAction{status: domain.ScheduleStatusFailed, reason: domain.ErrProductNotFound.Error()},
+		},
+		{
+			name:    "product currency changed",
+			status:  domain.ScheduleStatusPending,
+			price:   usd(1500),
+			product: &domain.Product{Price: domain.Money{AmountMinor: 1250, Currency: "EUR"}},
+			want:    scheduledAction{status: domain.ScheduleStatusFailed, reason: domain.ErrScheduleCurrency.Error()},
+		},
+		{
+			name:    "already applied",
+			status:  domain.ScheduleStatusApplied,
+			price:   usd(1500),
+			product: &domain.Product{Price: usd(1250)},
+		},
+		{
+			name:    "cancelled",
+			status:  domain.ScheduleStatusCancelled,
+			price:   usd(1500),
+			product: &domain.Product{Price: usd(1250)},
+		},
+		{
+			name:   "already failed",
+			status: domain.ScheduleStatusFailed,
+			price:  usd(1500),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change := &domain.ScheduledPriceChange{Status: tt.status, Price: tt.price}
+			if got := decideScheduled(change, tt.product); got != tt.want {
+				t.Errorf("decideScheduled() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecideScheduledTwice(t *testing.T) {
+	change := &domain.ScheduledPriceChange{Status: domain.ScheduleStatusPending, Price: domain.Money{AmountMinor: 1500, Currency: "USD"}}
+	product := &domain.Product{Price: domain.Money{AmountMinor: 1250, Currency: "USD"}}
+
+	first := decideScheduled(change, product)
+	if first.status != domain.ScheduleStatusApplied || !first.updatePrice {
+		t.Fatalf("first decideScheduled() = %+v, want the price applied", first)
+	}
+	// what applyScheduled stores
+	change.Status = first.status
+	product.Price = change.Price
+
+	if second := decideScheduled(change, product); second != (scheduledAction{}) {
+		t.Errorf("second decideScheduled() = %+v, want nothing to do", second)
+	}
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"math"
+	"product-service/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// MigrateLegacyPrices converts the floating point price columns to minor
+// units of currency. AutoMigrate has to add the new columns first. The old
+// columns are kept and written for one more release, so the previous one can
+// still run against the database; rows it created are converted on the next
+// start. A later release drops them
+func MigrateLegacyPrices(db *gorm.DB, currency string) error {
+	exponent, ok := domain.CurrencyExponent(currency)
+	if !ok {
+		return domain.ErrUnknownCurrency
+	}
+	factor := int64(math.Pow10(exponent))
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		migrator := tx.Migrator()
+
+		// numeric rounds halves away from zero, 12.345 becomes 1235 cents
+		if migrator.HasColumn("products", "price") {
+			err := tx.Exec(`UPDATE products SET price_amount_minor = round(CAST(price AS numeric) * ?), price_currency = ? WHERE price_currency = ''`,
+				factor, currency).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		// variant prices were in the product currency, which is currency now
+		if migrator.HasColumn("product_variants", "price") {
+			err := tx.Exec(`UPDATE product_variants SET price_amount_minor = round(CAST(price AS numeric) * ?) WHERE price IS NOT NULL AND price_amount_minor IS NULL`,
+				factor).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// syncDeprecatedPrice writes the price to the legacy column of the product
+func syncDeprecatedPrice(product *domain.Product) {
+	product.DeprecatedPrice = product.Price.Float()
+}
+
+// syncDeprecatedVariantPrice writes the price to the legacy column of the
+// variant, currency is the product currency
+func syncDeprecatedVariantPrice(variant *domain.ProductVariant, currency string) {
+	variant.DeprecatedPrice = nil
+	if variant.PriceAmountMinor != nil {
+		price := domain.Money{AmountMinor: *variant.PriceAmountMinor, Currency: currency}.Float()
+		variant.DeprecatedPrice = &price
+	}
+}
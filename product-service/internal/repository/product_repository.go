@@ -1,11 +1,16 @@
 package repository
 
 import (
+	"errors"
 	"product-service/internal/domain"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// every query is scoped to a tenant, ids from another tenant behave as if
+// they don't exist. Only the creator lookups span tenants
 type productRepository struct {
 	db *gorm.DB
 }
@@ -15,36 +20,104 @@ func NewProductRepository(db *gorm.DB) domain.ProductRepository {
 }
 
 func (r *productRepository) Create(product *domain.Product) error {
-	return r.db.Create(product).Error
+	if product.TenantID == 0 {
+		return domain.ErrNoTenant
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if product.CategoryID != nil {
+			if _, err := lockCategory(tx, product.TenantID, *product.CategoryID, "SHARE"); err != nil {
+				return err
+			}
+		}
+		syncDeprecatedPrice(product)
+		if err := tx.Create(product).Error; err != nil {
+			return err
+		}
+
+		return recordPriceChange(tx, &domain.PriceChange{
+			TenantID:      product.TenantID,
+			ProductID:     product.ID,
+			Price:         product.Price,
+			EffectiveFrom: product.CreatedAt,
+			ChangedBy:     product.CreatedBy,
+			Source:        domain.PriceSourceCreate,
+		})
+	})
 }
 
-func (r *productRepository) FindByID(id uint64) (*domain.Product, error) {
+func (r *productRepository) FindByID(tenantID, id uint64) (*domain.Product, error) {
 	var product domain.Product
-	err := r.db.First(&product, id).Error
+	err := r.db.Where("tenant_id = ?", tenantID).
+		Preload("Variants", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Prices").
+		First(&product, id).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrProductNotFound
+		}
 		return nil, err
 	}
 	return &product, nil
 }
 
-func (r *productRepository) Update(product *domain.Product) error {
-	return r.db.Save(product).Error
+func (r *productRepository) Update(product *domain.Product, changedBy uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// the lock keeps concurrent price changes in order
+		current, err := lockProduct(tx, product.TenantID, product.ID, "UPDATE")
+		if err != nil {
+			return err
+		}
+
+		// Save would insert the row when the tenant condition matches nothing
+		syncDeprecatedPrice(product)
+		err = tx.Model(&domain.Product{}).
+			Where("id = ? AND tenant_id = ?", product.ID, product.TenantID).
+			Select("name", "description", "price_amount_minor", "price_currency", "price", "stock", "updated_at").
+			Updates(product).Error
+		if err != nil || current.Price == product.Price {
+			return err
+		}
+
+		return recordPriceChange(tx, &domain.PriceChange{
+			TenantID:      product.TenantID,
+			ProductID:     product.ID,
+			Price:         product.Price,
+			EffectiveFrom: time.Now(),
+			ChangedBy:     changedBy,
+			Source:        domain.PriceSourceUpdate,
+		})
+	})
 }
 
-func (r *productRepository) Delete(id uint64) error {
-	return r.db.Delete(&domain.Product{}, id).Error
+func (r *productRepository) Delete(tenantID, id uint64) error {
+	result := r.db.Where("tenant_id = ?", tenantID).Delete(&domain.Product{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrProductNotFound
+	}
+
+	return nil
 }
 
-func (r *productRepository) List(page, limit int32, search string) ([]domain.Product, int64, error) {
+func (r *productRepository) List(tenantID uint64, page, limit int32, search, categoryPath string) ([]domain.Product, int64, error) {
 	var products []domain.Product
 	var total int64
 
-	query := r.db.Model(&domain.Product{})
+	query := r.db.Model(&domain.Product{}).Where("tenant_id = ?", tenantID)
 
 	if search != "" {
 		query = query.Where("name ILIKE ? OR description ILIKE ?", "%"+search+"%", "%"+search+"%")
 	}
 
+	// paths only contain ids and slashes, nothing to escape
+	if categoryPath != "" {
+		query = query.Where("category_id IN (?)",
+			r.db.Model(&domain.Category{}).Select("id").Where("tenant_id = ? AND path LIKE ?", tenantID, categoryPath+"%"))
+	}
+
 	err := query.Count(&total).Error
 	if err != nil {
 		return nil, 0, err
@@ -53,10 +126,80 @@ func (r *productRepository) List(page, limit int32, search string) ([]domain.Pro
 	// calculate offset
 	offset := (page - 1) * limit
 
-	err = query.Offset(int(offset)).Limit(int(limit)).Find(&products).Error
+	err = query.Preload("Prices").Offset(int(offset)).Limit(int(limit)).Find(&products).Error
 	if err != nil {
 		return nil, 0, err
 	}
 
 	return products, total, nil
 }
+
+func (r *productRepository) SetCategory(tenantID, id uint64, categoryID *uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if categoryID != nil {
+			if _, err := lockCategory(tx, tenantID, *categoryID, "SHARE"); err != nil {
+				return err
+			}
+		}
+
+		result := tx.Model(&domain.Product{}).
+			Where("id = ? AND tenant_id = ?", id, tenantID).
+			Update("category_id", categoryID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrProductNotFound
+		}
+
+		return nil
+	})
+}
+
+func (r *productRepository) SetPrice(price *domain.ProductPrice) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockProduct(tx, price.TenantID, price.ProductID, "SHARE"); err != nil {
+			return err
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "product_id"}, {Name: "currency"}},
+			DoUpdates: clause.AssignmentColumns([]string{"amount_minor", "updated_at"}),
+		}).Create(price).Error
+	})
+}
+
+func (r *productRepository) DeletePrice(tenantID, id uint64, currency string) error {
+	return r.db.Where("tenant_id = ? AND product_id = ? AND currency = ?", tenantID, id, currency).
+		Delete(&domain.ProductPrice{}).Error
+}
+
+func (r *productRepository) ListByCreator(userID uint64) ([]domain.Product, error) {
+	var products []domain.Product
+	err := r.db.Where("created_by = ?", userID).Order("id").Find(&products).Error
+
+	return products, err
+}
+
+func (r *productRepository) ClearCreator(userID uint64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// deleted products keep the reference too
+		err := tx.Unscoped().Model(&domain.Product{}).
+			Where("created_by = ?", userID).
+			Update("created_by", 0).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&domain.PriceChange{}).
+			Where("changed_by = ?", userID).
+			Update("changed_by", 0).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&domain.ScheduledPriceChange{}).
+			Where("created_by = ?", userID).
+			Update("created_by", 0).Error
+	})
+}
@@ -0,0 +1,288 @@
+package repository
+
+import (
+	"errors"
+	"product-service/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// variants are reached through their product, a variant id under another
+// product or tenant behaves as if it doesn't exist
+type variantRepository struct {
+	db *gorm.DB
+}
+
+func NewVariantRepository(db *gorm.DB) domain.VariantRepository {
+	return &variantRepository{db: db}
+}
+
+func (r *variantRepository) Create(variant *domain.ProductVariant) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// a deleted product can't get new variants
+		product, err := lockProduct(tx, variant.TenantID, variant.ProductID, "SHARE")
+		if err != nil {
+			return err
+		}
+		if err := checkVariantUnique(tx, variant); err != nil {
+			return err
+		}
+
+		syncDeprecatedVariantPrice(variant, product.Price.Currency)
+		return tx.Create(variant).Error
+	})
+}
+
+func (r *variantRepository) FindByID(tenantID, productID, id uint64) (*domain.ProductVariant, error) {
+	var variant domain.ProductVariant
+	err := r.db.Where("tenant_id = ? AND product_id = ?", tenantID, productID).First(&variant, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrVariantNotFound
+		}
+		return nil, err
+	}
+
+	return &variant, nil
+}
+
+func (r *variantRepository) Update(variant *domain.ProductVariant) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// the legacy price column needs the product currency
+		product, err := lockProduct(tx, variant.TenantID, variant.ProductID, "SHARE")
+		if err != nil {
+			if errors.Is(err, domain.ErrProductNotFound) {
+				return domain.ErrVariantNotFound
+			}
+			return err
+		}
+		if err := checkVariantUnique(tx, variant); err != nil {
+			return err
+		}
+
+		syncDeprecatedVariantPrice(variant, product.Price.Currency)
+		result := tx.Model(&domain.ProductVariant{}).
+			Where("id = ? AND tenant_id = ? AND product_id = ?", variant.ID, variant.TenantID, variant.ProductID).
+			Select("sku", "attributes", "attribute_key", "price_amount_minor", "price", "stock", "updated_at").
+			Updates(variant)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrVariantNotFound
+		}
+
+		return nil
+	})
+}
+
+func (r *variantRepository) Delete(tenantID, productID, id uint64) error {
+	result := r.db.Where("tenant_id = ? AND product_id = ?", tenantID, productID).Delete(&domain.ProductVariant{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrVariantNotFound
+	}
+
+	return nil
+}
+
+func (r *variantRepository) ReserveStock(reservation *domain.StockReservation) (int32, error) {
+	var remaining int32
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		remaining, err = adjustStock(tx, reservation.TenantID, reservation.ProductID, reservation.VariantID, -reservation.Quantity)
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(reservation).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return remaining, nil
+}
+
+func (r *variantRepository) ReleaseStock(tenantID, productID, reservationID uint64, quantity int32) (*domain.StockReservation, int32, error) {
+	var reservation domain.StockReservation
+	var remaining int32
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// waits for the expiry job when it is handling this reservation
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("tenant_id = ? AND product_id = ?", tenantID, productID).
+			First(&reservation, reservationID).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrReservationNotFound
+			}
+			return err
+		}
+		if reservation.Status != domain.ReservationStatusActive {
+			return domain.ErrReservationClosed
+		}
+		if quantity == 0 {
+			quantity = reservation.Quantity
+		}
+		if quantity > reservation.Quantity {
+			return domain.ErrReleaseTooLarge
+		}
+
+		remaining, err = returnReservedStock(tx, &reservation, quantity, domain.ReservationStatusReleased)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return &reservation, remaining, nil
+}
+
+func (r *variantRepository) ExpireReservations(now time.Time, limit int) (int, error) {
+	handled := 0
+	for handled < limit {
+		found := false
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			// SKIP LOCKED lets several instances run the expiry job
+			var reservation domain.StockReservation
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("status = ? AND expires_at <= ?", domain.ReservationStatusActive, now).
+				Order("expires_at, id").
+				Take(&reservation).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			found = true
+			_, err = returnReservedStock(tx, &reservation, reservation.Quantity, domain.ReservationStatusExpired)
+			return err
+		})
+		if err != nil || !found {
+			return handled, err
+		}
+		handled++
+	}
+
+	return handled, nil
+}
+
+// returnReservedStock gives quantity of a locked reservation back and closes
+// it with status once it holds nothing anymore. Stock of a deleted product or
+// variant is dropped
+func returnReservedStock(tx *gorm.DB, reservation *domain.StockReservation, quantity int32, status string) (int32, error) {
+	remaining, err := adjustStock(tx, reservation.TenantID, reservation.ProductID, reservation.VariantID, quantity)
+	if err != nil && !errors.Is(err, domain.ErrProductNotFound) && !errors.Is(err, domain.ErrVariantNotFound) {
+		return 0, err
+	}
+
+	reservation.Quantity -= quantity
+	if reservation.Quantity == 0 {
+		reservation.Status = status
+	}
+	err = tx.Model(reservation).Select("quantity", "status", "updated_at").Updates(reservation).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return remaining, nil
+}
+
+// adjustStock changes the stock of a locked row so concurrent reservations
+// can't take more than there is. Stock can only be taken from the product
+// itself while it has no variants
+func adjustStock(tx *gorm.DB, tenantID, productID uint64, variantID *uint64, delta int32) (int32, error) {
+	if variantID == nil {
+		product, err := lockProduct(tx, tenantID, productID, "UPDATE")
+		if err != nil {
+			return 0, err
+		}
+
+		if delta < 0 {
+			var variants int64
+			if err := tx.Model(&domain.ProductVariant{}).Where("product_id = ?", productID).Count(&variants).Error; err != nil {
+				return 0, err
+			}
+			if variants > 0 {
+				return 0, domain.ErrVariantRequired
+			}
+		}
+
+		remaining := product.Stock + delta
+		if remaining < 0 {
+			return 0, domain.ErrInsufficientStock
+		}
+		return remaining, tx.Model(product).Update("stock", remaining).Error
+	}
+
+	if _, err := lockProduct(tx, tenantID, productID, "SHARE"); err != nil {
+		return 0, err
+	}
+
+	var variant domain.ProductVariant
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("tenant_id = ? AND product_id = ?", tenantID, productID).
+		First(&variant, *variantID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, domain.ErrVariantNotFound
+		}
+		return 0, err
+	}
+
+	remaining := variant.Stock + delta
+	if remaining < 0 {
+		return 0, domain.ErrInsufficientStock
+	}
+	return remaining, tx.Model(&variant).Update("stock", remaining).Error
+}
+
+// checkVariantUnique reports a taken sku or attribute set before the unique
+// indexes would
+func checkVariantUnique(tx *gorm.DB, variant *domain.ProductVariant) error {
+	var count int64
+	err := tx.Model(&domain.ProductVariant{}).
+		Where("tenant_id = ? AND sku = ? AND id <> ?", variant.TenantID, variant.SKU, variant.ID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return domain.ErrSKUTaken
+	}
+
+	err = tx.Model(&domain.ProductVariant{}).
+		Where("product_id = ? AND attribute_key = ? AND id <> ?", variant.ProductID, variant.AttributeKey, variant.ID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return domain.ErrVariantExists
+	}
+
+	return nil
+}
+
+// lockProduct reads a product of the tenant with a row lock, strength is
+// SHARE or UPDATE
+func lockProduct(tx *gorm.DB, tenantID, id uint64, strength string) (*domain.Product, error) {
+	var product domain.Product
+	err := tx.Clauses(clause.Locking{Strength: strength}).
+		Where("tenant_id = ?", tenantID).
+		First(&product, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrProductNotFound
+		}
+		return nil, err
+	}
+
+	return &product, nil
+}
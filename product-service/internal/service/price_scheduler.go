@@ -0,0 +1,36 @@
+package service
+
+import (
+	"log"
+	"product-service/internal/domain"
+	"time"
+)
+
+// PriceScheduler applies scheduled price changes. Pending changes survive a
+// restart and each one is applied once, several instances can run it
+type PriceScheduler struct {
+	priceHistoryUseCase domain.PriceHistoryUseCase
+	interval            time.Duration
+}
+
+func NewPriceScheduler(priceHistoryUseCase domain.PriceHistoryUseCase, interval time.Duration) *PriceScheduler {
+	return &PriceScheduler{priceHistoryUseCase: priceHistoryUseCase, interval: interval}
+}
+
+// Run checks for due changes every interval, it never returns
+func (s *PriceScheduler) Run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		handled, err := s.priceHistoryUseCase.ApplyDue()
+		if err != nil {
+			log.Printf("Warning: failed to apply scheduled price changes: %v", err)
+		}
+		if handled > 0 {
+			log.Printf("Processed %d scheduled price changes", handled)
+		}
+
+		<-ticker.C
+	}
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"log"
+	"product-service/internal/domain"
+	"time"
+)
+
+// ReservationExpirer gives back the stock of reservations that were not
+// released before they expired, several instances can run it
+type ReservationExpirer struct {
+	variantUseCase domain.VariantUseCase
+	interval       time.Duration
+}
+
+func NewReservationExpirer(variantUseCase domain.VariantUseCase, interval time.Duration) *ReservationExpirer {
+	return &ReservationExpirer{variantUseCase: variantUseCase, interval: interval}
+}
+
+// Run checks for expired reservations every interval, it never returns
+func (e *ReservationExpirer) Run() {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		handled, err := e.variantUseCase.ExpireReservations()
+		if err != nil {
+			log.Printf("Warning: failed to expire stock reservations: %v", err)
+		}
+		if handled > 0 {
+			log.Printf("Processed %d expired stock reservations", handled)
+		}
+
+		<-ticker.C
+	}
+}
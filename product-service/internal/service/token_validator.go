@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"grpc/serviceauth"
+	"grpc/tokencache"
+	"log"
+	"product-service/internal/domain"
+	"strings"
+	"time"
+
+	authpb "grpc/pb/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// same prefix as auth-service, API keys can only be checked remotely
+	apiKeyPrefix    = "ak_"
+	validateTimeout = 3 * time.Second
+	// token_use of auth-service access tokens
+	accessTokenUse       = "access"
+	tokenCacheMaxEntries = 10000
+)
+
+// FallbackMode decides what happens to a token with a valid signature when
+// auth-service can't be reached to validate it
+type FallbackMode string
+
+const (
+	// FallbackClosed rejects the token
+	FallbackClosed FallbackMode = "closed"
+	// FallbackLocal accepts it with the claims of the token, revocations and
+	// disabled accounts are missed until auth-service is back
+	FallbackLocal FallbackMode = "local"
+)
+
+// validation metrics, exposed on /debug/vars
+var (
+	tokenLocalRejects      = expvar.NewInt("product_auth_local_rejects_total")
+	tokenLocalFallbacks    = expvar.NewInt("product_auth_local_fallbacks_total")
+	tokenRemoteValidations = expvar.NewInt("product_auth_remote_validations_total")
+	tokenCacheMetrics      = tokencache.Metrics{
+		Hits:        expvar.NewInt("product_auth_cache_hits_total"),
+		Misses:      expvar.NewInt("product_auth_cache_misses_total"),
+		Revocations: expvar.NewInt("product_auth_cache_revocations_total"),
+		Watching:    expvar.NewInt("product_auth_cache_watching"),
+	}
+)
+
+// userClaims mirrors the user token claims of auth-service
+type userClaims struct {
+	UserID         uint64 `json:"user_id"`
+	Role           string `json:"role,omitempty"`
+	ClientID       string `json:"client_id,omitempty"`
+	OrganizationID uint64 `json:"org_id,omitempty"`
+	TokenUse       string `json:"token_use,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// tokenValidator checks JWT signatures locally with the public keys of
+// auth-service so forged and expired tokens never reach it. Revocation,
+// disabled accounts and API keys are only known to auth-service, so
+// everything else goes through Validate and the result is cached for
+// cacheTTL while the revocation stream from auth-service is connected
+type tokenValidator struct {
+	issuer     string
+	keys       serviceauth.KeySource
+	authClient authpb.AuthServiceClient
+	cache      *tokencache.Cache[*domain.AuthUser]
+	fallback   FallbackMode
+}
+
+// NewTokenValidator verifies access tokens issued by issuer with keys, the
+// JWKS of auth-service
+func NewTokenValidator(issuer string, keys serviceauth.KeySource, authClient authpb.AuthServiceClient, cacheTTL time.Duration, fallback FallbackMode) domain.TokenValidator {
+	cache := tokencache.New(cacheTTL, tokenCacheMaxEntries, func(user *domain.AuthUser) uint64 {
+		return user.ID
+	}, tokenCacheMetrics)
+	go cache.Watch(authClient)
+
+	return &tokenValidator{
+		issuer:     issuer,
+		keys:       keys,
+		authClient: authClient,
+		cache:      cache,
+		fallback:   fallback,
+	}
+}
+
+func (v *tokenValidator) Validate(ctx context.Context, token string) (*domain.AuthUser, error) {
+	if token == "" {
+		return nil, domain.ErrMissingToken
+	}
+
+	user, generation, ok := v.cache.Get(token)
+	if ok {
+		return user, nil
+	}
+
+	var local *userClaims
+	if !strings.HasPrefix(token, apiKeyPrefix) && !isLegacyToken(token) {
+		claims, err := v.parseLocal(ctx, token)
+		if err != nil {
+			tokenLocalRejects.Add(1)
+			return nil, domain.ErrInvalidToken
+		}
+		local = claims
+	}
+
+	user, err := v.validateRemote(ctx, token)
+	if err != nil {
+		// FallbackLocal keeps serving users with a valid signature while
+		// auth-service is down
+		if v.fallback == FallbackLocal && local != nil && isUnavailable(err) {
+			log.Printf("Warning: auth-service unavailable, accepting locally validated token: %v", err)
+			tokenLocalFallbacks.Add(1)
+			return &domain.AuthUser{ID: local.UserID, Role: local.Role, AuthType: "jwt", OrganizationID: local.OrganizationID}, nil
+		}
+		return nil, err
+	}
+
+	// never cached past the token expiry
+	var notAfter time.Time
+	if local != nil && local.ExpiresAt != nil {
+		notAfter = local.ExpiresAt.Time
+	}
+	v.cache.Set(token, user, generation, notAfter)
+
+	return user, nil
+}
+
+func (v *tokenValidator) parseLocal(ctx context.Context, token string) (*userClaims, error) {
+	claims := &userClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	}, jwt.WithValidMethods([]string{"RS256"}), jwt.WithIssuer(v.issuer), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+
+	// service and ID tokens are signed with the same key
+	if claims.TokenUse != accessTokenUse {
+		return nil, errors.New("not an access token")
+	}
+
+	// tokens issued to third-party OAuth clients are not accepted here
+	if claims.ClientID != "" {
+		return nil, errors.New("token was issued to an OAuth client")
+	}
+
+	return claims, nil
+}
+
+func (v *tokenValidator) validateRemote(ctx context.Context, token string) (*domain.AuthUser, error) {
+	tokenRemoteValidations.Add(1)
+
+	ctx, cancel := context.WithTimeout(ctx, validateTimeout)
+	defer cancel()
+
+	resp, err := v.authClient.Validate(ctx, &authpb.ValidateRequest{Token: token})
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Valid || resp.User == nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	return &domain.AuthUser{
+		ID:       resp.User.Id,
+		Role:     resp.User.Role,
+		AuthType: resp.AuthType,
+		Scopes:   resp.Scopes,
+		// only set while the user is still a member
+		OrganizationID:   resp.OrganizationId,
+		OrganizationRole: resp.OrganizationRole,
+	}, nil
+}
+
+// isLegacyToken reports HS256 tokens issued before auth-service signed them
+// with its OIDC key, only auth-service can still check them
+func isLegacyToken(token string) bool {
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &userClaims{})
+	return err == nil && parsed.Method == jwt.SigningMethodHS256
+}
+
+func isUnavailable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
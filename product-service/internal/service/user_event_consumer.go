@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"log"
+	"product-service/internal/domain"
+	"time"
+
+	authpb "grpc/pb/auth"
+)
+
+const (
+	userEventConsumer = "product-service"
+	userEventRetryMin = time.Second
+	userEventRetryMax = 30 * time.Second
+)
+
+// UserEventConsumer follows the user events of auth-service and removes
+// references to erased users. Events are handled at least once
+type UserEventConsumer struct {
+	client         authpb.AuthServiceClient
+	cursors        domain.EventCursorRepository
+	productUseCase domain.ProductUseCase
+}
+
+func NewUserEventConsumer(client authpb.AuthServiceClient, cursors domain.EventCursorRepository, productUseCase domain.ProductUseCase) *UserEventConsumer {
+	return &UserEventConsumer{client: client, cursors: cursors, productUseCase: productUseCase}
+}
+
+// Run reconnects with backoff, it never returns
+func (c *UserEventConsumer) Run() {
+	retry := userEventRetryMin
+	for {
+		connected, err := c.consumeOnce()
+		log.Printf("Warning: user event stream closed: %v", err)
+
+		// backoff only grows while auth-service can't be reached
+		if connected {
+			retry = userEventRetryMin
+		}
+		time.Sleep(retry)
+		retry = min(retry*2, userEventRetryMax)
+	}
+}
+
+func (c *UserEventConsumer) consumeOnce() (bool, error) {
+	cursor, err := c.cursors.Get(userEventConsumer)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := c.client.WatchUserEvents(ctx, &authpb.WatchUserEventsRequest{AfterId: cursor})
+	if err != nil {
+		return false, err
+	}
+	if _, err := stream.Header(); err != nil {
+		return false, err
+	}
+	log.Printf("User event stream connected after event %d", cursor)
+
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			return true, err
+		}
+		if err := c.handle(event); err != nil {
+			// the event is sent again after reconnecting
+			return true, err
+		}
+		if err := c.cursors.Save(userEventConsumer, event.Id); err != nil {
+			return true, err
+		}
+	}
+}
+
+func (c *UserEventConsumer) handle(event *authpb.UserEvent) error {
+	switch event.Type {
+	case domain.UserEventErased:
+		return c.productUseCase.ForgetUser(event.UserId)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"product-service/internal/domain"
+	"strings"
+	"unicode/utf8"
+)
+
+type categoryUseCase struct {
+	categoryRepo domain.CategoryRepository
+}
+
+func NewCategoryUseCase(categoryRepo domain.CategoryRepository) domain.CategoryUseCase {
+	return &categoryUseCase{categoryRepo: categoryRepo}
+}
+
+func (u *categoryUseCase) Create(tenantID uint64, name string, parentID uint64) (*domain.Category, error) {
+	name, err := categoryName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	category := &domain.Category{
+		TenantID: tenantID,
+		ParentID: optionalID(parentID),
+		Name:     name,
+	}
+	if err := u.categoryRepo.Create(category); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (u *categoryUseCase) GetByID(tenantID, id uint64) (*domain.Category, error) {
+	return u.categoryRepo.FindByID(tenantID, id)
+}
+
+func (u *categoryUseCase) List(tenantID uint64) ([]domain.Category, error) {
+	return u.categoryRepo.List(tenantID)
+}
+
+func (u *categoryUseCase) Rename(tenantID, id uint64, name string) (*domain.Category, error) {
+	name, err := categoryName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.categoryRepo.Rename(tenantID, id, name)
+}
+
+func (u *categoryUseCase) Move(tenantID, id, parentID uint64) (*domain.Category, error) {
+	if id == parentID {
+		return nil, domain.ErrCategoryCycle
+	}
+
+	return u.categoryRepo.Move(tenantID, id, parentID)
+}
+
+func (u *categoryUseCase) Delete(tenantID, id uint64) error {
+	return u.categoryRepo.Delete(tenantID, id)
+}
+
+func categoryName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > 100 {
+		return "", domain.ErrCategoryNameInvalid
+	}
+
+	return name, nil
+}
@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"product-service/internal/domain"
+	"regexp"
+	"strings"
+)
+
+const maxImportedRates = 1000
+
+// numeric(30,12)
+var ratePattern = regexp.MustCompile(`^[0-9]{1,18}(\.[0-9]{1,12})?$`)
+
+type currencyUseCase struct {
+	currencyRepo domain.CurrencyRepository
+}
+
+func NewCurrencyUseCase(currencyRepo domain.CurrencyRepository) domain.CurrencyUseCase {
+	return &currencyUseCase{currencyRepo: currencyRepo}
+}
+
+func (u *currencyUseCase) SetExchangeRate(from, to, rate string) (*domain.ExchangeRate, error) {
+	exchangeRate, err := parseExchangeRate(from, to, rate)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.currencyRepo.SetRate(exchangeRate); err != nil {
+		return nil, err
+	}
+
+	return exchangeRate, nil
+}
+
+func (u *currencyUseCase) DeleteExchangeRate(from, to string) error {
+	return u.currencyRepo.DeleteRate(strings.ToUpper(from), strings.ToUpper(to))
+}
+
+func (u *currencyUseCase) ListExchangeRates() ([]domain.ExchangeRate, error) {
+	return u.currencyRepo.ListRates()
+}
+
+// ImportExchangeRates reads CSV with a from,to,rate header or without one,
+// lines starting with # are skipped. Nothing is stored when a line is invalid
+func (u *currencyUseCase) ImportExchangeRates(data []byte) (int, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	var rates []domain.ExchangeRate
+	seen := make(map[string]bool)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			return 0, &domain.ValidationError{Field: fmt.Sprintf("line %d", line), Message: err.Error()}
+		}
+		if len(rates) == 0 && strings.EqualFold(record[0], "from") {
+			continue
+		}
+
+		rate, err := parseExchangeRate(record[0], record[1], record[2])
+		if err != nil {
+			return 0, &domain.ValidationError{Field: fmt.Sprintf("line %d", line), Message: err.Error()}
+		}
+		if seen[rate.From+rate.To] {
+			return 0, &domain.ValidationError{Field: fmt.Sprintf("line %d", line), Message: "duplicate currency pair"}
+		}
+		seen[rate.From+rate.To] = true
+		rates = append(rates, *rate)
+
+		if len(rates) > maxImportedRates {
+			return 0, &domain.ValidationError{Field: "file", Message: fmt.Sprintf("at most %d rates can be imported at once", maxImportedRates)}
+		}
+	}
+	if len(rates) == 0 {
+		return 0, &domain.ValidationError{Field: "file", Message: "no rates found"}
+	}
+
+	if err := u.currencyRepo.ImportRates(rates); err != nil {
+		return 0, err
+	}
+
+	return len(rates), nil
+}
+
+func (u *currencyUseCase) SetRoundingRule(currency string, increment int64, mode string) (*domain.RoundingRule, error) {
+	currency, err := normalizeCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+	if mode == "" {
+		mode = domain.RoundHalfUp
+	}
+	if increment < 1 || (mode != domain.RoundHalfUp && mode != domain.RoundUp && mode != domain.RoundDown) {
+		return nil, domain.ErrInvalidRoundingRule
+	}
+
+	rule := &domain.RoundingRule{Currency: currency, Increment: increment, Mode: mode}
+	if err := u.currencyRepo.SetRoundingRule(rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (u *currencyUseCase) ListRoundingRules() ([]domain.RoundingRule, error) {
+	return u.currencyRepo.ListRoundingRules()
+}
+
+func parseExchangeRate(from, to, rate string) (*domain.ExchangeRate, error) {
+	from, err := normalizeCurrency(from)
+	if err != nil {
+		return nil, err
+	}
+	to, err = normalizeCurrency(to)
+	if err != nil {
+		return nil, err
+	}
+	if from == to {
+		return nil, &domain.ValidationError{Field: "to_currency", Message: "must differ from from_currency"}
+	}
+
+	rate = strings.TrimSpace(rate)
+	value, ok := new(big.Rat).SetString(rate)
+	if !ratePattern.MatchString(rate) || !ok || value.Sign() <= 0 {
+		return nil, domain.ErrInvalidRate
+	}
+
+	return &domain.ExchangeRate{From: from, To: to, Rate: rate}, nil
+}
+
+func normalizeCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if _, ok := domain.CurrencyExponent(currency); !ok {
+		return "", domain.ErrUnknownCurrency
+	}
+
+	return currency, nil
+}
+
+// loadConverter reads the rates and the rounding rule of currency once, so a
+// page of products is converted with the same rates
+func loadConverter(currencyRepo domain.CurrencyRepository, currency string) (*domain.CurrencyConverter, error) {
+	rates, err := currencyRepo.RatesFor(currency)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := currencyRepo.RoundingRule(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewCurrencyConverter(currency, rates, rule), nil
+}
@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"expvar"
+	"product-service/internal/domain"
+	"strings"
+)
+
+// counts calls still sending the deprecated floating point price, the
+// field can be removed once this stays at 0
+var deprecatedPriceInputs = expvar.NewInt("product_deprecated_price_inputs_total")
+
+// resolvePrice turns a client price into Money, the deprecated floating
+// point amount and Money without a currency are read in currency
+func resolvePrice(input domain.PriceInput, currency string) (domain.Money, error) {
+	var price domain.Money
+	if input.Money != nil {
+		price = *input.Money
+		price.Currency = strings.ToUpper(strings.TrimSpace(price.Currency))
+		if price.Currency == "" {
+			price.Currency = currency
+		}
+		if _, ok := domain.CurrencyExponent(price.Currency); !ok {
+			return domain.Money{}, domain.ErrUnknownCurrency
+		}
+	} else {
+		deprecatedPriceInputs.Add(1)
+
+		var err error
+		if price, err = domain.MoneyFromFloat(input.Amount, currency); err != nil {
+			return domain.Money{}, err
+		}
+	}
+
+	if price.AmountMinor <= 0 {
+		return domain.Money{}, domain.ErrInvalidPrice
+	}
+
+	return price, nil
+}
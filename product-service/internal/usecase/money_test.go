@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"errors"
+	"product-service/internal/domain"
+	"testing"
+)
+
+func TestResolvePrice(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          domain.PriceInput
+		currency       string
+		want           domain.Money
+		wantErr        error
+		wantDeprecated bool
+	}{
+		{
+			name:     "money",
+			input:    domain.PriceInput{Money: &domain.Money{AmountMinor: 1250, Currency: "EUR"}},
+			currency: "USD",
+			want:     domain.Money{AmountMinor: 1250, Currency: "EUR"},
+		},
+		{
+			name:     "money wins over the deprecated amount",
+			input:    domain.PriceInput{Money: &domain.Money{AmountMinor: 1250, Currency: "EUR"}, Amount: 99.99},
+			currency: "USD",
+			want:     domain.Money{AmountMinor: 1250, Currency: "EUR"},
+		},
+		{
+			name:     "money currency is normalized",
+			input:    domain.PriceInput{Money: &domain.Money{AmountMinor: 1250, Currency: " eur "}},
+			currency: "USD",
+			want:     domain.Money{AmountMinor: 1250, Currency: "EUR"},
+		},
+		{
+			name:     "money without a currency",
+			input:    domain.PriceInput{Money: &domain.Money{AmountMinor: 1250}},
+			currency: "JPY",
+			want:     domain.Money{AmountMinor: 1250, Currency: "JPY"},
+		},
+		{
+			name:     "money with an unknown currency",
+			input:    domain.PriceInput{Money: &domain.Money{AmountMinor: 1250, Currency: "XXX"}},
+			currency: "USD",
+			wantErr:  domain.ErrUnknownCurrency,
+		},
+		{
+			name:     "money of zero",
+			input:    domain.PriceInput{Money: &domain.Money{Currency: "USD"}},
+			currency: "USD",
+			wantErr:  domain.ErrInvalidPrice,
+		},
+		{
+			name:     "negative money",
+			input:    domain.PriceInput{Money: &domain.Money{AmountMinor: -1, Currency: "USD"}},
+			currency: "USD",
+			wantErr:  domain.ErrInvalidPrice,
+		},
+		{
+			name:           "deprecated amount",
+			input:          domain.PriceInput{Amount: 19.99},
+			currency:       "USD",
+			want:           domain.Money{AmountMinor: 1999, Currency: "USD"},
+			wantDeprecated: true,
+		},
+		{
+			name:           "deprecated amount in a zero digit currency",
+			input:          domain.PriceInput{Amount: 1250},
+			currency:       "JPY",
+			want:           domain.Money{AmountMinor: 1250, Currency: "JPY"},
+			wantDeprecated: true,
+		},
+		{
+			name:           "deprecated amount below the minor unit",
+			input:          domain.PriceInput{Amount: 0.001},
+			currency:       "USD",
+			wantErr:        domain.ErrInvalidPrice,
+			wantDeprecated: true,
+		},
+		{
+			name:           "deprecated amount in an unknown currency",
+			input:          domain.PriceInput{Amount: 12.5},
+			currency:       "XXX",
+			wantErr:        domain.ErrUnknownCurrency,
+			wantDeprecated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := deprecatedPriceInputs.Value()
+
+			got, err := resolvePrice(tt.input, tt.currency)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("resolvePrice() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolvePrice() = %+v, want %+v", got, tt.want)
+			}
+			if counted := deprecatedPriceInputs.Value() > before; counted != tt.wantDeprecated {
+				t.Errorf("deprecated input counted = %v, want %v", counted, tt.wantDeprecated)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"product-service/internal/domain"
+	"time"
+)
+
+const (
+	maxHistoryPageSize = 100
+	// more due changes are applied on the next run
+	maxAppliedPerRun = 500
+)
+
+type priceHistoryUseCase struct {
+	historyRepo domain.PriceHistoryRepository
+	productRepo domain.ProductRepository
+}
+
+func NewPriceHistoryUseCase(historyRepo domain.PriceHistoryRepository, productRepo domain.ProductRepository) domain.PriceHistoryUseCase {
+	return &priceHistoryUseCase{historyRepo: historyRepo, productRepo: productRepo}
+}
+
+func (u *priceHistoryUseCase) GetHistory(tenantID, productID uint64, page, limit int32) ([]domain.PriceChange, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	limit = min(limit, maxHistoryPageSize)
+
+	// the history of a deleted product is gone with it
+	if _, err := u.productRepo.FindByID(tenantID, productID); err != nil {
+		return nil, 0, err
+	}
+
+	return u.historyRepo.List(tenantID, productID, page, limit)
+}
+
+func (u *priceHistoryUseCase) Schedule(tenantID, productID, createdBy uint64, price domain.Money, effectiveAt time.Time) (*domain.ScheduledPriceChange, error) {
+	if !effectiveAt.After(time.Now()) {
+		return nil, domain.ErrScheduleInPast
+	}
+
+	product, err := u.productRepo.FindByID(tenantID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if price, err = resolvePrice(domain.PriceInput{Money: &price}, product.Price.Currency); err != nil {
+		return nil, err
+	}
+	// changing the currency is left to Update, it checks the variant prices
+	if price.Currency != product.Price.Currency {
+		return nil, domain.ErrScheduleCurrency
+	}
+
+	change := &domain.ScheduledPriceChange{
+		TenantID:    tenantID,
+		ProductID:   productID,
+		Price:       price,
+		EffectiveAt: effectiveAt.UTC(),
+		Status:      domain.ScheduleStatusPending,
+		CreatedBy:   createdBy,
+	}
+	if err := u.historyRepo.Schedule(change); err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+func (u *priceHistoryUseCase) ListScheduled(tenantID, productID uint64) ([]domain.ScheduledPriceChange, error) {
+	if _, err := u.productRepo.FindByID(tenantID, productID); err != nil {
+		return nil, err
+	}
+
+	return u.historyRepo.ListScheduled(tenantID, productID)
+}
+
+func (u *priceHistoryUseCase) Cancel(tenantID, productID, id uint64) (*domain.ScheduledPriceChange, error) {
+	return u.historyRepo.CancelScheduled(tenantID, productID, id)
+}
+
+func (u *priceHistoryUseCase) ApplyDue() (int, error) {
+	return u.historyRepo.ApplyDue(time.Now(), maxAppliedPerRun)
+}
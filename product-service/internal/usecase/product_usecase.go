@@ -3,35 +3,52 @@ package usecase
 import (
 	"errors"
 	"product-service/internal/domain"
+	"strings"
 )
 
 type productUseCase struct {
-	productRepo domain.ProductRepository
+	productRepo  domain.ProductRepository
+	categoryRepo domain.CategoryRepository
+	currencyRepo domain.CurrencyRepository
+	// prices sent without a currency are in defaultCurrency
+	defaultCurrency string
 }
 
-func NewProductUseCase(productRepo domain.ProductRepository) domain.ProductUseCase {
-	return &productUseCase{productRepo: productRepo}
+func NewProductUseCase(productRepo domain.ProductRepository, categoryRepo domain.CategoryRepository, currencyRepo domain.CurrencyRepository, defaultCurrency string) domain.ProductUseCase {
+	return &productUseCase{
+		productRepo:     productRepo,
+		categoryRepo:    categoryRepo,
+		currencyRepo:    currencyRepo,
+		defaultCurrency: defaultCurrency,
+	}
 }
 
-func (u *productUseCase) Create(name, description string, price float64, stock int32) (*domain.Product, error) {
+func (u *productUseCase) Create(tenantID, createdBy, categoryID uint64, name, description string, priceInput domain.PriceInput, stock int32) (*domain.Product, error) {
 	if name == "" {
 		return nil, errors.New("name is required")
 	}
-	if price <= 0 {
-		return nil, errors.New("price must be greater than 0")
+	if !priceInput.IsSet() {
+		return nil, domain.ErrInvalidPrice
+	}
+	price, err := resolvePrice(priceInput, u.defaultCurrency)
+	if err != nil {
+		return nil, err
 	}
 	if stock < 0 {
 		return nil, errors.New("stock cannot be negative")
 	}
 
 	product := &domain.Product{
+		TenantID:    tenantID,
+		CreatedBy:   createdBy,
+		CategoryID:  optionalID(categoryID),
 		Name:        name,
 		Description: description,
 		Price:       price,
 		Stock:       stock,
 	}
 
-	err := u.productRepo.Create(product)
+	err = u.productRepo.Create(product)
 	if err != nil {
 		return nil, err
 	}
@@ -39,12 +56,25 @@ func (u *productUseCase) Create(name, description string, price float64, stock i
 	return product, nil
 }
 
-func (u *productUseCase) GetByID(id uint64) (*domain.Product, error) {
-	return u.productRepo.FindByID(id)
+func (u *productUseCase) GetByID(tenantID, id uint64, currency string) (*domain.Product, error) {
+	product, err := u.productRepo.FindByID(tenantID, id)
+	if err != nil || currency == "" {
+		return product, err
+	}
+
+	converter, err := u.converter(currency)
+	if err != nil {
+		return nil, err
+	}
+	if err := localizePrices(converter, product); err != nil {
+		return nil, err
+	}
+
+	return product, nil
 }
 
-func (u *productUseCase) Update(id uint64, name, description string, price float64, stock int32) (*domain.Product, error) {
-	product, err := u.productRepo.FindByID(id)
+func (u *productUseCase) Update(tenantID, id, updatedBy uint64, name, description string, priceInput domain.PriceInput, stock int32) (*domain.Product, error) {
+	product, err := u.productRepo.FindByID(tenantID, id)
 	if err != nil {
 		return nil, err
 	}
@@ -55,14 +85,22 @@ func (u *productUseCase) Update(id uint64, name, description string, price float
 	if description != "" {
 		product.Description = description
 	}
-	if price > 0 {
+	if priceInput.IsSet() {
+		price, err := resolvePrice(priceInput, product.Price.Currency)
+		if err != nil {
+			return nil, err
+		}
+		// variant prices are stored in the product currency
+		if price.Currency != product.Price.Currency && hasVariantPrices(product) {
+			return nil, domain.ErrCurrencyMismatch
+		}
 		product.Price = price
 	}
 	if stock >= 0 {
 		product.Stock = stock
 	}
 
-	err = u.productRepo.Update(product)
+	err = u.productRepo.Update(product, updatedBy)
 	if err != nil {
 		return nil, err
 	}
@@ -70,16 +108,11 @@ func (u *productUseCase) Update(id uint64, name, description string, price float
 	return product, nil
 }
 
-func (u *productUseCase) Delete(id uint64) error {
-	_, err := u.productRepo.FindByID(id)
-	if err != nil {
-		return err
-	}
-
-	return u.productRepo.Delete(id)
+func (u *productUseCase) Delete(tenantID, id uint64) error {
+	return u.productRepo.Delete(tenantID, id)
 }
 
-func (u *productUseCase) List(page, limit int32, search string) ([]domain.Product, int64, error) {
+func (u *productUseCase) List(tenantID uint64, page, limit int32, search string, categoryID uint64, currency string) ([]domain.Product, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -87,5 +120,131 @@ func (u *productUseCase) List(page, limit int32, search string) ([]domain.Produc
 		limit = 10
 	}
 
-	return u.productRepo.List(page, limit, search)
+	var categoryPath string
+	if categoryID != 0 {
+		category, err := u.categoryRepo.FindByID(tenantID, categoryID)
+		if err != nil {
+			return nil, 0, err
+		}
+		categoryPath = category.Path
+	}
+
+	var converter *domain.CurrencyConverter
+	if currency != "" {
+		var err error
+		if converter, err = u.converter(currency); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	products, total, err := u.productRepo.List(tenantID, page, limit, search, categoryPath)
+	if err != nil || converter == nil {
+		return products, total, err
+	}
+
+	// a missing exchange rate only leaves that product without a price
+	for i := range products {
+		if err := localizePrices(converter, &products[i]); err != nil && !errors.Is(err, domain.ErrNoExchangeRate) {
+			return nil, 0, err
+		}
+	}
+
+	return products, total, nil
+}
+
+func (u *productUseCase) SetCategory(tenantID, id, categoryID uint64) (*domain.Product, error) {
+	if err := u.productRepo.SetCategory(tenantID, id, optionalID(categoryID)); err != nil {
+		return nil, err
+	}
+
+	return u.productRepo.FindByID(tenantID, id)
+}
+
+func (u *productUseCase) SetPrice(tenantID, id uint64, price domain.Money) (*domain.Product, error) {
+	product, err := u.productRepo.FindByID(tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// without a default currency a missing currency_code is rejected
+	if price, err = resolvePrice(domain.PriceInput{Money: &price}, ""); err != nil {
+		return nil, err
+	}
+	if price.Currency == product.Price.Currency {
+		return nil, domain.ErrProductCurrency
+	}
+
+	err = u.productRepo.SetPrice(&domain.ProductPrice{
+		ProductID:   id,
+		TenantID:    tenantID,
+		Currency:    price.Currency,
+		AmountMinor: price.AmountMinor,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return u.productRepo.FindByID(tenantID, id)
+}
+
+func (u *productUseCase) DeletePrice(tenantID, id uint64, currency string) (*domain.Product, error) {
+	if err := u.productRepo.DeletePrice(tenantID, id, strings.ToUpper(currency)); err != nil {
+		return nil, err
+	}
+
+	return u.productRepo.FindByID(tenantID, id)
+}
+
+func (u *productUseCase) ExportByCreator(userID uint64) ([]domain.Product, error) {
+	return u.productRepo.ListByCreator(userID)
+}
+
+func (u *productUseCase) ForgetUser(userID uint64) error {
+	return u.productRepo.ClearCreator(userID)
+}
+
+func (u *productUseCase) converter(currency string) (*domain.CurrencyConverter, error) {
+	currency, err := normalizeCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return loadConverter(u.currencyRepo, currency)
+}
+
+// localizePrices sets RequestedPrice on the product and its variants
+func localizePrices(converter *domain.CurrencyConverter, product *domain.Product) error {
+	price, converted, err := converter.ProductPrice(product)
+	if err != nil {
+		return err
+	}
+	product.RequestedPrice, product.PriceConverted = &price, converted
+
+	for i := range product.Variants {
+		variant := &product.Variants[i]
+		price, converted, err := converter.VariantPrice(product, variant)
+		if err != nil {
+			return err
+		}
+		variant.RequestedPrice, variant.PriceConverted = &price, converted
+	}
+
+	return nil
+}
+
+func hasVariantPrices(product *domain.Product) bool {
+	for _, variant := range product.Variants {
+		if variant.PriceAmountMinor != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// optionalID maps the 0 used by the APIs to a NULL column
+func optionalID(id uint64) *uint64 {
+	if id == 0 {
+		return nil
+	}
+	return &id
 }
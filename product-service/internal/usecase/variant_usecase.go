@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	"encoding/json"
+	"product-service/internal/domain"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxVariantAttributes = 10
+	maxAttributeLength   = 50
+	maxSKULength         = 64
+
+	defaultReservationTTL = 15 * time.Minute
+	maxReservationTTL     = 24 * time.Hour
+	// more expired reservations are handled on the next run
+	maxExpiredPerRun = 500
+)
+
+type variantUseCase struct {
+	variantRepo domain.VariantRepository
+	productRepo domain.ProductRepository
+}
+
+func NewVariantUseCase(variantRepo domain.VariantRepository, productRepo domain.ProductRepository) domain.VariantUseCase {
+	return &variantUseCase{variantRepo: variantRepo, productRepo: productRepo}
+}
+
+func (u *variantUseCase) Create(tenantID, productID uint64, sku string, attributes map[string]string, price domain.PriceInput, stock int32) (*domain.ProductVariant, error) {
+	variant := &domain.ProductVariant{
+		TenantID:  tenantID,
+		ProductID: productID,
+		Stock:     stock,
+	}
+	if err := applyVariantFields(variant, &sku, attributes); err != nil {
+		return nil, err
+	}
+	if price.IsSet() {
+		if err := u.applyPrice(variant, price); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateVariantStock(variant); err != nil {
+		return nil, err
+	}
+
+	if err := u.variantRepo.Create(variant); err != nil {
+		return nil, err
+	}
+
+	return variant, nil
+}
+
+func (u *variantUseCase) Update(tenantID, productID, id uint64, update domain.VariantUpdate) (*domain.ProductVariant, error) {
+	variant, err := u.variantRepo.FindByID(tenantID, productID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyVariantFields(variant, update.SKU, update.Attributes); err != nil {
+		return nil, err
+	}
+	if update.ClearPrice {
+		variant.PriceAmountMinor = nil
+	} else if update.Price.IsSet() {
+		if err := u.applyPrice(variant, update.Price); err != nil {
+			return nil, err
+		}
+	}
+	if update.Stock != nil {
+		variant.Stock = *update.Stock
+	}
+	if err := validateVariantStock(variant); err != nil {
+		return nil, err
+	}
+
+	if err := u.variantRepo.Update(variant); err != nil {
+		return nil, err
+	}
+
+	return variant, nil
+}
+
+func (u *variantUseCase) Delete(tenantID, productID, id uint64) error {
+	return u.variantRepo.Delete(tenantID, productID, id)
+}
+
+func (u *variantUseCase) ReserveStock(tenantID, productID, variantID uint64, quantity int32, ttl time.Duration) (*domain.StockReservation, int32, error) {
+	if quantity <= 0 {
+		return nil, 0, domain.ErrInvalidQuantity
+	}
+	if ttl == 0 {
+		ttl = defaultReservationTTL
+	}
+	if ttl < time.Second || ttl > maxReservationTTL {
+		return nil, 0, &domain.ValidationError{Field: "ttl_seconds", Message: "must be between 1 second and 24 hours"}
+	}
+
+	reservation := &domain.StockReservation{
+		TenantID:  tenantID,
+		ProductID: productID,
+		Quantity:  quantity,
+		Status:    domain.ReservationStatusActive,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if variantID != 0 {
+		reservation.VariantID = &variantID
+	}
+
+	remaining, err := u.variantRepo.ReserveStock(reservation)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return reservation, remaining, nil
+}
+
+// ReleaseStock gives back quantity of a reservation, 0 gives back all of it
+func (u *variantUseCase) ReleaseStock(tenantID, productID, reservationID uint64, quantity int32) (*domain.StockReservation, int32, error) {
+	if quantity < 0 {
+		return nil, 0, domain.ErrInvalidQuantity
+	}
+
+	return u.variantRepo.ReleaseStock(tenantID, productID, reservationID, quantity)
+}
+
+func (u *variantUseCase) ExpireReservations() (int, error) {
+	return u.variantRepo.ExpireReservations(time.Now(), maxExpiredPerRun)
+}
+
+// applyPrice sets a price override, it has to be in the product currency
+func (u *variantUseCase) applyPrice(variant *domain.ProductVariant, input domain.PriceInput) error {
+	product, err := u.productRepo.FindByID(variant.TenantID, variant.ProductID)
+	if err != nil {
+		return err
+	}
+
+	price, err := resolvePrice(input, product.Price.Currency)
+	if err != nil {
+		return err
+	}
+	if price.Currency != product.Price.Currency {
+		return domain.ErrCurrencyMismatch
+	}
+	variant.PriceAmountMinor = &price.AmountMinor
+
+	return nil
+}
+
+// applyVariantFields sets the sku and the attributes when they are given,
+// empty attributes keep the current ones
+func applyVariantFields(variant *domain.ProductVariant, sku *string, attributes map[string]string) error {
+	if sku != nil {
+		trimmed := strings.TrimSpace(*sku)
+		if trimmed == "" || utf8.RuneCountInString(trimmed) > maxSKULength {
+			return &domain.ValidationError{Field: "sku", Message: "must be between 1 and 64 characters"}
+		}
+		variant.SKU = trimmed
+	}
+
+	if len(attributes) == 0 {
+		if variant.Attributes == nil {
+			return &domain.ValidationError{Field: "attributes", Message: "at least one attribute is required"}
+		}
+		return nil
+	}
+	if len(attributes) > maxVariantAttributes {
+		return &domain.ValidationError{Field: "attributes", Message: "at most 10 attributes are allowed"}
+	}
+
+	cleaned := make(map[string]string, len(attributes))
+	for name, value := range attributes {
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if name == "" || value == "" || utf8.RuneCountInString(name) > maxAttributeLength || utf8.RuneCountInString(value) > maxAttributeLength {
+			return &domain.ValidationError{Field: "attributes", Message: "names and values must be between 1 and 50 characters"}
+		}
+		cleaned[name] = value
+	}
+
+	// encoding/json sorts map keys, equal attribute sets get the same key
+	key, err := json.Marshal(cleaned)
+	if err != nil {
+		return err
+	}
+	variant.Attributes = cleaned
+	variant.AttributeKey = string(key)
+
+	return nil
+}
+
+func validateVariantStock(variant *domain.ProductVariant) error {
+	if variant.Stock < 0 {
+		return &domain.ValidationError{Field: "stock", Message: "cannot be negative"}
+	}
+
+	return nil
+}